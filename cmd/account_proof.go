@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/spf13/cobra"
+)
+
+// NewAccountProofCommand creates and returns the account-proof command
+func NewAccountProofCommand(_ *RootContext) *cobra.Command {
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "account-proof <input.json>",
+		Short: "Print the Merkle proof of an account from the witness of a prover input",
+		Long:  "Print the Merkle proof path of an account (nodes from the pre-state root to the account leaf) and the decoded account, as covered by the witness state of a JSON prover input",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if !gethcommon.IsHexAddress(addr) {
+				return fmt.Errorf("invalid address %q", addr)
+			}
+			pi, err := readProverInputFile(args[0])
+			if err != nil {
+				return err
+			}
+			return input.PrintAccountProof(os.Stdout, pi, gethcommon.HexToAddress(addr))
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "", "Address of the account")
+	_ = cmd.MarkFlagRequired("addr")
+
+	return cmd
+}
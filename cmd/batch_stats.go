@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/spf13/cobra"
+)
+
+// NewBatchStatsCommand creates and returns the batch-stats command
+func NewBatchStatsCommand(_ *RootContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch-stats <input.json>...",
+		Short: "Report the witness data deduplicated by batching prover inputs",
+		Long:  "Report the witness state nodes, codes and ancestors shared by JSON prover inputs, which are deduplicated when merging the inputs into a batch",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			inputs := make([]*input.ProverInput, 0, len(args))
+			for _, path := range args {
+				pi, err := readProverInputFile(path)
+				if err != nil {
+					return err
+				}
+				if pi.Witness != nil {
+					if pi.Witness.Ancestors, err = pi.Witness.LoadAncestors(); err != nil {
+						return fmt.Errorf("failed to load ancestors of %v: %w", path, err)
+					}
+				}
+				inputs = append(inputs, pi)
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(input.ComputeDedupStats(inputs...))
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyChecksumCommand creates and returns the verify-checksum command
+func NewVerifyChecksumCommand(_ *RootContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-checksum <input.json>",
+		Short: "Verify the integrity checksum of a stored prover input",
+		Long:  "Verify the integrity checksum of a stored JSON prover input. It recomputes the checksum of the input and compares it with the embedded checksum to detect corruption",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			pi, err := readProverInputFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			if err := input.VerifyChecksum(pi); err != nil {
+				return err
+			}
+
+			fmt.Printf("Checksum OK: %s\n", pi.Checksum.Hex())
+			return nil
+		},
+	}
+	return cmd
+}
+
+// readProverInputFile reads a JSON prover input file
+func readProverInputFile(path string) (*input.ProverInput, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prover input: %w", err)
+	}
+
+	var pi input.ProverInput
+	if err := json.Unmarshal(b, &pi); err != nil {
+		return nil, fmt.Errorf("failed to decode prover input (it may be truncated): %w", err)
+	}
+	pi.Witness.ResolveAncestorsFile(path)
+
+	return &pi, nil
+}
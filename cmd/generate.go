@@ -113,6 +113,30 @@ func NewExecuteCommand(rootCtx *RootContext) *cobra.Command {
 	return cmd
 }
 
+func NewExecRPCCommand(rootCtx *RootContext) *cobra.Command {
+	var (
+		ctx         = &ProverInputContext{RootContext: *rootCtx}
+		blockNumber string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "exec-rpc",
+		Short:   "Generate prover inputs in memory and immediately execute block on them, without storing anything.",
+		Long:    "Generate prover inputs in memory and immediately execute block on them, without storing anything. It runs online and requires --chain-rpc-url to be set to a remote JSON-RPC Ethereum Execution Layer node",
+		PreRunE: preRun(ctx, &blockNumber),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return ctx.svc.ExecuteFromRPC(cmd.Context(), ctx.blockNumber)
+		},
+		PostRunE: func(cmd *cobra.Command, _ []string) error {
+			return ctx.svc.Stop(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVarP(&blockNumber, "block-number", "b", "latest", "Block number")
+
+	return cmd
+}
+
 func NewConfigCommand(rootCtx *RootContext) *cobra.Command {
 	var (
 		ctx = &ProverInputContext{RootContext: *rootCtx}
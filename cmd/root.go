@@ -70,6 +70,14 @@ func NewZkPigCommand() *cobra.Command {
 	rootCmd.AddCommand(NewPreflightCommand(ctx))
 	rootCmd.AddCommand(NewPrepareCommand(ctx))
 	rootCmd.AddCommand(NewExecuteCommand(ctx))
+	rootCmd.AddCommand(NewExecRPCCommand(ctx))
+	rootCmd.AddCommand(NewVerifyChecksumCommand(ctx))
+	rootCmd.AddCommand(NewBatchStatsCommand(ctx))
+	rootCmd.AddCommand(NewValidateCommand(ctx))
+	rootCmd.AddCommand(NewTreeCommand(ctx))
+	rootCmd.AddCommand(NewAccountProofCommand(ctx))
+	rootCmd.AddCommand(NewSnapshotCommand(ctx))
+	rootCmd.AddCommand(NewServeCommand(ctx))
 	rootCmd.AddCommand(NewConfigCommand(ctx))
 
 	return rootCmd
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/zk-pig/src/generator"
+	"github.com/kkrt-labs/zk-pig/src/server"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewServeCommand creates and returns the serve command
+func NewServeCommand(_ *RootContext) *cobra.Command {
+	cfg := new(server.Config)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP server executing prover inputs",
+		Long:  "Run an HTTP server exposing POST /execute, which executes the JSON prover input in the request body and returns the execution result, and GET /healthz. It runs until interrupted",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			srv := server.New(generator.NewExecutor(), cfg)
+			if err := srv.Start(ctx); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+			case err := <-srv.Errors():
+				if err != nil {
+					log.LoggerFromContext(ctx).Error("Execution server failed", zap.Error(err))
+					return err
+				}
+			}
+
+			stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.Timeout)
+			defer cancel()
+			return srv.Stop(stopCtx)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.Addr, "addr", ":8080", "Address the server listens on")
+	cmd.Flags().Int64Var(&cfg.MaxRequestSize, "max-request-size", 128<<20, "Maximum size in bytes of a request body")
+	cmd.Flags().DurationVar(&cfg.Timeout, "timeout", time.Minute, "Maximum duration of a request")
+	cmd.Flags().IntVar(&cfg.MaxConcurrency, "max-concurrency", 0, "Maximum number of concurrent executions (defaults to the number of CPUs)")
+
+	return cmd
+}
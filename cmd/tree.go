@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/spf13/cobra"
+)
+
+// NewTreeCommand creates and returns the tree command
+func NewTreeCommand(_ *RootContext) *cobra.Command {
+	var maxDepth int
+
+	cmd := &cobra.Command{
+		Use:   "tree <input.json>",
+		Short: "Print the witness state of a prover input as a trie",
+		Long:  "Print the witness state of a JSON prover input as a human-readable trie (branches, extensions and leaves with decoded accounts). Nodes absent from the witness are printed as <missing>",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			pi, err := readProverInputFile(args[0])
+			if err != nil {
+				return err
+			}
+			return input.PrintWitnessState(os.Stdout, pi, maxDepth)
+		},
+	}
+
+	cmd.Flags().IntVar(&maxDepth, "max-depth", 8, "Maximum depth of printed nodes (0 for no limit)")
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/kkrt-labs/zk-pig/src/generator"
+	"github.com/spf13/cobra"
+)
+
+// NewValidateCommand creates and returns the validate command
+func NewValidateCommand(_ *RootContext) *cobra.Command {
+	var csvPath, sqlitePath string
+
+	cmd := &cobra.Command{
+		Use:   "validate <input.json>...",
+		Short: "Validate a batch of stored prover inputs by executing their blocks",
+		Long:  "Validate a batch of stored JSON prover inputs by executing their blocks off-line. With --csv, it writes a summary row per input (block number, status, gas used, transaction count, duration and witness size). With --sqlite, it appends the results to the block_results table of an SQLite database",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Inputs are run as the entries of a manifest without expected metadata
+			var manifest bytes.Buffer
+			enc := json.NewEncoder(&manifest)
+			for _, path := range args {
+				if err := enc.Encode(&generator.ManifestEntry{Path: path}); err != nil {
+					return fmt.Errorf("failed to encode manifest entry: %w", err)
+				}
+			}
+
+			results, err := generator.RunManifest(cmd.Context(), generator.NewExecutor(), &manifest)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, res := range results {
+				if res.Err != nil {
+					failed++
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: FAILED (%v)\n", res.Entry.Path, res.Err)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: OK\n", res.Entry.Path)
+				}
+			}
+
+			if csvPath != "" {
+				f, err := os.Create(csvPath)
+				if err != nil {
+					return fmt.Errorf("failed to create summary file: %w", err)
+				}
+				defer f.Close()
+				if err := generator.WriteCSV(f, results); err != nil {
+					return err
+				}
+			}
+
+			if sqlitePath != "" {
+				sink, err := generator.NewSQLiteSink(sqlitePath)
+				if err != nil {
+					return err
+				}
+				defer sink.Close()
+				if err := sink.Write(results...); err != nil {
+					return err
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d prover input(s) failed validation", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&csvPath, "csv", "", "Path of the CSV summary file to write")
+	cmd.Flags().StringVar(&sqlitePath, "sqlite", "", "Path of the SQLite database to append results to")
+
+	return cmd
+}
@@ -10,7 +10,8 @@ import (
 )
 
 // NewChain creates a new core.HeaderChain instance
-func NewChain(cfg *params.ChainConfig, stateDB gethstate.Database) (*core.HeaderChain, error) {
+// The consensus engine derived from the chain configuration is decorated with the given decorators
+func NewChain(cfg *params.ChainConfig, stateDB gethstate.Database, decorators ...EngineDecorator) (*core.HeaderChain, error) {
 	// Setup the genesis block, to avoid error on core.NewHeaderChain
 	_, err := core.DefaultGenesisBlock().Commit(stateDB.TrieDB().Disk(), stateDB.TrieDB())
 	if err != nil {
@@ -22,6 +23,9 @@ func NewChain(cfg *params.ChainConfig, stateDB gethstate.Database) (*core.Header
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consensus engine: %v", err)
 	}
+	for _, decorate := range decorators {
+		engine = decorate(engine)
+	}
 
 	hc, err := core.NewHeaderChain(stateDB.TrieDB().Disk(), cfg, engine, nil)
 	if err != nil {
@@ -0,0 +1,206 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ChainConfigWithRules returns a copy of the chain configuration that yields the given rules whatever the block number and timestamp.
+// Every fork activation is set at genesis if the corresponding rule is enabled and unset otherwise. IsMerge sets the terminal total
+// difficulty and merge netsplit block at genesis, or a never reached terminal total difficulty so the chain stays pre-merge.
+//
+// Rules that no chain configuration yields are rejected, rather than widened to the closest configuration (e.g. IsEIP2929 without IsBerlin,
+// or IsShanghai without IsMerge, as go-ethereum derives EIP rules from fork activations and only enables post-merge forks on merged chains).
+//
+// Note: go-ethereum only enables merge related rules (Shanghai, Cancun, Prague, Verkle) on post-merge blocks,
+// so those rules are only effective on blocks with a zero difficulty.
+func ChainConfigWithRules(cfg *params.ChainConfig, rules *params.Rules) (*params.ChainConfig, error) {
+	c := *cfg
+	if rules.ChainID != nil {
+		c.ChainID = new(big.Int).Set(rules.ChainID)
+	}
+
+	c.DAOForkBlock = nil
+	c.DAOForkSupport = false
+	c.MuirGlacierBlock = nil
+	c.ArrowGlacierBlock = nil
+	c.GrayGlacierBlock = nil
+	for _, fork := range ruleForks(&c, rules) {
+		if fork.block != nil {
+			*fork.block = activationBlock(fork.enabled)
+		} else {
+			*fork.time = activationTime(fork.enabled)
+		}
+	}
+	if rules.IsMerge {
+		c.TerminalTotalDifficulty = big.NewInt(0)
+		c.MergeNetsplitBlock = big.NewInt(0)
+	} else {
+		c.TerminalTotalDifficulty = new(big.Int).Lsh(big.NewInt(1), 128) // Never reached
+		c.MergeNetsplitBlock = nil
+	}
+
+	expected := *rules
+	expected.ChainID = c.ChainID
+	if mismatches := rulesMismatches(&expected, c.Rules(big.NewInt(0), rules.IsMerge, 0), false); len(mismatches) > 0 {
+		return nil, fmt.Errorf("rules can not be expressed by a chain configuration, the closest one yields %v", strings.Join(mismatches, ", "))
+	}
+
+	return &c, nil
+}
+
+// forkActivation is the activation field of the fork enabling a rule, block based forks have a block field and timestamp based ones a time field
+type forkActivation struct {
+	block   **big.Int
+	time    **uint64
+	enabled bool
+}
+
+// ruleForks returns the activation fields of the forks of the chain configuration enabling the given rules
+// Rules derived by go-ethereum from other forks (IsEIP2929 and IsEIP4762) and IsMerge (which is not a fork activation) are not mapped,
+// callers check them by comparing the rules yielded by the resulting configuration (see rulesMismatches).
+func ruleForks(c *params.ChainConfig, rules *params.Rules) []forkActivation {
+	return []forkActivation{
+		{block: &c.HomesteadBlock, enabled: rules.IsHomestead},
+		{block: &c.EIP150Block, enabled: rules.IsEIP150},
+		{block: &c.EIP155Block, enabled: rules.IsEIP155},
+		{block: &c.EIP158Block, enabled: rules.IsEIP158},
+		{block: &c.ByzantiumBlock, enabled: rules.IsByzantium},
+		{block: &c.ConstantinopleBlock, enabled: rules.IsConstantinople},
+		{block: &c.PetersburgBlock, enabled: rules.IsPetersburg},
+		{block: &c.IstanbulBlock, enabled: rules.IsIstanbul},
+		{block: &c.BerlinBlock, enabled: rules.IsBerlin},
+		{block: &c.LondonBlock, enabled: rules.IsLondon},
+		{time: &c.ShanghaiTime, enabled: rules.IsShanghai},
+		{time: &c.CancunTime, enabled: rules.IsCancun},
+		{time: &c.PragueTime, enabled: rules.IsPrague},
+		{time: &c.VerkleTime, enabled: rules.IsVerkle},
+	}
+}
+
+// rulesMismatches returns the flags of got differing from want, formatted as name=value
+// If enabledOnly is set, only the flags enabled in want are compared.
+func rulesMismatches(want *params.Rules, got params.Rules, enabledOnly bool) []string {
+	var mismatches []string
+	wantV, gotV := reflect.ValueOf(want).Elem(), reflect.ValueOf(got)
+	for i := 0; i < wantV.NumField(); i++ {
+		if wantV.Field(i).Kind() != reflect.Bool || wantV.Field(i).Bool() == gotV.Field(i).Bool() || (enabledOnly && !wantV.Field(i).Bool()) {
+			continue
+		}
+		mismatches = append(mismatches, fmt.Sprintf("%v=%v", wantV.Type().Field(i).Name, gotV.Field(i).Bool()))
+	}
+	return mismatches
+}
+
+func activationBlock(enabled bool) *big.Int {
+	if enabled {
+		return big.NewInt(0)
+	}
+	return nil
+}
+
+func activationTime(enabled bool) *uint64 {
+	if enabled {
+		t := uint64(0)
+		return &t
+	}
+	return nil
+}
+
+// ShadowFork describes rules activated on top of a base chain configuration from a given height
+type ShadowFork struct {
+	Block *big.Int      // Block number from which block based forks rules (up to London) and the merge are activated
+	Time  *uint64       // Block timestamp from which timestamp based forks rules (Shanghai and later) are activated
+	Rules *params.Rules // Rules to activate, rules that are disabled keep their base configuration activation
+}
+
+// ShadowForkConfig returns a copy of the base chain configuration with the shadow fork rules activated at the shadow fork height
+// Forks already activated by the base configuration before the shadow fork height are left unchanged. IsMerge sets the merge netsplit
+// block at the shadow fork block, and a zero terminal total difficulty if the base configuration never merges.
+//
+// Rules are mapped to forks as in ChainConfigWithRules: it errors if an enabled rule is not yielded at the shadow fork height by the
+// resulting configuration (e.g. IsEIP2929 without IsBerlin, or IsShanghai on a chain that does not merge), rather than widening it.
+func ShadowForkConfig(base *params.ChainConfig, fork *ShadowFork) (*params.ChainConfig, error) {
+	c := *base
+	r := fork.Rules
+
+	for _, f := range ruleForks(&c, r) {
+		if f.block != nil {
+			*f.block = shadowActivationBlock(*f.block, f.enabled, fork.Block)
+		} else {
+			*f.time = shadowActivationTime(*f.time, f.enabled, fork.Time)
+		}
+	}
+	if r.IsMerge {
+		if c.TerminalTotalDifficulty == nil {
+			c.TerminalTotalDifficulty = big.NewInt(0)
+		}
+		c.MergeNetsplitBlock = shadowActivationBlock(c.MergeNetsplitBlock, true, fork.Block)
+	}
+
+	// Enabled rules must hold from the shadow fork height (post-merge if the chain merges)
+	number, time := new(big.Int), uint64(0)
+	if fork.Block != nil {
+		number.Set(fork.Block)
+	}
+	if fork.Time != nil {
+		time = *fork.Time
+	}
+	got := c.Rules(number, c.TerminalTotalDifficulty != nil, time)
+	if mismatches := rulesMismatches(r, got, true); len(mismatches) > 0 {
+		return nil, fmt.Errorf("shadow fork rules can not be expressed by a chain configuration, the closest one yields %v", strings.Join(mismatches, ", "))
+	}
+
+	return &c, nil
+}
+
+func shadowActivationBlock(base *big.Int, enabled bool, at *big.Int) *big.Int {
+	if !enabled || at == nil || (base != nil && base.Cmp(at) <= 0) {
+		return base
+	}
+	return new(big.Int).Set(at)
+}
+
+func shadowActivationTime(base *uint64, enabled bool, at *uint64) *uint64 {
+	if !enabled || at == nil || (base != nil && *base <= *at) {
+		return base
+	}
+	t := *at
+	return &t
+}
+
+// MergeChainConfig returns a copy of the base chain configuration merged with the override configuration.
+// Fields set in the override configuration (i.e. non-zero) take precedence over the base configuration, other fields are kept from the base.
+// As a consequence, an override can not unset a base field (e.g. deactivate a fork).
+// It also returns the JSON names of the fields whose value was overridden, in declaration order.
+func MergeChainConfig(base, override *params.ChainConfig) (cfg *params.ChainConfig, overridden []string) {
+	merged := *base
+	if override == nil {
+		return &merged, nil
+	}
+
+	mergedV := reflect.ValueOf(&merged).Elem()
+	overrideV := reflect.ValueOf(override).Elem()
+	for i := 0; i < overrideV.NumField(); i++ {
+		field := overrideV.Field(i)
+		if field.IsZero() || reflect.DeepEqual(field.Interface(), mergedV.Field(i).Interface()) {
+			continue
+		}
+		mergedV.Field(i).Set(field)
+		overridden = append(overridden, jsonFieldName(overrideV.Type().Field(i)))
+	}
+
+	return &merged, overridden
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,176 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainConfigWithRules(t *testing.T) {
+	rules := params.Rules{
+		ChainID:     big.NewInt(1),
+		IsHomestead: true,
+		IsEIP150:    true,
+		IsEIP155:    true,
+		IsEIP158:    true,
+		IsByzantium: true,
+		IsIstanbul:  true,
+		IsLondon:    true,
+	}
+
+	cfg, err := ChainConfigWithRules(params.MainnetChainConfig, &rules)
+	require.NoError(t, err)
+	assert.Equal(t, rules, cfg.Rules(big.NewInt(21465322), false, 1734523800))
+	assert.Equal(t, rules, cfg.Rules(big.NewInt(1), false, 0))
+
+	// Base configuration is not modified
+	assert.True(t, params.MainnetChainConfig.IsBerlin(big.NewInt(21465322)))
+}
+
+func TestChainConfigWithRulesMerge(t *testing.T) {
+	rules := params.MainnetChainConfig.Rules(big.NewInt(21465322), true, 1734523800) // Cancun
+	cfg, err := ChainConfigWithRules(params.MainnetChainConfig, &rules)
+	require.NoError(t, err)
+	assert.Equal(t, rules, cfg.Rules(big.NewInt(1), true, 0))
+	assert.Equal(t, big.NewInt(0), cfg.TerminalTotalDifficulty)
+	assert.Equal(t, big.NewInt(0), cfg.MergeNetsplitBlock)
+
+	// Pre-merge rules yield a chain never reaching the terminal total difficulty
+	rules = params.MainnetChainConfig.Rules(big.NewInt(12965000), false, 0) // London
+	cfg, err = ChainConfigWithRules(params.MainnetChainConfig, &rules)
+	require.NoError(t, err)
+	assert.Equal(t, rules, cfg.Rules(big.NewInt(1), false, 0))
+	assert.Nil(t, cfg.MergeNetsplitBlock)
+	assert.True(t, cfg.TerminalTotalDifficulty.Cmp(params.MainnetChainConfig.TerminalTotalDifficulty) > 0)
+}
+
+func TestChainConfigWithRulesInexpressible(t *testing.T) {
+	for _, tt := range []struct {
+		desc  string
+		rules params.Rules
+		err   string
+	}{
+		{
+			desc:  "EIP-2929 without Berlin",
+			rules: params.Rules{IsHomestead: true, IsEIP2929: true},
+			err:   "IsEIP2929=false",
+		},
+		{
+			desc:  "Shanghai without the merge",
+			rules: params.Rules{IsHomestead: true, IsBerlin: true, IsEIP2929: true, IsLondon: true, IsShanghai: true},
+			err:   "IsShanghai=false",
+		},
+		{
+			desc:  "merge without London",
+			rules: params.Rules{IsHomestead: true, IsMerge: true},
+			err:   "IsMerge=false",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ChainConfigWithRules(params.MainnetChainConfig, &tt.rules)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "rules can not be expressed by a chain configuration")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestShadowForkConfig(t *testing.T) {
+	shadowTime := uint64(1800000000)
+	cfg, err := ShadowForkConfig(params.MainnetChainConfig, &ShadowFork{
+		Block: big.NewInt(22000000),
+		Time:  &shadowTime,
+		Rules: &params.Rules{
+			IsBerlin: true, // already active on base configuration
+			IsPrague: true,
+		},
+	})
+	require.NoError(t, err)
+
+	// Forks activated by the base configuration are unchanged
+	assert.Equal(t, params.MainnetChainConfig.BerlinBlock, cfg.BerlinBlock)
+	assert.Equal(t, params.MainnetChainConfig.CancunTime, cfg.CancunTime)
+
+	// Shadow forks are activated at the shadow fork height
+	assert.False(t, cfg.IsPrague(big.NewInt(22000000), shadowTime-1))
+	assert.True(t, cfg.IsPrague(big.NewInt(22000000), shadowTime))
+
+	// Base configuration is not modified
+	assert.Nil(t, params.MainnetChainConfig.PragueTime)
+}
+
+func TestShadowForkConfigMerge(t *testing.T) {
+	// Base configuration never merges, the shadow fork merges at its block
+	base := *params.AllEthashProtocolChanges
+	base.TerminalTotalDifficulty = nil
+	cfg, err := ShadowForkConfig(&base, &ShadowFork{
+		Block: big.NewInt(100),
+		Rules: &params.Rules{IsMerge: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), cfg.TerminalTotalDifficulty)
+	assert.Equal(t, big.NewInt(100), cfg.MergeNetsplitBlock)
+	assert.Nil(t, base.TerminalTotalDifficulty)
+}
+
+func TestShadowForkConfigInexpressible(t *testing.T) {
+	shadowTime := uint64(100)
+	noMerge := *params.AllEthashProtocolChanges
+	noMerge.TerminalTotalDifficulty = nil
+	for _, tt := range []struct {
+		desc string
+		base *params.ChainConfig
+		fork ShadowFork
+		err  string
+	}{
+		{
+			desc: "EIP-2929 without Berlin",
+			base: params.MainnetChainConfig,
+			fork: ShadowFork{Block: big.NewInt(1), Rules: &params.Rules{IsEIP2929: true}},
+			err:  "IsEIP2929=false",
+		},
+		{
+			desc: "Shanghai on a chain that never merges",
+			base: &noMerge,
+			fork: ShadowFork{Block: big.NewInt(1), Time: &shadowTime, Rules: &params.Rules{IsShanghai: true}},
+			err:  "IsShanghai=false",
+		},
+		{
+			desc: "block based fork without shadow fork block",
+			base: params.MainnetChainConfig,
+			fork: ShadowFork{Time: &shadowTime, Rules: &params.Rules{IsBerlin: true}},
+			err:  "IsBerlin=false",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := ShadowForkConfig(tt.base, &tt.fork)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "shadow fork rules can not be expressed by a chain configuration")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestMergeChainConfig(t *testing.T) {
+	cancunTime := uint64(1800000000)
+	override := &params.ChainConfig{
+		ChainID:    params.MainnetChainConfig.ChainID, // same as base, not reported
+		CancunTime: &cancunTime,
+	}
+
+	cfg, overridden := MergeChainConfig(params.MainnetChainConfig, override)
+	assert.Equal(t, []string{"cancunTime"}, overridden)
+	assert.Equal(t, cancunTime, *cfg.CancunTime)
+	assert.Equal(t, params.MainnetChainConfig.ShanghaiTime, cfg.ShanghaiTime)
+	assert.Equal(t, params.MainnetChainConfig.LondonBlock, cfg.LondonBlock)
+
+	// Base configuration is not modified
+	assert.NotEqual(t, cancunTime, *params.MainnetChainConfig.CancunTime)
+
+	cfg, overridden = MergeChainConfig(params.MainnetChainConfig, nil)
+	assert.Empty(t, overridden)
+	assert.Equal(t, params.MainnetChainConfig, cfg)
+}
@@ -1,6 +1,8 @@
 package ethereum
 
 import (
+	"fmt"
+
 	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
@@ -47,3 +49,34 @@ func WriteNodesToHashDB(db ethdb.Database, nodes ...[]byte) {
 		rawdb.WriteLegacyTrieNode(db, gethcommon.BytesToHash(hash), node)
 	}
 }
+
+// WriteNodesToHashDBBatched fills an ethdb.Database with the provided nodes, committing writes in chunks of batchSize nodes
+// It is much faster than WriteNodesToHashDB on persistent databases for large witnesses. If batchSize is zero, nodes are written in a single batch.
+func WriteNodesToHashDBBatched(db ethdb.Database, batchSize int, nodes ...[]byte) error {
+	var (
+		hasher = crypto.NewKeccakState()
+		hash   = make([]byte, 32)
+		batch  = db.NewBatch()
+	)
+	//nolint:errcheck // Can't fail
+	for i, node := range nodes {
+		hasher.Reset()
+		hasher.Write(node)
+		hasher.Read(hash)
+
+		rawdb.WriteLegacyTrieNode(batch, gethcommon.BytesToHash(hash), node)
+
+		if batchSize > 0 && (i+1)%batchSize == 0 {
+			if err := batch.Write(); err != nil {
+				return fmt.Errorf("failed to write nodes batch: %v", err)
+			}
+			batch.Reset()
+		}
+	}
+	if batch.ValueSize() > 0 {
+		if err := batch.Write(); err != nil {
+			return fmt.Errorf("failed to write nodes batch: %v", err)
+		}
+	}
+	return nil
+}
@@ -1,11 +1,17 @@
 package ethereum
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFillDBWithBytecode(t *testing.T) {
@@ -23,3 +29,76 @@ func TestFillDBWithBytecode(t *testing.T) {
 	code2 := rawdb.ReadCode(db, crypto.Keccak256Hash(codes[1]))
 	assert.Equal(t, codes[1], code2, "Expected code2 to be correct")
 }
+
+// testNodes returns n random nodes
+func testNodes(n int) [][]byte {
+	r := rand.New(rand.NewSource(1))
+	nodes := make([][]byte, n)
+	for i := range nodes {
+		nodes[i] = make([]byte, 32+r.Intn(500))
+		r.Read(nodes[i])
+	}
+	return nodes
+}
+
+// dumpDB returns every key-value pair of the database
+func dumpDB(t *testing.T, db ethdb.Database) map[string][]byte {
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+
+	kvs := make(map[string][]byte)
+	for it.Next() {
+		kvs[string(it.Key())] = bytes.Clone(it.Value())
+	}
+	require.NoError(t, it.Error())
+	return kvs
+}
+
+func TestWriteNodesToHashDBBatched(t *testing.T) {
+	nodes := testNodes(1000)
+
+	expected := rawdb.NewMemoryDatabase()
+	WriteNodesToHashDB(expected, nodes...)
+
+	for _, batchSize := range []int{0, 1, 7, 1000, 5000} {
+		t.Run(fmt.Sprintf("batch size %d", batchSize), func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			require.NoError(t, WriteNodesToHashDBBatched(db, batchSize, nodes...))
+			assert.Equal(t, dumpDB(t, expected), dumpDB(t, db))
+		})
+	}
+}
+
+func BenchmarkWriteNodesToHashDB(b *testing.B) {
+	nodes := testNodes(10_000)
+
+	newDB := func(b *testing.B) ethdb.Database {
+		kvdb, err := leveldb.New(b.TempDir(), 16, 16, "", false)
+		require.NoError(b, err)
+		db := rawdb.NewDatabase(kvdb)
+		b.Cleanup(func() { db.Close() })
+		return db
+	}
+
+	b.Run("per-node", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			db := newDB(b)
+			b.StartTimer()
+			WriteNodesToHashDB(db, nodes...)
+		}
+	})
+
+	for _, batchSize := range []int{100, 1000, 10_000} {
+		b.Run(fmt.Sprintf("batched-%d", batchSize), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				db := newDB(b)
+				b.StartTimer()
+				if err := WriteNodesToHashDBBatched(db, batchSize, nodes...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
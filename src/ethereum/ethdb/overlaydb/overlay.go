@@ -0,0 +1,266 @@
+package overlaydb
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+var errReadOnly = errors.New("ancient store is read-only in overlay database")
+
+// Database wraps an ethdb.Database and buffers every key-value write into an in-memory overlay, the wrapped database is never written to
+// Reads are served from the overlay first, then from the wrapped database. Ancient store writes are rejected.
+type Database struct {
+	ethdb.Database
+
+	mu     sync.RWMutex
+	writes map[string][]byte // Overlay entries, a nil value marks a deleted key
+}
+
+// New returns a new Database on top of the given database
+func New(db ethdb.Database) *Database {
+	return &Database{
+		Database: db,
+		writes:   make(map[string][]byte),
+	}
+}
+
+// Unwrap returns the wrapped database
+func (db *Database) Unwrap() ethdb.Database {
+	return db.Database
+}
+
+// Has checks if the key exists in the overlay or in the wrapped database
+func (db *Database) Has(key []byte) (bool, error) {
+	db.mu.RLock()
+	value, ok := db.writes[string(key)]
+	db.mu.RUnlock()
+	if ok {
+		return value != nil, nil
+	}
+	return db.Database.Has(key)
+}
+
+// Get retrieves the value of the key from the overlay or from the wrapped database
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.mu.RLock()
+	value, ok := db.writes[string(key)]
+	db.mu.RUnlock()
+	if ok {
+		if value == nil {
+			return nil, errors.New("not found")
+		}
+		return bytes.Clone(value), nil
+	}
+	return db.Database.Get(key)
+}
+
+// Put writes the key-value pair into the overlay
+func (db *Database) Put(key, value []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.writes[string(key)] = append([]byte{}, value...) // Non-nil even for empty values
+	return nil
+}
+
+// Delete marks the key as deleted in the overlay
+func (db *Database) Delete(key []byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.writes[string(key)] = nil
+	return nil
+}
+
+// DeleteRange marks every key in the range [start, end) as deleted in the overlay
+func (db *Database) DeleteRange(start, end []byte) error {
+	it := db.NewIterator(nil, start)
+	var keys [][]byte
+	for it.Next() && (end == nil || bytes.Compare(it.Key(), end) < 0) {
+		keys = append(keys, bytes.Clone(it.Key()))
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := db.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the number of entries written or deleted in the overlay
+func (db *Database) Size() int {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.writes)
+}
+
+// Compact is a no-op, the wrapped database is not compacted
+func (db *Database) Compact(_, _ []byte) error {
+	return nil
+}
+
+// ModifyAncients is rejected as the ancient store is read-only
+func (db *Database) ModifyAncients(func(ethdb.AncientWriteOp) error) (int64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateHead is rejected as the ancient store is read-only
+func (db *Database) TruncateHead(uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// TruncateTail is rejected as the ancient store is read-only
+func (db *Database) TruncateTail(uint64) (uint64, error) {
+	return 0, errReadOnly
+}
+
+// Sync is a no-op, nothing is ever written to the ancient store
+func (db *Database) Sync() error {
+	return nil
+}
+
+// Close discards the overlay, the wrapped database is left open
+func (db *Database) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.writes = make(map[string][]byte)
+	return nil
+}
+
+// NewBatch creates a batch buffering writes until written into the overlay
+func (db *Database) NewBatch() ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewBatchWithSize creates a batch buffering writes until written into the overlay
+func (db *Database) NewBatchWithSize(int) ethdb.Batch {
+	return &batch{db: db}
+}
+
+// NewIterator iterates over the wrapped database entries merged with the overlay entries, in key order
+func (db *Database) NewIterator(prefix, start []byte) ethdb.Iterator {
+	db.mu.RLock()
+	from := append(bytes.Clone(prefix), start...)
+	var keys []string
+	for key := range db.writes {
+		if bytes.HasPrefix([]byte(key), prefix) && key >= string(from) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	entries := make([]entry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, entry{key: []byte(key), value: bytes.Clone(db.writes[key])})
+	}
+	db.mu.RUnlock()
+
+	it := &iterator{base: db.Database.NewIterator(prefix, start), overlay: entries}
+	it.baseOk = it.base.Next()
+	return it
+}
+
+type entry struct {
+	key, value []byte
+}
+
+type batch struct {
+	db     *Database
+	writes []entry // A nil value marks a deleted key
+	size   int
+}
+
+func (b *batch) Put(key, value []byte) error {
+	b.writes = append(b.writes, entry{key: bytes.Clone(key), value: append([]byte{}, value...)})
+	b.size += len(key) + len(value)
+	return nil
+}
+
+func (b *batch) Delete(key []byte) error {
+	b.writes = append(b.writes, entry{key: bytes.Clone(key)})
+	b.size += len(key)
+	return nil
+}
+
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+func (b *batch) Write() error {
+	return b.Replay(b.db)
+}
+
+func (b *batch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+func (b *batch) Replay(w ethdb.KeyValueWriter) error {
+	for _, e := range b.writes {
+		var err error
+		if e.value == nil {
+			err = w.Delete(e.key)
+		} else {
+			err = w.Put(e.key, e.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterator merges the wrapped database iterator with the sorted overlay entries, overlay entries shadow the wrapped database ones
+type iterator struct {
+	base    ethdb.Iterator
+	baseOk  bool
+	overlay []entry
+
+	key, value []byte
+}
+
+func (it *iterator) Next() bool {
+	for {
+		switch {
+		case !it.baseOk && len(it.overlay) == 0:
+			it.key, it.value = nil, nil
+			return false
+		case len(it.overlay) == 0 || (it.baseOk && bytes.Compare(it.base.Key(), it.overlay[0].key) < 0):
+			it.key, it.value = bytes.Clone(it.base.Key()), bytes.Clone(it.base.Value())
+			it.baseOk = it.base.Next()
+			return true
+		default:
+			next := it.overlay[0]
+			it.overlay = it.overlay[1:]
+			if it.baseOk && bytes.Equal(it.base.Key(), next.key) {
+				it.baseOk = it.base.Next()
+			}
+			if next.value == nil {
+				continue // Deleted in the overlay
+			}
+			it.key, it.value = next.key, next.value
+			return true
+		}
+	}
+}
+
+func (it *iterator) Error() error {
+	return it.base.Error()
+}
+
+func (it *iterator) Key() []byte {
+	return it.key
+}
+
+func (it *iterator) Value() []byte {
+	return it.value
+}
+
+func (it *iterator) Release() {
+	it.base.Release()
+}
@@ -0,0 +1,63 @@
+package overlaydb
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase(t *testing.T) {
+	base := rawdb.NewMemoryDatabase()
+	require.NoError(t, base.Put([]byte("a1"), []byte("base")))
+	require.NoError(t, base.Put([]byte("a2"), []byte("base")))
+	require.NoError(t, base.Put([]byte("b1"), []byte("base")))
+
+	db := New(base)
+	require.NoError(t, db.Put([]byte("a1"), []byte("overlay")))
+	require.NoError(t, db.Put([]byte("a3"), []byte("overlay")))
+	require.NoError(t, db.Delete([]byte("a2")))
+
+	batch := db.NewBatch()
+	require.NoError(t, batch.Put([]byte("a0"), []byte("batch")))
+	require.NoError(t, batch.Delete([]byte("b1")))
+	require.NoError(t, batch.Write())
+
+	value, err := db.Get([]byte("a1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("overlay"), value)
+	has, err := db.Has([]byte("a2"))
+	require.NoError(t, err)
+	assert.False(t, has)
+	_, err = db.Get([]byte("b1"))
+	assert.Error(t, err)
+
+	// Iteration merges the overlay with the base database, in key order
+	var keys, values []string
+	it := db.NewIterator([]byte("a"), nil)
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		values = append(values, string(it.Value()))
+	}
+	it.Release()
+	require.NoError(t, it.Error())
+	assert.Equal(t, []string{"a0", "a1", "a3"}, keys)
+	assert.Equal(t, []string{"batch", "overlay", "overlay"}, values)
+
+	// The base database is left untouched
+	value, err = base.Get([]byte("a1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("base"), value)
+	for _, key := range []string{"a2", "b1"} {
+		has, err := base.Has([]byte(key))
+		require.NoError(t, err)
+		assert.True(t, has)
+	}
+	has, err = base.Has([]byte("a3"))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = db.ModifyAncients(nil)
+	assert.Error(t, err)
+}
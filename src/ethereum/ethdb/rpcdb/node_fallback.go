@@ -0,0 +1,94 @@
+package rpcdb
+
+import (
+	"context"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// NodeFetcher fetches trie nodes by hash from a remote source
+type NodeFetcher interface {
+	// FetchNode returns the trie node with the given hash
+	FetchNode(ctx context.Context, hash gethcommon.Hash) ([]byte, error)
+}
+
+type debugDBNodeFetcher struct {
+	client *gethrpc.Client
+}
+
+// NewDebugDBNodeFetcher creates a node fetcher reading nodes from the database of a hash based node with the debug_dbGet RPC method
+func NewDebugDBNodeFetcher(client *gethrpc.Client) NodeFetcher {
+	return &debugDBNodeFetcher{client: client}
+}
+
+func (f *debugDBNodeFetcher) FetchNode(ctx context.Context, hash gethcommon.Hash) ([]byte, error) {
+	var blob hexutil.Bytes
+	if err := f.client.CallContext(ctx, &blob, "debug_dbGet", hash.Hex()); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// NodeFallbackDatabase wraps an ethdb.Database and fetches trie nodes missing from the database with a node fetcher
+// Fetched nodes are verified against their hash and written to the wrapped database, so each node is fetched once.
+type NodeFallbackDatabase struct {
+	ethdb.Database
+	fetcher NodeFetcher
+	retries int
+}
+
+// NodeFallback returns a new NodeFallbackDatabase, failed node fetches are retried up to retries times before giving up
+func NodeFallback(db ethdb.Database, fetcher NodeFetcher, retries int) *NodeFallbackDatabase {
+	return &NodeFallbackDatabase{
+		Database: db,
+		fetcher:  fetcher,
+		retries:  retries,
+	}
+}
+
+// Unwrap returns the wrapped database
+func (db *NodeFallbackDatabase) Unwrap() ethdb.Database {
+	return db.Database
+}
+
+// Get retrieves the value for a key.
+// Trie node keys (i.e. node hashes) missing from the underlying database are fetched with the node fetcher.
+func (db *NodeFallbackDatabase) Get(key []byte) ([]byte, error) {
+	value, err := db.Database.Get(key)
+	if err == nil || len(key) != gethcommon.HashLength {
+		return value, err
+	}
+
+	hash := gethcommon.BytesToHash(key)
+	// Note: We use the context.TODO() because the ethdb.Database.Get method does not accept a context.
+	var fetchErr error
+	for attempt := 0; attempt <= db.retries; attempt++ {
+		var blob []byte
+		blob, fetchErr = db.fetcher.FetchNode(context.TODO(), hash)
+		if fetchErr != nil {
+			continue
+		}
+		if crypto.Keccak256Hash(blob) != hash {
+			return nil, fmt.Errorf("fetched node %v does not match its hash", hash.Hex())
+		}
+		if err := db.Database.Put(key, blob); err != nil {
+			return nil, fmt.Errorf("failed to store fetched node %v: %v", hash.Hex(), err)
+		}
+		return blob, nil
+	}
+
+	return nil, fmt.Errorf("failed to fetch node %v after %d attempt(s): %v", hash.Hex(), db.retries+1, fetchErr)
+}
+
+// Has checks if the database has a key, trie nodes missing from the underlying database are fetched with the node fetcher.
+func (db *NodeFallbackDatabase) Has(key []byte) (bool, error) {
+	if _, err := db.Get(key); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
@@ -24,6 +24,11 @@ func Hack(db ethdb.Database, remote rpc.Client) *Database {
 	}
 }
 
+// Unwrap returns the wrapped database
+func (db *Database) Unwrap() ethdb.Database {
+	return db.Database
+}
+
 // decodeHeaderNumberAndHash decodes the header number and hash given a Geth ethdb database key.
 // It returns the header number, hash, and a boolean indicating if the key is a header key.
 func decodeHeaderNumberAndHash(key []byte) (uint64, gethcommon.Hash, bool) {
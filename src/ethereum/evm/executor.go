@@ -3,14 +3,19 @@ package evm
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"runtime"
+	"sync"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	gethstate "github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
 	gethparams "github.com/ethereum/go-ethereum/params"
 	"github.com/kkrt-labs/go-utils/log"
 )
@@ -20,9 +25,27 @@ type ExecParams struct {
 	VMConfig *vm.Config // VM configuration
 	Block    *types.Block
 	Validate bool // Whether to the validate the block at the end of execution
+
+	// StateTestMode restricts validation to the resulting state root (as in state tests)
+	// Consensus related checks (gas used, bloom, receipts and requests roots) are skipped
+	StateTestMode bool
+
+	// Checks restricts validation to the given checks (all checks if zero)
+	Checks ValidationCheck
+
 	State    *gethstate.StateDB
 	Chain    *core.HeaderChain
 	Reporter func(error)
+
+	// Precompiles are additional precompiled contracts merged with the fork default precompiles (optional)
+	Precompiles map[gethcommon.Address]vm.PrecompiledContract
+
+	// Prewarm enables recovering transaction senders in parallel before sequential execution (optional)
+	Prewarm        bool
+	PrewarmWorkers int // Number of pre-warming workers, defaults to the number of CPUs
+
+	// NoPrefetch disables the background state prefetcher and senders pre-warming, so the block is executed purely sequentially (e.g. for deterministic benchmarking)
+	NoPrefetch bool
 }
 
 // Executor is an interface for executing EVM blocks.
@@ -50,7 +73,21 @@ func NewExecutor() Executor {
 
 // Execute executes an EVM block.
 // It processes the block on the given state and chain then validates the block if requested.
+// It errors if the underlying database of the state is already in use by another execution (see acquireDB for the guard limits).
+// Execution is aborted between transactions once ctx is done, returning a *CanceledError.
 func (e *executor) Execute(ctx context.Context, params *ExecParams) (res *core.ProcessResult, execErr error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("block processing failed: %w", &CanceledError{Err: err})
+	}
+
+	if trieDB := params.State.Database().TrieDB(); trieDB != nil {
+		release, err := acquireDB(trieDB.Disk())
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
 	if vmCfg := params.VMConfig; vmCfg != nil && vmCfg.Tracer != nil {
 		if vmCfg.Tracer.OnBlockStart != nil {
 			vmCfg.Tracer.OnBlockStart(tracing.BlockEvent{
@@ -65,7 +102,7 @@ func (e *executor) Execute(ctx context.Context, params *ExecParams) (res *core.P
 	}
 
 	if params.Chain.Config().IsByzantium(params.Block.Number()) {
-		if params.VMConfig.StatelessSelfValidation {
+		if params.VMConfig.StatelessSelfValidation && !params.NoPrefetch {
 			// Create witness for tracking state accesses
 			witness, err := stateless.NewWitness(params.Block.Header(), params.Chain)
 			if err != nil {
@@ -81,6 +118,36 @@ func (e *executor) Execute(ctx context.Context, params *ExecParams) (res *core.P
 		}
 	}
 
+	if params.Prewarm && !params.NoPrefetch {
+		prewarmSenders(params.Chain.Config(), params.Block, params.PrewarmWorkers)
+	}
+
+	// Fee caps are validated upfront, so every violating transaction is reported rather than only the first one failing processing
+	var gasPrices *gasPriceRecorder
+	if checks := params.EnabledChecks(); params.Validate && !params.StateTestMode {
+		if checks.Has(CheckFeeCaps) {
+			if err := validateFeeCaps(params.Block, params.VMConfig); err != nil {
+				execErr = fmt.Errorf("block validation failed: %v", err)
+				return
+			}
+		}
+
+		gasPrices = new(gasPriceRecorder)
+		tracer := params.VMConfig.Tracer
+		params.VMConfig.Tracer = MuxHooks(tracer, gasPrices.hooks())
+		defer func() {
+			params.VMConfig.Tracer = tracer
+		}()
+	}
+
+	if ctx.Done() != nil {
+		tracer := params.VMConfig.Tracer
+		params.VMConfig.Tracer = MuxHooks(tracer, NewCancelTracer(ctx).Hooks())
+		defer func() {
+			params.VMConfig.Tracer = tracer
+		}()
+	}
+
 	// Process block on given state
 	res, execErr = e.processBlock(ctx, params)
 	if execErr != nil {
@@ -88,17 +155,38 @@ func (e *executor) Execute(ctx context.Context, params *ExecParams) (res *core.P
 	}
 
 	if params.Validate {
-		execErr = e.validateBlock(ctx, params, res)
+		execErr = e.validateBlock(ctx, params, res, gasPrices)
 	}
 
 	return
 }
 
-func (e *executor) processBlock(ctx context.Context, params *ExecParams) (*core.ProcessResult, error) {
-	processor := core.NewStateProcessor(params.Chain.Config(), params.Chain)
-
+func (e *executor) processBlock(ctx context.Context, params *ExecParams) (res *core.ProcessResult, err error) {
 	log.LoggerFromContext(ctx).Info("Process block...")
-	res, err := processor.Process(params.Block, params.State, *params.VMConfig)
+
+	// The step limit, call depth limit, gas target and cancel tracers abort the execution by panicking with a *StepLimitError, a *CallDepthLimitError, a *GasTargetReached and a *CanceledError
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case *StepLimitError:
+				res, err = nil, fmt.Errorf("block processing failed: %w", r)
+			case *CallDepthLimitError:
+				res, err = nil, fmt.Errorf("block processing failed: %w", r)
+			case *GasTargetReached:
+				res, err = nil, fmt.Errorf("block processing stopped: %w", r)
+			case *CanceledError:
+				res, err = nil, fmt.Errorf("block processing failed: %w", r)
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	if len(params.Precompiles) > 0 {
+		res, err = processWithPrecompiles(params.Chain, params.Block, params.State, *params.VMConfig, params.Precompiles)
+	} else {
+		res, err = core.NewStateProcessor(params.Chain.Config(), params.Chain).Process(params.Block, params.State, *params.VMConfig)
+	}
 	if err != nil {
 		if params.Reporter != nil {
 			params.Reporter(summarizeBadBlockError(params.Chain.Config(), params.Block, res, err))
@@ -108,10 +196,38 @@ func (e *executor) processBlock(ctx context.Context, params *ExecParams) (*core.
 	return res, err
 }
 
-func (e *executor) validateBlock(ctx context.Context, params *ExecParams, res *core.ProcessResult) error {
+func (e *executor) validateBlock(ctx context.Context, params *ExecParams, res *core.ProcessResult, gasPrices *gasPriceRecorder) error {
+	checks := params.EnabledChecks()
+	if params.StateTestMode {
+		if !checks.Has(CheckStateRoot) {
+			return nil
+		}
+		return e.validateStateRoot(ctx, params)
+	}
+
 	log.LoggerFromContext(ctx).Info("Validate block & state transition...")
-	validator := core.NewBlockValidator(params.Chain.Config(), nil)
-	err := validator.ValidateState(params.Block, params.State, res, false)
+	if checks.Has(CheckBlobGas) {
+		if err := validateBlobGas(params.Chain, params.Block.Header()); err != nil {
+			return fmt.Errorf("block validation failed: %v", err)
+		}
+	}
+
+	if checks.Has(CheckEffectiveGasPrices) {
+		if err := validateEffectiveGasPrices(params.Chain.Config(), params.Block, gasPrices.gasBuys); err != nil {
+			return fmt.Errorf("block validation failed: %v", err)
+		}
+	}
+
+	if checks.Has(CheckRequests) {
+		if err := validateRequests(params.Chain.Config(), params.Block.Header(), res.Requests); err != nil {
+			if params.Reporter != nil {
+				params.Reporter(summarizeBadBlockError(params.Chain.Config(), params.Block, res, err))
+			}
+			return fmt.Errorf("block validation failed: %v", err)
+		}
+	}
+
+	err := validateResult(params, res, checks)
 	if params.Reporter != nil {
 		params.Reporter(summarizeBadBlockError(params.Chain.Config(), params.Block, res, err))
 	}
@@ -121,6 +237,99 @@ func (e *executor) validateBlock(ctx context.Context, params *ExecParams, res *c
 	return nil
 }
 
+// ValidationChecks returns the names of the checks performed when executing with the given parameters, in the order they run
+// It returns nil if validation is disabled.
+func ValidationChecks(params *ExecParams) []string {
+	if !params.Validate {
+		return nil
+	}
+	checks := params.EnabledChecks() &^ (CheckFeeRecipient | CheckBeaconRoot)
+	if params.StateTestMode {
+		checks &= CheckStateRoot
+	}
+
+	cfg, header := params.Chain.Config(), params.Block.Header()
+	if !cfg.IsCancun(header.Number, header.Time) {
+		checks &^= CheckBlobGas
+	}
+	return checks.Names()
+}
+
+// EnabledChecks returns the validation checks to perform
+func (params *ExecParams) EnabledChecks() ValidationCheck {
+	if params.Checks == 0 {
+		return AllChecks
+	}
+	return params.Checks
+}
+
+func (e *executor) validateStateRoot(ctx context.Context, params *ExecParams) error {
+	log.LoggerFromContext(ctx).Info("Validate state root...")
+	root := params.State.IntermediateRoot(params.Chain.Config().IsEIP158(params.Block.Number()))
+	if root != params.Block.Root() {
+		return fmt.Errorf("state root mismatch (expected %v, computed %v)", params.Block.Root().Hex(), root.Hex())
+	}
+	return nil
+}
+
+// validateBlobGas validates the blob gas fields of Cancun blocks against their parent, as per the EIP-4844 update rule
+func validateBlobGas(chain *core.HeaderChain, header *types.Header) error {
+	if !chain.Config().IsCancun(header.Number, header.Time) {
+		return nil
+	}
+
+	parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return fmt.Errorf("missing parent header %v to validate blob gas", header.ParentHash.Hex())
+	}
+
+	if err := eip4844.VerifyEIP4844Header(parent, header); err != nil {
+		return fmt.Errorf("invalid blob gas: %v", err)
+	}
+	return nil
+}
+
+// inUseDBs holds the underlying databases used by executions in progress
+// It is shared by every executor as executors are stateless and created per execution.
+var inUseDBs sync.Map
+
+// unwrapper is implemented by database wrappers (e.g. overlaydb.Database, rpcdb.NodeFallbackDatabase) to expose the wrapped database
+type unwrapper interface {
+	Unwrap() ethdb.Database
+}
+
+// unwrapDB returns the innermost database wrapped by db
+func unwrapDB(db ethdb.KeyValueStore) ethdb.KeyValueStore {
+	for {
+		u, ok := db.(unwrapper)
+		if !ok {
+			return db
+		}
+		db = u.Unwrap()
+	}
+}
+
+// acquireDB marks the underlying database as in use, it errors if the database is already in use
+// Concurrent executions on a shared database would silently corrupt the state
+//
+// Databases are tracked by the identity of the innermost database, wrappers implementing Unwrap are unwrapped first so
+// executions on distinct wrappers of the same database are detected.
+func acquireDB(db ethdb.KeyValueStore) (release func(), err error) {
+	if db != nil {
+		db = unwrapDB(db)
+	}
+	if db == nil || !reflect.TypeOf(db).Comparable() {
+		// Database can not be tracked
+		return func() {}, nil
+	}
+
+	if _, loaded := inUseDBs.LoadOrStore(db, struct{}{}); loaded {
+		return nil, fmt.Errorf("database already in use by another execution")
+	}
+
+	return func() { inUseDBs.Delete(db) }, nil
+}
+
 // summarizeBadBlockError generates a human-readable summary of a bad block.
 func summarizeBadBlockError(chainCfg *gethparams.ChainConfig, block *types.Block, res *core.ProcessResult, err error) error {
 	var receipts types.Receipts
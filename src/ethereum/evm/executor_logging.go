@@ -2,6 +2,7 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
@@ -53,16 +54,20 @@ func ExecutorWithLog(namespaces ...string) ExecutorDecorator {
 		return ExecutorFunc(func(ctx context.Context, params *ExecParams) (*core.ProcessResult, error) {
 			logger := log.LoggerWithFieldsFromNamespaceContext(ctx, namespaces...)
 
-			// Set tracing logger
-			params.VMConfig.Tracer = NewLoggerTracer(logger).Hooks()
+			// Set tracing logger (combined with any tracer already set)
+			params.VMConfig.Tracer = MuxHooks(params.VMConfig.Tracer, NewLoggerTracer(logger).Hooks())
 
 			logger.Info("Start block execution...")
 			res, err := executor.Execute(log.WithLogger(ctx, logger), params)
-			if err != nil {
+			var gasTargetReached *GasTargetReached
+			switch {
+			case errors.As(err, &gasTargetReached):
+				logger.Info("Block execution stopped at gas target", zap.Error(err))
+			case err != nil:
 				logger.Error("Block execution failed",
 					zap.Error(err),
 				)
-			} else {
+			default:
 				logger.Info("Block execution succeeded!",
 					zap.Uint64("gasUsed", res.GasUsed),
 				)
@@ -116,11 +121,15 @@ func (t *LoggerTracer) OnTxStart(vm *tracing.VMContext, tx *gethtypes.Transactio
 
 // OnTxEnd logs transaction execution end
 func (t *LoggerTracer) OnTxEnd(receipt *gethtypes.Receipt, err error) {
-	if err != nil {
+	switch {
+	case err != nil:
 		t.txLogger.Error("failed to execute transaction",
 			zap.Error(err),
 		)
-	} else {
+	case receipt == nil:
+		// Execution was aborted in the middle of the transaction (e.g. by the step limit tracer)
+		t.txLogger.Error("transaction execution aborted")
+	default:
 		t.txLogger.Debug("Executed transaction",
 			zap.String("receipt.txHash", receipt.TxHash.Hex()),
 			zap.Uint64("receipt.status", receipt.Status),
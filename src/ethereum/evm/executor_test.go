@@ -0,0 +1,184 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/ethdb/overlaydb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorSharedDB(t *testing.T) {
+	cfg := params.MergedTestChainConfig
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, nil)
+	genesis := (&core.Genesis{Config: cfg, BaseFee: core.DefaultGenesisBlock().BaseFee}).MustCommit(db, trieDB)
+
+	engine := beacon.New(ethash.NewFaker())
+	blocks, _ := core.GenerateChain(cfg, genesis, engine, db, 1, func(_ int, b *core.BlockGen) { b.SetPoS() })
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+
+	// newParams returns execution parameters on a new state backed by the given trie database
+	newParams := func(trieDB *triedb.Database, tracer *tracing.Hooks) *ExecParams {
+		state, err := gethstate.New(genesis.Root(), gethstate.NewDatabase(trieDB, nil))
+		require.NoError(t, err)
+		return &ExecParams{
+			VMConfig: &vm.Config{Tracer: tracer},
+			Block:    blocks[0],
+			Validate: true,
+			Chain:    hc,
+			State:    state,
+		}
+	}
+
+	// First execution blocks until the second execution has been attempted
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := NewExecutor().Execute(context.Background(), newParams(trieDB, &tracing.Hooks{
+			OnBlockStart: func(tracing.BlockEvent) {
+				close(started)
+				<-release
+			},
+		}))
+		done <- err
+	}()
+
+	<-started
+	_, err = NewExecutor().Execute(context.Background(), newParams(trieDB, nil))
+	require.Error(t, err)
+	assert.Equal(t, "database already in use by another execution", err.Error())
+
+	// Wrappers around the shared database are unwrapped
+	_, err = NewExecutor().Execute(context.Background(), newParams(triedb.NewDatabase(overlaydb.New(db), nil), nil))
+	require.Error(t, err)
+	assert.Equal(t, "database already in use by another execution", err.Error())
+
+	close(release)
+	require.NoError(t, <-done)
+
+	// Database is released once the execution is over
+	_, err = NewExecutor().Execute(context.Background(), newParams(trieDB, nil))
+	assert.NoError(t, err)
+}
+
+func TestExecutorValidateBlobGas(t *testing.T) {
+	cfg := params.MergedTestChainConfig
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, nil)
+	genesis := (&core.Genesis{Config: cfg, BaseFee: core.DefaultGenesisBlock().BaseFee}).MustCommit(db, trieDB)
+
+	engine := beacon.New(ethash.NewFaker())
+	blocks, _ := core.GenerateChain(cfg, genesis, engine, db, 1, func(_ int, b *core.BlockGen) { b.SetPoS() })
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+
+	execute := func(block *types.Block) error {
+		state, err := gethstate.New(genesis.Root(), gethstate.NewDatabase(trieDB, nil))
+		require.NoError(t, err)
+		_, err = NewExecutor().Execute(context.Background(), &ExecParams{
+			VMConfig: &vm.Config{},
+			Block:    block,
+			Validate: true,
+			Chain:    hc,
+			State:    state,
+		})
+		return err
+	}
+
+	require.NoError(t, execute(blocks[0]))
+
+	// Child excess blob gas does not follow from the parent excess blob gas and blob gas used
+	header := blocks[0].Header()
+	excessBlobGas := *header.ExcessBlobGas + params.BlobTxBlobGasPerBlob
+	header.ExcessBlobGas = &excessBlobGas
+
+	err = execute(types.NewBlockWithHeader(header).WithBody(*blocks[0].Body()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid blob gas: invalid excessBlobGas")
+}
+
+func TestExecutorCancel(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	cfg := params.MergedTestChainConfig
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, nil)
+	genesis := (&core.Genesis{
+		Config:  cfg,
+		BaseFee: core.DefaultGenesisBlock().BaseFee,
+		Alloc:   types.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+	}).MustCommit(db, trieDB)
+
+	engine := beacon.New(ethash.NewFaker())
+	signer := types.LatestSigner(cfg)
+	blocks, _ := core.GenerateChain(cfg, genesis, engine, db, 1, func(_ int, b *core.BlockGen) {
+		b.SetPoS()
+		for i := 0; i < 2; i++ {
+			tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   cfg.ChainID,
+				Nonce:     b.TxNonce(sender),
+				To:        &gethcommon.Address{0x1},
+				Gas:       params.TxGas,
+				GasFeeCap: b.BaseFee(),
+				Value:     big.NewInt(1),
+			})
+			b.AddTx(tx)
+		}
+	})
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+
+	execute := func(ctx context.Context, tracer *tracing.Hooks) error {
+		state, err := gethstate.New(genesis.Root(), gethstate.NewDatabase(trieDB, nil))
+		require.NoError(t, err)
+		_, err = NewExecutor().Execute(ctx, &ExecParams{
+			VMConfig: &vm.Config{Tracer: tracer},
+			Block:    blocks[0],
+			Validate: true,
+			Chain:    hc,
+			State:    state,
+		})
+		return err
+	}
+
+	// Context done before execution
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = execute(ctx, nil)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Context done during execution, the execution is aborted at the next transaction
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var txs int
+	err = execute(ctx, &tracing.Hooks{
+		OnTxEnd: func(*types.Receipt, error) {
+			txs++
+			cancel()
+		},
+	})
+	var canceledErr *CanceledError
+	require.ErrorAs(t, err, &canceledErr)
+	assert.Equal(t, 1, canceledErr.TxIndex)
+	assert.Equal(t, 1, txs)
+
+	// Execution completes if the context is not done
+	require.NoError(t, execute(context.Background(), nil))
+}
@@ -0,0 +1,94 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	gethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// validateFeeCaps validates that the max fee per gas of every transaction of an EIP-1559 block covers the block base fee
+// Every violating transaction is reported. As with transaction processing, zero fee transactions are accepted if base fee checks are disabled.
+func validateFeeCaps(block *types.Block, vmCfg *vm.Config) error {
+	baseFee := block.BaseFee()
+	if baseFee == nil {
+		return nil
+	}
+
+	var violations []string
+	for i, tx := range block.Transactions() {
+		if vmCfg.NoBaseFee && tx.GasFeeCap().BitLen() == 0 && tx.GasTipCap().BitLen() == 0 {
+			continue
+		}
+		if tx.GasFeeCap().Cmp(baseFee) < 0 {
+			violations = append(violations, fmt.Sprintf("  transaction %d (%v): max fee per gas %v below base fee %v", i, tx.Hash().Hex(), tx.GasFeeCap(), baseFee))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d transaction(s) with max fee per gas below base fee:\n%v", len(violations), strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+// gasPriceRecorder records the gas bought by every transaction, i.e. the amount debited from the sender to pay for the transaction gas limit (and blob gas)
+type gasPriceRecorder struct {
+	gasBuys []*big.Int
+}
+
+func (r *gasPriceRecorder) hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: func(_ *tracing.VMContext, _ *types.Transaction, _ gethcommon.Address) {
+			r.gasBuys = append(r.gasBuys, new(big.Int))
+		},
+		OnBalanceChange: func(_ gethcommon.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+			if reason == tracing.BalanceDecreaseGasBuy && len(r.gasBuys) > 0 {
+				r.gasBuys[len(r.gasBuys)-1].Sub(prev, new)
+			}
+		},
+	}
+}
+
+// validateEffectiveGasPrices validates the gas price every transaction was charged against its effective gas price,
+// i.e. min(max priority fee + base fee, max fee) for EIP-1559 blocks and the transaction gas price otherwise
+func validateEffectiveGasPrices(chainCfg *gethparams.ChainConfig, block *types.Block, gasBuys []*big.Int) error {
+	txs := block.Transactions()
+	if len(gasBuys) != len(txs) {
+		return fmt.Errorf("executed transactions count mismatch (block %d, executed %d)", len(txs), len(gasBuys))
+	}
+
+	blobBaseFee := new(big.Int)
+	if chainCfg.IsCancun(block.Number(), block.Time()) && block.ExcessBlobGas() != nil {
+		blobBaseFee = eip4844.CalcBlobFee(*block.ExcessBlobGas())
+	}
+
+	var violations []string
+	for i, tx := range txs {
+		// Gas bought is gas limit * gas price + blob gas * blob base fee
+		price := new(big.Int).Sub(gasBuys[i], new(big.Int).Mul(new(big.Int).SetUint64(tx.BlobGas()), blobBaseFee))
+		price.Div(price, new(big.Int).SetUint64(tx.Gas()))
+		if expected := effectiveGasPrice(tx, block.BaseFee()); price.Cmp(expected) != 0 {
+			violations = append(violations, fmt.Sprintf("  transaction %d (%v): effective gas price %v, expected %v", i, tx.Hash().Hex(), price, expected))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d transaction(s) with invalid effective gas price:\n%v", len(violations), strings.Join(violations, "\n"))
+	}
+	return nil
+}
+
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(tx.GasPrice())
+	}
+	price := new(big.Int).Add(tx.GasTipCap(), baseFee)
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
@@ -0,0 +1,69 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorValidateFeeCaps(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	cfg := params.MergedTestChainConfig
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, nil)
+	genesis := (&core.Genesis{
+		Config:  cfg,
+		BaseFee: core.DefaultGenesisBlock().BaseFee,
+		Alloc:   types.GenesisAlloc{addr: {Balance: big.NewInt(params.Ether)}},
+	}).MustCommit(db, trieDB)
+
+	engine := beacon.New(ethash.NewFaker())
+	blocks, _ := core.GenerateChain(cfg, genesis, engine, db, 1, func(_ int, b *core.BlockGen) { b.SetPoS() })
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+
+	execute := func(block *types.Block) error {
+		state, err := gethstate.New(genesis.Root(), gethstate.NewDatabase(trieDB, nil))
+		require.NoError(t, err)
+		_, err = NewExecutor().Execute(context.Background(), &ExecParams{
+			VMConfig: &vm.Config{},
+			Block:    block,
+			Validate: true,
+			Chain:    hc,
+			State:    state,
+		})
+		return err
+	}
+
+	// Transaction with a max fee per gas below the block base fee
+	baseFee := blocks[0].BaseFee()
+	tx, err := types.SignNewTx(key, types.LatestSigner(cfg), &types.DynamicFeeTx{
+		ChainID:   cfg.ChainID,
+		Gas:       params.TxGas,
+		GasFeeCap: new(big.Int).Sub(baseFee, big.NewInt(1)),
+		GasTipCap: big.NewInt(0),
+		To:        &addr,
+	})
+	require.NoError(t, err)
+
+	err = execute(blocks[0].WithBody(types.Body{Transactions: []*types.Transaction{tx}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 transaction(s) with max fee per gas below base fee")
+	assert.Contains(t, err.Error(), tx.Hash().Hex())
+}
@@ -0,0 +1,36 @@
+package evm
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	gethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// prewarmSenders recovers the senders of the block transactions in parallel before sequential execution
+// Recovered senders are cached on the transactions, so execution then skips the signature recoveries.
+// Pre-warming never touches the state, so the execution result (including the accessed state) is the same as with serial execution.
+func prewarmSenders(chainCfg *gethparams.ChainConfig, block *types.Block, workers int) {
+	txs := block.Transactions()
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+
+	signer := types.MakeSigner(chainCfg, block.Number(), block.Time())
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(txs); i += workers {
+				// Invalid signatures are ignored here, they are reported by sequential execution
+				_, _ = types.Sender(signer, txs[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,100 @@
+package evm
+
+import (
+	"fmt"
+	"maps"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// processWithPrecompiles processes the block similarly to core.StateProcessor
+// except that the given precompiled contracts are merged with the fork default precompiles of the EVM
+//
+// Note: custom precompiles are not pre-warmed in the transactions access list (EIP-2929)
+func processWithPrecompiles(chain *core.HeaderChain, block *types.Block, statedb *gethstate.StateDB, cfg vm.Config, precompiles map[gethcommon.Address]vm.PrecompiledContract) (*core.ProcessResult, error) {
+	var (
+		config      = chain.Config()
+		receipts    types.Receipts
+		usedGas     = new(uint64)
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		allLogs     []*types.Log
+		gp          = new(core.GasPool).AddGas(block.GasLimit())
+	)
+
+	// Mutate the block and state according to any hard-fork specs
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+
+	var (
+		context = core.NewEVMBlockContext(header, chain, nil)
+		signer  = types.MakeSigner(config, header.Number, header.Time)
+	)
+
+	vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, config, cfg)
+
+	// Merge custom precompiles with the fork defaults
+	active := vm.ActivePrecompiledContracts(config.Rules(context.BlockNumber, context.Random != nil, context.Time))
+	maps.Copy(active, precompiles)
+	vmenv.SetPrecompiles(active)
+
+	// Apply pre-execution system calls.
+	var tracingStateDB = vm.StateDB(statedb)
+	if hooks := cfg.Tracer; hooks != nil {
+		tracingStateDB = gethstate.NewHookedState(statedb, hooks)
+	}
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		core.ProcessBeaconBlockRoot(*beaconRoot, vmenv, tracingStateDB)
+	}
+	if config.IsPrague(block.Number(), block.Time()) {
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, tracingStateDB)
+	}
+
+	// Iterate over and process the individual transactions
+	for i, tx := range block.Transactions() {
+		msg, err := core.TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+
+		receipt, err := core.ApplyTransactionWithEVM(msg, config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+
+	// Read requests if Prague is enabled.
+	var requests [][]byte
+	if config.IsPrague(block.Number(), block.Time()) {
+		// EIP-6110 deposits
+		depositRequests, err := core.ParseDepositLogs(allLogs, config)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, depositRequests)
+		// EIP-7002 withdrawals
+		requests = append(requests, core.ProcessWithdrawalQueue(vmenv, tracingStateDB))
+		// EIP-7251 consolidations
+		requests = append(requests, core.ProcessConsolidationQueue(vmenv, tracingStateDB))
+	}
+
+	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
+	chain.Engine().Finalize(chain, header, tracingStateDB, block.Body())
+
+	return &core.ProcessResult{
+		Receipts: receipts,
+		Requests: requests,
+		Logs:     allLogs,
+		GasUsed:  *usedGas,
+	}, nil
+}
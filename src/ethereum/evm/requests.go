@@ -0,0 +1,66 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	gethparams "github.com/ethereum/go-ethereum/params"
+)
+
+// requestTypes are the EIP-7685 execution layer request types, with their name and the size of a single request
+var requestTypes = map[byte]struct {
+	name string
+	size int
+}{
+	0x00: {"deposits", 192},       // EIP-6110
+	0x01: {"withdrawals", 76},     // EIP-7002
+	0x02: {"consolidations", 116}, // EIP-7251
+}
+
+// validateRequests validates the EIP-7685 requests hash of Prague blocks against the requests collected during execution
+// On mismatch, the returned error details the computed requests of every type
+func validateRequests(chainCfg *gethparams.ChainConfig, header *types.Header, requests [][]byte) error {
+	if !chainCfg.IsPrague(header.Number, header.Time) {
+		if header.RequestsHash != nil {
+			return fmt.Errorf("unexpected requests hash before Prague")
+		}
+		return nil
+	}
+
+	if header.RequestsHash == nil {
+		return fmt.Errorf("missing requests hash")
+	}
+
+	if hash := types.CalcRequestsHash(requests); hash != *header.RequestsHash {
+		return fmt.Errorf("requests hash mismatch (header %v, computed %v):\n%v", header.RequestsHash.Hex(), hash.Hex(), describeRequests(requests))
+	}
+	return nil
+}
+
+// describeRequests returns a human-readable summary of the requests of every type
+func describeRequests(requests [][]byte) string {
+	lines := make([]string, 0, len(requests))
+	for _, data := range requests {
+		if len(data) == 0 {
+			lines = append(lines, "  invalid empty request data")
+			continue
+		}
+
+		hash := gethcommon.Hash(sha256.Sum256(data))
+		reqType, ok := requestTypes[data[0]]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("  type %#x: %d bytes (sha256 %v)", data[0], len(data)-1, hash.Hex()))
+			continue
+		}
+
+		count := fmt.Sprintf("%d", (len(data)-1)/reqType.size)
+		if (len(data)-1)%reqType.size != 0 {
+			count = fmt.Sprintf("invalid length %d", len(data)-1)
+		}
+		lines = append(lines, fmt.Sprintf("  type %#x %v: %v (sha256 %v)", data[0], reqType.name, count, hash.Hex()))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,105 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorValidateRequests(t *testing.T) {
+	cfg := params.MergedTestChainConfig
+	key, _ := crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	// The EIP-2935 history contract is not deployed as the chain maker does not persist its parent block hash system call
+	// The deposit contract emits its call data as a deposit log
+	depositCode := []byte{
+		byte(vm.CALLDATASIZE), byte(vm.PUSH0), byte(vm.PUSH0), byte(vm.CALLDATACOPY),
+		byte(vm.CALLDATASIZE), byte(vm.PUSH0), byte(vm.LOG0), byte(vm.STOP),
+	}
+	gspec := &core.Genesis{
+		Config:  cfg,
+		BaseFee: core.DefaultGenesisBlock().BaseFee,
+		Alloc: types.GenesisAlloc{
+			sender:                           {Balance: big.NewInt(params.Ether)},
+			cfg.DepositContractAddress:       {Code: depositCode},
+			params.BeaconRootsAddress:        {Nonce: 1, Code: params.BeaconRootsCode},
+			params.WithdrawalQueueAddress:    {Nonce: 1, Code: params.WithdrawalQueueCode},
+			params.ConsolidationQueueAddress: {Nonce: 1, Code: params.ConsolidationQueueCode},
+		},
+	}
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, nil)
+	genesis := gspec.MustCommit(db, trieDB)
+
+	engine := beacon.New(ethash.NewFaker())
+	blocks, _ := core.GenerateChain(cfg, genesis, engine, db, 1, func(_ int, b *core.BlockGen) {
+		b.SetPoS()
+		b.SetParentBeaconRoot(gethcommon.Hash{0x01})
+		// Deposit, withdrawal and consolidation requests (both queues charge a fee of 1 wei when empty)
+		for _, req := range []struct {
+			to    gethcommon.Address
+			value int64
+			data  []byte
+		}{
+			{cfg.DepositContractAddress, 0, bytes.Repeat([]byte{0x11}, 576)},
+			{params.WithdrawalQueueAddress, 1, bytes.Repeat([]byte{0x22}, 56)},
+			{params.ConsolidationQueueAddress, 1, bytes.Repeat([]byte{0x33}, 96)},
+		} {
+			tx, err := types.SignNewTx(key, types.LatestSigner(cfg), &types.DynamicFeeTx{
+				ChainID:   cfg.ChainID,
+				Nonce:     b.TxNonce(sender),
+				To:        &req.to,
+				Value:     big.NewInt(req.value),
+				Gas:       500_000,
+				GasFeeCap: b.BaseFee(),
+				Data:      req.data,
+			})
+			require.NoError(t, err)
+			b.AddTx(tx)
+		}
+	})
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+
+	state, err := gethstate.New(genesis.Root(), gethstate.NewDatabase(trieDB, nil))
+	require.NoError(t, err)
+	res, err := NewExecutor().Execute(context.Background(), &ExecParams{
+		VMConfig: &vm.Config{},
+		Block:    blocks[0],
+		Validate: true,
+		Chain:    hc,
+		State:    state,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Requests, 3)
+	assert.Len(t, res.Requests[0], 1+192)
+	assert.Len(t, res.Requests[1], 1+76)
+	assert.Len(t, res.Requests[2], 1+116)
+	require.NoError(t, validateRequests(cfg, blocks[0].Header(), res.Requests))
+
+	// Dropping the withdrawal request fails validation
+	tampered := [][]byte{res.Requests[0], {0x01}, res.Requests[2]}
+	err = validateRequests(cfg, blocks[0].Header(), tampered)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requests hash mismatch")
+	assert.Contains(t, err.Error(), "type 0x0 deposits: 1")
+	assert.Contains(t, err.Error(), "type 0x1 withdrawals: 0")
+	assert.Contains(t, err.Error(), "type 0x2 consolidations: 1")
+}
@@ -0,0 +1,110 @@
+package evm
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// CallFrame is a message call (or contract creation) executed during a transaction
+type CallFrame struct {
+	Type     vm.OpCode // CALL, CALLCODE, DELEGATECALL, STATICCALL, CREATE, CREATE2 or SELFDESTRUCT
+	From     gethcommon.Address
+	To       gethcommon.Address
+	Value    *big.Int
+	Input    []byte
+	Output   []byte
+	Gas      uint64
+	GasUsed  uint64
+	Depth    int // Depth of the call, 0 for the transaction top level call
+	Error    error
+	Reverted bool
+	Calls    []*CallFrame // Internal calls made by this call
+}
+
+// CallTracer is an EVM tracer that captures the call tree of every executed transaction
+type CallTracer struct {
+	calls      []*CallFrame
+	stack      []*CallFrame
+	systemCall bool
+}
+
+// NewCallTracer creates a new call tracer
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// OnTxStart resets the call stack for the new transaction
+func (t *CallTracer) OnTxStart(_ *tracing.VMContext, _ *gethtypes.Transaction, _ gethcommon.Address) {
+	t.stack = t.stack[:0]
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not captured
+func (t *CallTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *CallTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnEnter pushes a new call frame
+func (t *CallTracer) OnEnter(depth int, typ byte, from, to gethcommon.Address, input []byte, gas uint64, value *big.Int) {
+	if t.systemCall {
+		return
+	}
+
+	frame := &CallFrame{
+		Type:  vm.OpCode(typ),
+		From:  from,
+		To:    to,
+		Input: gethcommon.CopyBytes(input),
+		Gas:   gas,
+		Depth: depth,
+	}
+	if value != nil {
+		frame.Value = new(big.Int).Set(value)
+	}
+
+	if len(t.stack) == 0 {
+		t.calls = append(t.calls, frame)
+	} else {
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	t.stack = append(t.stack, frame)
+}
+
+// OnExit pops the current call frame
+func (t *CallTracer) OnExit(_ int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.systemCall || len(t.stack) == 0 {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+
+	frame.Output = gethcommon.CopyBytes(output)
+	frame.GasUsed = gasUsed
+	frame.Error = err
+	frame.Reverted = reverted
+}
+
+// Calls returns the top level call frame of every executed transaction, in execution order
+func (t *CallTracer) Calls() []*CallFrame {
+	return t.calls
+}
+
+// Hooks returns the call tracer hooks
+func (t *CallTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:         t.OnTxStart,
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnEnter:           t.OnEnter,
+		OnExit:            t.OnExit,
+	}
+}
@@ -0,0 +1,83 @@
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// CallDepthLimitError is the error of a transaction nesting calls deeper than allowed
+type CallDepthLimitError struct {
+	TxIndex int
+	TxHash  gethcommon.Hash
+	Address gethcommon.Address // Address of the call frame exceeding the limit
+	Depth   int
+	Limit   int
+}
+
+func (e *CallDepthLimitError) Error() string {
+	return fmt.Sprintf("transaction %d (%v) exceeded the call depth limit of %d (aborted entering depth %d at %v)", e.TxIndex, e.TxHash.Hex(), e.Limit, e.Depth, e.Address.Hex())
+}
+
+// CallDepthLimitTracer is an EVM tracer that aborts the execution when a transaction nests calls deeper than the limit
+// It is a soft cap below the 1024 call depth enforced by the EVM, detecting pathological recursion early. The top-level call has depth 0.
+//
+// The execution is aborted by panicking with a *CallDepthLimitError, which is recovered by the executor and returned as the execution error
+type CallDepthLimitTracer struct {
+	limit int
+
+	txIndex    int
+	txHash     gethcommon.Hash
+	systemCall bool
+	exceeded   *CallDepthLimitError
+}
+
+// NewCallDepthLimitTracer creates a new call depth limit tracer with the given limit
+func NewCallDepthLimitTracer(limit int) *CallDepthLimitTracer {
+	return &CallDepthLimitTracer{limit: limit, txIndex: -1}
+}
+
+// OnTxStart records the transaction being executed
+func (t *CallDepthLimitTracer) OnTxStart(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+	t.txHash = tx.Hash()
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not limited
+func (t *CallDepthLimitTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *CallDepthLimitTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnEnter aborts the execution if the entered call frame exceeds the limit
+func (t *CallDepthLimitTracer) OnEnter(depth int, _ byte, _, to gethcommon.Address, _ []byte, _ uint64, _ *big.Int) {
+	if t.systemCall || t.txIndex < 0 {
+		return
+	}
+	if depth > t.limit {
+		t.exceeded = &CallDepthLimitError{TxIndex: t.txIndex, TxHash: t.txHash, Address: to, Depth: depth, Limit: t.limit}
+		panic(t.exceeded)
+	}
+}
+
+// Exceeded returns the call depth limit error of the aborted transaction, if any
+func (t *CallDepthLimitTracer) Exceeded() *CallDepthLimitError {
+	return t.exceeded
+}
+
+// Hooks returns the call depth limit tracer hooks
+func (t *CallDepthLimitTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:         t.OnTxStart,
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnEnter:           t.OnEnter,
+	}
+}
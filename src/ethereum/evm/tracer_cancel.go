@@ -0,0 +1,53 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// CanceledError is the error of an execution aborted because its context was done
+type CanceledError struct {
+	TxIndex int
+	Err     error
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("execution canceled before transaction %d: %v", e.TxIndex, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// CancelTracer is an EVM tracer that aborts the execution between transactions once the context is done
+// A transaction in progress runs to completion, so cancellation is effective at the next transaction.
+//
+// The execution is aborted by panicking with a *CanceledError, which is recovered by the executor and returned as the execution error
+type CancelTracer struct {
+	ctx     context.Context
+	txIndex int
+}
+
+// NewCancelTracer creates a new cancel tracer for the given context
+func NewCancelTracer(ctx context.Context) *CancelTracer {
+	return &CancelTracer{ctx: ctx, txIndex: -1}
+}
+
+// OnTxStart aborts the execution if the context is done
+func (t *CancelTracer) OnTxStart(_ *tracing.VMContext, _ *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+	if err := t.ctx.Err(); err != nil {
+		panic(&CanceledError{TxIndex: t.txIndex, Err: err})
+	}
+}
+
+// Hooks returns the cancel tracer hooks
+func (t *CancelTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: t.OnTxStart,
+	}
+}
@@ -0,0 +1,84 @@
+package evm
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// LoadedCode is the bytecode of an account loaded during execution
+type LoadedCode struct {
+	Address  gethcommon.Address `json:"address"`
+	CodeHash gethcommon.Hash    `json:"codeHash"`
+}
+
+// CodeLoadTracer is an EVM tracer that records the accounts whose code is loaded during execution
+// Code is loaded when the account is called (CALL, CALLCODE, DELEGATECALL, STATICCALL, including transactions and system calls)
+// or inspected with EXTCODESIZE or EXTCODECOPY. Accounts without code are not recorded.
+type CodeLoadTracer struct {
+	state  tracing.StateDB
+	loaded map[gethcommon.Address]gethcommon.Hash
+}
+
+// NewCodeLoadTracer creates a new code load tracer, reading codes from the given state at the time they are loaded
+func NewCodeLoadTracer(state tracing.StateDB) *CodeLoadTracer {
+	return &CodeLoadTracer{
+		state:  state,
+		loaded: make(map[gethcommon.Address]gethcommon.Hash),
+	}
+}
+
+// OnEnter records the code of the called account
+func (t *CodeLoadTracer) OnEnter(_ int, typ byte, _, to gethcommon.Address, _ []byte, _ uint64, _ *big.Int) {
+	switch vm.OpCode(typ) {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.load(to)
+	}
+}
+
+// OnOpcode records the code of the account inspected by EXTCODESIZE and EXTCODECOPY
+func (t *CodeLoadTracer) OnOpcode(_ uint64, op byte, _, _ uint64, scope tracing.OpContext, _ []byte, _ int, err error) {
+	if err != nil {
+		return
+	}
+	switch vm.OpCode(op) {
+	case vm.EXTCODESIZE, vm.EXTCODECOPY:
+		if stack := scope.StackData(); len(stack) > 0 {
+			t.load(gethcommon.Address(stack[len(stack)-1].Bytes20()))
+		}
+	}
+}
+
+func (t *CodeLoadTracer) load(addr gethcommon.Address) {
+	if _, ok := t.loaded[addr]; ok {
+		return
+	}
+	if code := t.state.GetCode(addr); len(code) > 0 {
+		t.loaded[addr] = crypto.Keccak256Hash(code)
+	}
+}
+
+// LoadedCodes returns the loaded codes sorted by address
+func (t *CodeLoadTracer) LoadedCodes() []*LoadedCode {
+	codes := make([]*LoadedCode, 0, len(t.loaded))
+	for addr, hash := range t.loaded {
+		codes = append(codes, &LoadedCode{Address: addr, CodeHash: hash})
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return bytes.Compare(codes[i].Address.Bytes(), codes[j].Address.Bytes()) < 0
+	})
+	return codes
+}
+
+// Hooks returns the code load tracer hooks
+func (t *CodeLoadTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnEnter:  t.OnEnter,
+		OnOpcode: t.OnOpcode,
+	}
+}
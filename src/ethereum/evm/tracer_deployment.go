@@ -0,0 +1,70 @@
+package evm
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Deployment holds the inputs of a CREATE or CREATE2 opcode that a deployment override can modify
+type Deployment struct {
+	Op     vm.OpCode          // CREATE or CREATE2
+	Caller gethcommon.Address // Address of the deploying contract
+	Value  *big.Int           // Value endowed to the deployed contract
+	Salt   *gethcommon.Hash   // Salt of the contract address derivation (nil for CREATE, whose address derives from the caller nonce)
+}
+
+// DeploymentOverride overrides the inputs of a contract deployment in place
+type DeploymentOverride func(d *Deployment)
+
+// DeploymentOverrideTracer is an EVM tracer that lets a deployment override modify the inputs of CREATE and CREATE2 opcodes before they execute
+// It rewrites the EVM stack, so the execution diverges from the canonical one: it is meant for research (e.g. fuzzing address derivation) only.
+type DeploymentOverrideTracer struct {
+	override DeploymentOverride
+}
+
+// NewDeploymentOverrideTracer creates a new deployment override tracer
+func NewDeploymentOverrideTracer(override DeploymentOverride) *DeploymentOverrideTracer {
+	return &DeploymentOverrideTracer{override: override}
+}
+
+// OnOpcode overrides the stack operands of CREATE and CREATE2 opcodes
+func (t *DeploymentOverrideTracer) OnOpcode(_ uint64, op byte, _, _ uint64, scope tracing.OpContext, _ []byte, _ int, err error) {
+	if err != nil {
+		return
+	}
+	opcode := vm.OpCode(op)
+	if opcode != vm.CREATE && opcode != vm.CREATE2 {
+		return
+	}
+
+	// Operands are read from the top of the stack (value, offset, size and salt for CREATE2), the stack data shares the EVM stack memory
+	stack := scope.StackData()
+	if (opcode == vm.CREATE && len(stack) < 3) || (opcode == vm.CREATE2 && len(stack) < 4) {
+		return
+	}
+	value := &stack[len(stack)-1]
+	d := &Deployment{Op: opcode, Caller: scope.Address(), Value: value.ToBig()}
+	if opcode == vm.CREATE2 {
+		salt := gethcommon.Hash(stack[len(stack)-4].Bytes32())
+		d.Salt = &salt
+	}
+
+	t.override(d)
+
+	if d.Value != nil {
+		value.SetFromBig(d.Value)
+	}
+	if opcode == vm.CREATE2 && d.Salt != nil {
+		stack[len(stack)-4].SetBytes32(d.Salt[:])
+	}
+}
+
+// Hooks returns the deployment override tracer hooks
+func (t *DeploymentOverrideTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnOpcode: t.OnOpcode,
+	}
+}
@@ -0,0 +1,83 @@
+package evm
+
+import (
+	"errors"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// ExecErrorTracer is an EVM tracer that flags invalid opcode and out of gas conditions hit by executed transactions
+// Conditions are flagged whether they abort the transaction top level call or an internal call
+type ExecErrorTracer struct {
+	invalidOpcode []int
+	outOfGas      []int
+
+	txIndex    int
+	systemCall bool
+}
+
+// NewExecErrorTracer creates a new execution error tracer
+func NewExecErrorTracer() *ExecErrorTracer {
+	return &ExecErrorTracer{txIndex: -1}
+}
+
+// OnTxStart tracks the index of the executed transaction
+func (t *ExecErrorTracer) OnTxStart(_ *tracing.VMContext, _ *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not tracked
+func (t *ExecErrorTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *ExecErrorTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnExit flags the execution error of a call
+func (t *ExecErrorTracer) OnExit(_ int, _ []byte, _ uint64, err error, _ bool) {
+	if t.systemCall || err == nil || t.txIndex < 0 {
+		return
+	}
+
+	var invalidOpcodeErr *vm.ErrInvalidOpCode
+	switch {
+	case errors.As(err, &invalidOpcodeErr):
+		t.invalidOpcode = appendTxIndex(t.invalidOpcode, t.txIndex)
+	case errors.Is(err, vm.ErrOutOfGas), errors.Is(err, vm.ErrCodeStoreOutOfGas):
+		t.outOfGas = appendTxIndex(t.outOfGas, t.txIndex)
+	}
+}
+
+// InvalidOpcodeTxs returns the indexes of the transactions that hit an invalid opcode
+func (t *ExecErrorTracer) InvalidOpcodeTxs() []int {
+	return t.invalidOpcode
+}
+
+// OutOfGasTxs returns the indexes of the transactions that ran out of gas
+func (t *ExecErrorTracer) OutOfGasTxs() []int {
+	return t.outOfGas
+}
+
+// Hooks returns the execution error tracer hooks
+func (t *ExecErrorTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:         t.OnTxStart,
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnExit:            t.OnExit,
+	}
+}
+
+// appendTxIndex appends the transaction index unless it is already the last recorded one
+func appendTxIndex(indexes []int, txIndex int) []int {
+	if len(indexes) > 0 && indexes[len(indexes)-1] == txIndex {
+		return indexes
+	}
+	return append(indexes, txIndex)
+}
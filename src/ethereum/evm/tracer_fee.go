@@ -0,0 +1,56 @@
+package evm
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// FeeTracer is an EVM tracer that tallies the transaction fees credited to the block coinbase
+// Post-London only the priority fee is credited to the coinbase, the base fee is burnt.
+type FeeTracer struct {
+	fees       *big.Int
+	recipients map[gethcommon.Address]*big.Int
+}
+
+// NewFeeTracer creates a new fee tracer
+func NewFeeTracer() *FeeTracer {
+	return &FeeTracer{fees: new(big.Int), recipients: make(map[gethcommon.Address]*big.Int)}
+}
+
+// OnBalanceChange tallies transaction fee rewards
+func (t *FeeTracer) OnBalanceChange(addr gethcommon.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+	if reason != tracing.BalanceIncreaseRewardTransactionFee {
+		return
+	}
+
+	t.fees.Add(t.fees, new).Sub(t.fees, prev)
+	fees, ok := t.recipients[addr]
+	if !ok {
+		fees = big.NewInt(0)
+		t.recipients[addr] = fees
+	}
+	fees.Add(fees, new).Sub(fees, prev)
+}
+
+// Fees returns the total transaction fees credited to the coinbase across executed transactions
+func (t *FeeTracer) Fees() *big.Int {
+	return new(big.Int).Set(t.fees)
+}
+
+// Recipients returns the transaction fees credited to each recipient across executed transactions
+func (t *FeeTracer) Recipients() map[gethcommon.Address]*big.Int {
+	recipients := make(map[gethcommon.Address]*big.Int, len(t.recipients))
+	for addr, fees := range t.recipients {
+		recipients[addr] = new(big.Int).Set(fees)
+	}
+	return recipients
+}
+
+// Hooks returns the fee tracer hooks
+func (t *FeeTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBalanceChange: t.OnBalanceChange,
+	}
+}
@@ -0,0 +1,139 @@
+package evm
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// OpcodeCategory is a category of EVM opcodes used to break down the gas consumed by the execution
+type OpcodeCategory string
+
+const (
+	OpcodeCategoryArithmetic  OpcodeCategory = "arithmetic"  // ADD to SIGNEXTEND
+	OpcodeCategoryBitwise     OpcodeCategory = "bitwise"     // Comparison and bitwise operations, LT to SAR
+	OpcodeCategoryHashing     OpcodeCategory = "hashing"     // KECCAK256
+	OpcodeCategoryEnvironment OpcodeCategory = "environment" // Transaction and account environment, ADDRESS to EXTCODEHASH
+	OpcodeCategoryBlock       OpcodeCategory = "block"       // Block environment, BLOCKHASH to BLOBBASEFEE
+	OpcodeCategoryStack       OpcodeCategory = "stack"       // POP, PUSH, DUP and SWAP
+	OpcodeCategoryMemory      OpcodeCategory = "memory"      // MLOAD, MSTORE, MSTORE8, MSIZE and MCOPY
+	OpcodeCategoryStorage     OpcodeCategory = "storage"     // SLOAD, SSTORE, TLOAD and TSTORE
+	OpcodeCategoryControl     OpcodeCategory = "control"     // Control flow, STOP, JUMP, JUMPI, PC, GAS, JUMPDEST, RETURN, REVERT and INVALID
+	OpcodeCategoryLogging     OpcodeCategory = "logging"     // LOG0 to LOG4
+	OpcodeCategoryCall        OpcodeCategory = "call"        // CALL, CALLCODE, DELEGATECALL, STATICCALL, CREATE, CREATE2 and SELFDESTRUCT
+	OpcodeCategoryOther       OpcodeCategory = "other"       // Opcodes not belonging to any other category
+)
+
+// CategoryOf returns the category of the opcode
+func CategoryOf(op vm.OpCode) OpcodeCategory {
+	switch {
+	case op >= vm.ADD && op <= vm.SIGNEXTEND:
+		return OpcodeCategoryArithmetic
+	case op >= vm.LT && op <= vm.SAR:
+		return OpcodeCategoryBitwise
+	case op == vm.KECCAK256:
+		return OpcodeCategoryHashing
+	case op >= vm.ADDRESS && op <= vm.EXTCODEHASH:
+		return OpcodeCategoryEnvironment
+	case op >= vm.BLOCKHASH && op <= vm.BLOBBASEFEE:
+		return OpcodeCategoryBlock
+	case op == vm.POP || (op >= vm.PUSH0 && op <= vm.SWAP16):
+		return OpcodeCategoryStack
+	case op == vm.MLOAD || op == vm.MSTORE || op == vm.MSTORE8 || op == vm.MSIZE || op == vm.MCOPY:
+		return OpcodeCategoryMemory
+	case op == vm.SLOAD || op == vm.SSTORE || op == vm.TLOAD || op == vm.TSTORE:
+		return OpcodeCategoryStorage
+	case op == vm.STOP || op == vm.JUMP || op == vm.JUMPI || op == vm.PC || op == vm.GAS || op == vm.JUMPDEST ||
+		op == vm.RETURN || op == vm.REVERT || op == vm.INVALID:
+		return OpcodeCategoryControl
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return OpcodeCategoryLogging
+	case op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL ||
+		op == vm.CREATE || op == vm.CREATE2 || op == vm.SELFDESTRUCT:
+		return OpcodeCategoryCall
+	default:
+		return OpcodeCategoryOther
+	}
+}
+
+// GasCategoryTracer is an EVM tracer that tallies the gas consumed by the block transactions per opcode category
+// The gas of a call opcode excludes the gas forwarded to the callee, which is attributed to the opcodes executed by the callee.
+// Gas not consumed by opcodes (intrinsic gas, precompiles, code deposit), failed opcodes and system calls are not attributed, refunds are not deducted.
+type GasCategoryTracer struct {
+	gas map[OpcodeCategory]uint64
+
+	// Depth of the call opcode whose callee is about to be entered (zero if none)
+	callDepth  int
+	systemCall bool
+}
+
+// NewGasCategoryTracer creates a new gas category tracer
+func NewGasCategoryTracer() *GasCategoryTracer {
+	return &GasCategoryTracer{gas: make(map[OpcodeCategory]uint64)}
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not attributed
+func (t *GasCategoryTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *GasCategoryTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnOpcode attributes the opcode cost to its category
+func (t *GasCategoryTracer) OnOpcode(_ uint64, op byte, _, cost uint64, _ tracing.OpContext, _ []byte, depth int, err error) {
+	if t.systemCall || err != nil {
+		return
+	}
+	t.gas[CategoryOf(vm.OpCode(op))] += cost
+
+	// The cost of message calls includes the gas forwarded to the callee
+	switch vm.OpCode(op) {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.callDepth = depth
+	default:
+		t.callDepth = 0
+	}
+}
+
+// OnEnter deducts the gas forwarded to the callee from the call opcode cost
+func (t *GasCategoryTracer) OnEnter(depth int, typ byte, _, _ gethcommon.Address, _ []byte, gas uint64, value *big.Int) {
+	if t.systemCall || t.callDepth == 0 || depth != t.callDepth {
+		return
+	}
+	t.callDepth = 0
+
+	// The call stipend of value transfers is granted to the callee on top of the forwarded gas, at no cost to the caller
+	forwarded := gas
+	if op := vm.OpCode(typ); (op == vm.CALL || op == vm.CALLCODE) && value != nil && value.Sign() > 0 && forwarded >= params.CallStipend {
+		forwarded -= params.CallStipend
+	}
+	if forwarded > t.gas[OpcodeCategoryCall] {
+		forwarded = t.gas[OpcodeCategoryCall]
+	}
+	t.gas[OpcodeCategoryCall] -= forwarded
+}
+
+// GasByCategory returns the gas consumed per opcode category across executed transactions
+func (t *GasCategoryTracer) GasByCategory() map[OpcodeCategory]uint64 {
+	gas := make(map[OpcodeCategory]uint64, len(t.gas))
+	for category, used := range t.gas {
+		gas[category] = used
+	}
+	return gas
+}
+
+// Hooks returns the gas category tracer hooks
+func (t *GasCategoryTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnOpcode:          t.OnOpcode,
+		OnEnter:           t.OnEnter,
+	}
+}
@@ -0,0 +1,95 @@
+package evm
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasPoolTx is the block gas pool accounting of a transaction
+type GasPoolTx struct {
+	TxIndex           int             `json:"txIndex"`
+	TxHash            gethcommon.Hash `json:"txHash"`
+	GasUsed           uint64          `json:"gasUsed"`
+	CumulativeGasUsed uint64          `json:"cumulativeGasUsed"`
+	GasPool           uint64          `json:"gasPool"` // Gas left in the block gas pool after the transaction
+}
+
+// GasPoolReport reports the depletion of the block gas pool by the block transactions
+type GasPoolReport struct {
+	GasLimit uint64       `json:"gasLimit"`
+	Txs      []*GasPoolTx `json:"txs"`
+	GasPool  uint64       `json:"gasPool"` // Gas left in the block gas pool after the last transaction
+
+	// Inconsistencies are the accounting violations observed (e.g. cumulative gas used exceeding the block gas limit)
+	Inconsistencies []string `json:"inconsistencies,omitempty"`
+}
+
+// GasPoolTracer is an EVM tracer that tracks the block gas pool depletion per transaction
+// It recomputes the pool from the transactions receipts and flags receipts inconsistent with it, rather than failing the execution.
+type GasPoolTracer struct {
+	report     *GasPoolReport
+	cumulative uint64 // Gas used by the transactions executed so far
+	txIndex    int
+	txHash     gethcommon.Hash
+}
+
+// NewGasPoolTracer creates a new gas pool tracer
+func NewGasPoolTracer() *GasPoolTracer {
+	return &GasPoolTracer{report: new(GasPoolReport), txIndex: -1}
+}
+
+// OnBlockStart fills the gas pool with the block gas limit
+func (t *GasPoolTracer) OnBlockStart(event tracing.BlockEvent) {
+	t.report.GasLimit = event.Block.GasLimit()
+	t.report.GasPool = t.report.GasLimit
+}
+
+// OnTxStart records the executed transaction
+func (t *GasPoolTracer) OnTxStart(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+	t.txHash = tx.Hash()
+}
+
+// OnTxEnd deducts the gas used by the transaction from the gas pool
+func (t *GasPoolTracer) OnTxEnd(receipt *gethtypes.Receipt, _ error) {
+	if receipt == nil {
+		return
+	}
+
+	r := t.report
+	t.cumulative += receipt.GasUsed
+	if receipt.CumulativeGasUsed != t.cumulative {
+		r.Inconsistencies = append(r.Inconsistencies, fmt.Sprintf("transaction %d (%v) has cumulative gas used %d, expected %d", t.txIndex, t.txHash.Hex(), receipt.CumulativeGasUsed, t.cumulative))
+	}
+	if t.cumulative > r.GasLimit {
+		r.Inconsistencies = append(r.Inconsistencies, fmt.Sprintf("transaction %d (%v) depletes the gas pool: cumulative gas used %d exceeds the block gas limit %d", t.txIndex, t.txHash.Hex(), t.cumulative, r.GasLimit))
+		r.GasPool = 0
+	} else {
+		r.GasPool = r.GasLimit - t.cumulative
+	}
+
+	r.Txs = append(r.Txs, &GasPoolTx{
+		TxIndex:           t.txIndex,
+		TxHash:            t.txHash,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		GasPool:           r.GasPool,
+	})
+}
+
+// Report returns the gas pool report of the executed transactions
+func (t *GasPoolTracer) Report() *GasPoolReport {
+	return t.report
+}
+
+// Hooks returns the gas pool tracer hooks
+func (t *GasPoolTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnBlockStart: t.OnBlockStart,
+		OnTxStart:    t.OnTxStart,
+		OnTxEnd:      t.OnTxEnd,
+	}
+}
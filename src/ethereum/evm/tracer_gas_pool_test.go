@@ -0,0 +1,36 @@
+package evm
+
+import (
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasPoolTracer(t *testing.T) {
+	tracer := NewGasPoolTracer()
+	hooks := tracer.Hooks()
+	hooks.OnBlockStart(tracing.BlockEvent{Block: gethtypes.NewBlockWithHeader(&gethtypes.Header{GasLimit: 50_000})})
+
+	for _, receipt := range []*gethtypes.Receipt{
+		{GasUsed: 21_000, CumulativeGasUsed: 21_000},
+		{GasUsed: 21_000, CumulativeGasUsed: 40_000}, // Inconsistent cumulative gas used
+		{GasUsed: 21_000, CumulativeGasUsed: 63_000}, // Exceeds the block gas limit
+	} {
+		hooks.OnTxStart(nil, gethtypes.NewTx(&gethtypes.LegacyTx{}), gethcommon.Address{})
+		hooks.OnTxEnd(receipt, nil)
+	}
+
+	report := tracer.Report()
+	require.Len(t, report.Txs, 3)
+	assert.Equal(t, uint64(29_000), report.Txs[0].GasPool)
+	assert.Equal(t, uint64(8_000), report.Txs[1].GasPool)
+	assert.Equal(t, uint64(0), report.GasPool)
+	require.Len(t, report.Inconsistencies, 2)
+	assert.Contains(t, report.Inconsistencies[0], "transaction 1")
+	assert.Contains(t, report.Inconsistencies[0], "cumulative gas used 40000, expected 42000")
+	assert.Contains(t, report.Inconsistencies[1], "cumulative gas used 63000 exceeds the block gas limit 50000")
+}
@@ -0,0 +1,60 @@
+package evm
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasTargetReached reports the transaction whose execution made the cumulative gas used by the block transactions reach the gas target
+type GasTargetReached struct {
+	TxIndex           int
+	TxHash            gethcommon.Hash
+	CumulativeGasUsed uint64
+	Target            uint64
+}
+
+func (e *GasTargetReached) Error() string {
+	return fmt.Sprintf("gas target of %d reached by transaction %d (%v) with cumulative gas used %d", e.Target, e.TxIndex, e.TxHash.Hex(), e.CumulativeGasUsed)
+}
+
+// GasTargetTracer is an EVM tracer that stops the execution once the cumulative gas used by the block transactions reaches the target
+// The transaction reaching the target completes, the following transactions, withdrawals and end of block system calls are not executed.
+//
+// The execution is stopped by panicking with a *GasTargetReached, which is recovered by the executor and returned as the execution error.
+// The tracer must be muxed last, so the other tracers observe the end of the transaction reaching the target.
+type GasTargetTracer struct {
+	target uint64
+
+	txIndex int
+	txHash  gethcommon.Hash
+}
+
+// NewGasTargetTracer creates a new gas target tracer
+func NewGasTargetTracer(target uint64) *GasTargetTracer {
+	return &GasTargetTracer{target: target, txIndex: -1}
+}
+
+// OnTxStart records the executed transaction
+func (t *GasTargetTracer) OnTxStart(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+	t.txHash = tx.Hash()
+}
+
+// OnTxEnd stops the execution if the cumulative gas used reached the target
+func (t *GasTargetTracer) OnTxEnd(receipt *gethtypes.Receipt, _ error) {
+	if receipt == nil || receipt.CumulativeGasUsed < t.target {
+		return
+	}
+	panic(&GasTargetReached{TxIndex: t.txIndex, TxHash: t.txHash, CumulativeGasUsed: receipt.CumulativeGasUsed, Target: t.target})
+}
+
+// Hooks returns the gas target tracer hooks
+func (t *GasTargetTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: t.OnTxStart,
+		OnTxEnd:   t.OnTxEnd,
+	}
+}
@@ -0,0 +1,259 @@
+package evm
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// MuxHooks combines multiple tracing hooks into a single one
+// Each event is dispatched to every hook in the order they are provided
+// nil hooks are ignored, and a combined hook is only set if at least one of the hooks defines it
+// (so the interpreter does not pay for e.g. per-opcode tracing if no hook traces opcodes)
+func MuxHooks(hooks ...*tracing.Hooks) *tracing.Hooks {
+	hs := make([]*tracing.Hooks, 0, len(hooks))
+	for _, h := range hooks {
+		if h != nil {
+			hs = append(hs, h)
+		}
+	}
+
+	switch len(hs) {
+	case 0:
+		return nil
+	case 1:
+		return hs[0]
+	}
+
+	var (
+		onTxStart         []tracing.TxStartHook
+		onTxEnd           []tracing.TxEndHook
+		onEnter           []tracing.EnterHook
+		onExit            []tracing.ExitHook
+		onOpcode          []tracing.OpcodeHook
+		onFault           []tracing.FaultHook
+		onGasChange       []tracing.GasChangeHook
+		onBlockchainInit  []tracing.BlockchainInitHook
+		onClose           []tracing.CloseHook
+		onBlockStart      []tracing.BlockStartHook
+		onBlockEnd        []tracing.BlockEndHook
+		onSkippedBlock    []tracing.SkippedBlockHook
+		onGenesisBlock    []tracing.GenesisBlockHook
+		onSystemCallStart []tracing.OnSystemCallStartHook
+		onSystemCallEnd   []tracing.OnSystemCallEndHook
+		onBalanceChange   []tracing.BalanceChangeHook
+		onNonceChange     []tracing.NonceChangeHook
+		onCodeChange      []tracing.CodeChangeHook
+		onStorageChange   []tracing.StorageChangeHook
+		onLog             []tracing.LogHook
+	)
+	for _, h := range hs {
+		if h.OnTxStart != nil {
+			onTxStart = append(onTxStart, h.OnTxStart)
+		}
+		if h.OnTxEnd != nil {
+			onTxEnd = append(onTxEnd, h.OnTxEnd)
+		}
+		if h.OnEnter != nil {
+			onEnter = append(onEnter, h.OnEnter)
+		}
+		if h.OnExit != nil {
+			onExit = append(onExit, h.OnExit)
+		}
+		if h.OnOpcode != nil {
+			onOpcode = append(onOpcode, h.OnOpcode)
+		}
+		if h.OnFault != nil {
+			onFault = append(onFault, h.OnFault)
+		}
+		if h.OnGasChange != nil {
+			onGasChange = append(onGasChange, h.OnGasChange)
+		}
+		if h.OnBlockchainInit != nil {
+			onBlockchainInit = append(onBlockchainInit, h.OnBlockchainInit)
+		}
+		if h.OnClose != nil {
+			onClose = append(onClose, h.OnClose)
+		}
+		if h.OnBlockStart != nil {
+			onBlockStart = append(onBlockStart, h.OnBlockStart)
+		}
+		if h.OnBlockEnd != nil {
+			onBlockEnd = append(onBlockEnd, h.OnBlockEnd)
+		}
+		if h.OnSkippedBlock != nil {
+			onSkippedBlock = append(onSkippedBlock, h.OnSkippedBlock)
+		}
+		if h.OnGenesisBlock != nil {
+			onGenesisBlock = append(onGenesisBlock, h.OnGenesisBlock)
+		}
+		if h.OnSystemCallStart != nil {
+			onSystemCallStart = append(onSystemCallStart, h.OnSystemCallStart)
+		}
+		if h.OnSystemCallEnd != nil {
+			onSystemCallEnd = append(onSystemCallEnd, h.OnSystemCallEnd)
+		}
+		if h.OnBalanceChange != nil {
+			onBalanceChange = append(onBalanceChange, h.OnBalanceChange)
+		}
+		if h.OnNonceChange != nil {
+			onNonceChange = append(onNonceChange, h.OnNonceChange)
+		}
+		if h.OnCodeChange != nil {
+			onCodeChange = append(onCodeChange, h.OnCodeChange)
+		}
+		if h.OnStorageChange != nil {
+			onStorageChange = append(onStorageChange, h.OnStorageChange)
+		}
+		if h.OnLog != nil {
+			onLog = append(onLog, h.OnLog)
+		}
+	}
+
+	mux := new(tracing.Hooks)
+	if len(onTxStart) > 0 {
+		mux.OnTxStart = func(vm *tracing.VMContext, tx *gethtypes.Transaction, from gethcommon.Address) {
+			for _, fn := range onTxStart {
+				fn(vm, tx, from)
+			}
+		}
+	}
+	if len(onTxEnd) > 0 {
+		mux.OnTxEnd = func(receipt *gethtypes.Receipt, err error) {
+			for _, fn := range onTxEnd {
+				fn(receipt, err)
+			}
+		}
+	}
+	if len(onEnter) > 0 {
+		mux.OnEnter = func(depth int, typ byte, from, to gethcommon.Address, input []byte, gas uint64, value *big.Int) {
+			for _, fn := range onEnter {
+				fn(depth, typ, from, to, input, gas, value)
+			}
+		}
+	}
+	if len(onExit) > 0 {
+		mux.OnExit = func(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+			for _, fn := range onExit {
+				fn(depth, output, gasUsed, err, reverted)
+			}
+		}
+	}
+	if len(onOpcode) > 0 {
+		mux.OnOpcode = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+			for _, fn := range onOpcode {
+				fn(pc, op, gas, cost, scope, rData, depth, err)
+			}
+		}
+	}
+	if len(onFault) > 0 {
+		mux.OnFault = func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, depth int, err error) {
+			for _, fn := range onFault {
+				fn(pc, op, gas, cost, scope, depth, err)
+			}
+		}
+	}
+	if len(onGasChange) > 0 {
+		mux.OnGasChange = func(old, new uint64, reason tracing.GasChangeReason) {
+			for _, fn := range onGasChange {
+				fn(old, new, reason)
+			}
+		}
+	}
+	if len(onBlockchainInit) > 0 {
+		mux.OnBlockchainInit = func(chainConfig *params.ChainConfig) {
+			for _, fn := range onBlockchainInit {
+				fn(chainConfig)
+			}
+		}
+	}
+	if len(onClose) > 0 {
+		mux.OnClose = func() {
+			for _, fn := range onClose {
+				fn()
+			}
+		}
+	}
+	if len(onBlockStart) > 0 {
+		mux.OnBlockStart = func(event tracing.BlockEvent) {
+			for _, fn := range onBlockStart {
+				fn(event)
+			}
+		}
+	}
+	if len(onBlockEnd) > 0 {
+		mux.OnBlockEnd = func(err error) {
+			for _, fn := range onBlockEnd {
+				fn(err)
+			}
+		}
+	}
+	if len(onSkippedBlock) > 0 {
+		mux.OnSkippedBlock = func(event tracing.BlockEvent) {
+			for _, fn := range onSkippedBlock {
+				fn(event)
+			}
+		}
+	}
+	if len(onGenesisBlock) > 0 {
+		mux.OnGenesisBlock = func(genesis *gethtypes.Block, alloc gethtypes.GenesisAlloc) {
+			for _, fn := range onGenesisBlock {
+				fn(genesis, alloc)
+			}
+		}
+	}
+	if len(onSystemCallStart) > 0 {
+		mux.OnSystemCallStart = func() {
+			for _, fn := range onSystemCallStart {
+				fn()
+			}
+		}
+	}
+	if len(onSystemCallEnd) > 0 {
+		mux.OnSystemCallEnd = func() {
+			for _, fn := range onSystemCallEnd {
+				fn()
+			}
+		}
+	}
+	if len(onBalanceChange) > 0 {
+		mux.OnBalanceChange = func(addr gethcommon.Address, prev, new *big.Int, reason tracing.BalanceChangeReason) {
+			for _, fn := range onBalanceChange {
+				fn(addr, prev, new, reason)
+			}
+		}
+	}
+	if len(onNonceChange) > 0 {
+		mux.OnNonceChange = func(addr gethcommon.Address, prev, new uint64) {
+			for _, fn := range onNonceChange {
+				fn(addr, prev, new)
+			}
+		}
+	}
+	if len(onCodeChange) > 0 {
+		mux.OnCodeChange = func(addr gethcommon.Address, prevCodeHash gethcommon.Hash, prevCode []byte, codeHash gethcommon.Hash, code []byte) {
+			for _, fn := range onCodeChange {
+				fn(addr, prevCodeHash, prevCode, codeHash, code)
+			}
+		}
+	}
+	if len(onStorageChange) > 0 {
+		mux.OnStorageChange = func(addr gethcommon.Address, slot, prev, new gethcommon.Hash) {
+			for _, fn := range onStorageChange {
+				fn(addr, slot, prev, new)
+			}
+		}
+	}
+	if len(onLog) > 0 {
+		mux.OnLog = func(log *gethtypes.Log) {
+			for _, fn := range onLog {
+				fn(log)
+			}
+		}
+	}
+
+	return mux
+}
@@ -0,0 +1,38 @@
+package evm
+
+import (
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMuxHooks(t *testing.T) {
+	var calls []string
+	a := &tracing.Hooks{
+		OnTxStart: func(*tracing.VMContext, *gethtypes.Transaction, gethcommon.Address) {
+			calls = append(calls, "a.OnTxStart")
+		},
+	}
+	b := &tracing.Hooks{
+		OnTxStart: func(*tracing.VMContext, *gethtypes.Transaction, gethcommon.Address) {
+			calls = append(calls, "b.OnTxStart")
+		},
+		OnTxEnd: func(*gethtypes.Receipt, error) { calls = append(calls, "b.OnTxEnd") },
+	}
+
+	assert.Nil(t, MuxHooks(nil, nil))
+	assert.Same(t, a, MuxHooks(nil, a))
+
+	mux := MuxHooks(a, nil, b)
+	// Hooks that no child defines are left unset, so the interpreter skips them
+	assert.Nil(t, mux.OnOpcode)
+	assert.Nil(t, mux.OnEnter)
+	assert.Nil(t, mux.OnStorageChange)
+
+	mux.OnTxStart(nil, gethtypes.NewTx(&gethtypes.LegacyTx{}), gethcommon.Address{})
+	mux.OnTxEnd(&gethtypes.Receipt{}, nil)
+	assert.Equal(t, []string{"a.OnTxStart", "b.OnTxStart", "b.OnTxEnd"}, calls)
+}
@@ -0,0 +1,35 @@
+package evm
+
+import (
+	"github.com/ethereum/go-ethereum/core/tracing"
+)
+
+// RefundTracer is an EVM tracer that tallies the gas refunded to transactions senders
+// The tallied refunds are the ones effectively applied, i.e. after the refund cap (EIP-3529 post-London)
+type RefundTracer struct {
+	refunded uint64
+}
+
+// NewRefundTracer creates a new refund tracer
+func NewRefundTracer() *RefundTracer {
+	return &RefundTracer{}
+}
+
+// OnGasChange tallies gas refunds
+func (t *RefundTracer) OnGasChange(old, new uint64, reason tracing.GasChangeReason) {
+	if reason == tracing.GasChangeTxRefunds && new > old {
+		t.refunded += new - old
+	}
+}
+
+// Refunded returns the total gas refunded across executed transactions
+func (t *RefundTracer) Refunded() uint64 {
+	return t.refunded
+}
+
+// Hooks returns the refund tracer hooks
+func (t *RefundTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnGasChange: t.OnGasChange,
+	}
+}
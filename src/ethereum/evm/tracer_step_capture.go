@@ -0,0 +1,127 @@
+package evm
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// StepCapture is the EVM state captured right before a transaction executes an opcode step
+type StepCapture struct {
+	TxIndex int                `json:"txIndex"`
+	Step    uint64             `json:"step"`
+	PC      uint64             `json:"pc"`
+	Op      string             `json:"op"`
+	Depth   int                `json:"depth"`
+	Gas     uint64             `json:"gas"`
+	Address gethcommon.Address `json:"address"` // Address of the executing contract
+	Stack   []uint256.Int      `json:"stack"`   // Stack from bottom to top
+	Memory  hexutil.Bytes      `json:"memory"`
+
+	// Storage slots of the executing contract accessed by the transaction so far, with their values at the step
+	Storage map[gethcommon.Hash]gethcommon.Hash `json:"storage"`
+}
+
+// StepCaptureTracer is an EVM tracer that captures the memory, stack and storage of a transaction at a given step
+// Steps are the opcodes executed by the transaction, including internal calls, indexed from 0.
+type StepCaptureTracer struct {
+	txIndex int
+	step    uint64
+
+	state      tracing.StateDB
+	currentTx  int
+	steps      uint64
+	systemCall bool
+	accessed   map[gethcommon.Address]map[gethcommon.Hash]struct{}
+	capture    *StepCapture
+}
+
+// NewStepCaptureTracer creates a new step capture tracer capturing the given step of the transaction at the given index
+func NewStepCaptureTracer(txIndex int, step uint64) *StepCaptureTracer {
+	return &StepCaptureTracer{txIndex: txIndex, step: step, currentTx: -1}
+}
+
+// OnTxStart resets the step counter
+func (t *StepCaptureTracer) OnTxStart(vmCtx *tracing.VMContext, _ *gethtypes.Transaction, _ gethcommon.Address) {
+	t.currentTx++
+	t.state = vmCtx.StateDB
+	t.steps = 0
+	t.accessed = make(map[gethcommon.Address]map[gethcommon.Hash]struct{})
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not captured
+func (t *StepCaptureTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *StepCaptureTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnOpcode captures the EVM state at the target step
+func (t *StepCaptureTracer) OnOpcode(pc uint64, op byte, gas, _ uint64, scope tracing.OpContext, _ []byte, depth int, _ error) {
+	if t.systemCall || t.currentTx != t.txIndex || t.capture != nil {
+		return
+	}
+
+	stack := scope.StackData()
+	if t.steps == t.step {
+		t.capture = &StepCapture{
+			TxIndex: t.txIndex,
+			Step:    t.step,
+			PC:      pc,
+			Op:      vm.OpCode(op).String(),
+			Depth:   depth,
+			Gas:     gas,
+			Address: scope.Address(),
+			Stack:   append([]uint256.Int(nil), stack...),
+			Memory:  gethcommon.CopyBytes(scope.MemoryData()),
+			Storage: make(map[gethcommon.Hash]gethcommon.Hash),
+		}
+		for key := range t.accessed[scope.Address()] {
+			t.capture.Storage[key] = t.state.GetState(scope.Address(), key)
+		}
+		return
+	}
+	t.steps++
+
+	switch vm.OpCode(op) {
+	case vm.SLOAD, vm.SSTORE:
+		if len(stack) > 0 {
+			slots, ok := t.accessed[scope.Address()]
+			if !ok {
+				slots = make(map[gethcommon.Hash]struct{})
+				t.accessed[scope.Address()] = slots
+			}
+			slots[gethcommon.Hash(stack[len(stack)-1].Bytes32())] = struct{}{}
+		}
+	}
+}
+
+// Capture returns the EVM state captured at the target step
+// It errors if the transaction was not executed or completed before reaching the step.
+func (t *StepCaptureTracer) Capture() (*StepCapture, error) {
+	if t.capture != nil {
+		return t.capture, nil
+	}
+	if t.currentTx < t.txIndex {
+		return nil, fmt.Errorf("step capture failed: transaction %d not executed (%d transaction(s) executed)", t.txIndex, t.currentTx+1)
+	}
+	return nil, fmt.Errorf("step capture failed: step %d beyond the execution of transaction %d (%d step(s) executed)", t.step, t.txIndex, t.steps)
+}
+
+// Hooks returns the step capture tracer hooks
+func (t *StepCaptureTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:         t.OnTxStart,
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnOpcode:          t.OnOpcode,
+	}
+}
@@ -0,0 +1,84 @@
+package evm
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// StepLimitError is the error of a transaction executing more opcode steps than allowed
+type StepLimitError struct {
+	TxIndex int
+	TxHash  gethcommon.Hash
+	Steps   uint64
+	Limit   uint64
+}
+
+func (e *StepLimitError) Error() string {
+	return fmt.Sprintf("transaction %d (%v) exceeded the step limit of %d (aborted at step %d)", e.TxIndex, e.TxHash.Hex(), e.Limit, e.Steps)
+}
+
+// StepLimitTracer is an EVM tracer that aborts the execution when a transaction executes more opcode steps than the limit (including internal calls)
+// It is a defense against pathological contracts, independent from gas
+//
+// The execution is aborted by panicking with a *StepLimitError, which is recovered by the executor and returned as the execution error
+type StepLimitTracer struct {
+	limit uint64
+
+	txIndex    int
+	txHash     gethcommon.Hash
+	steps      uint64
+	systemCall bool
+	exceeded   *StepLimitError
+}
+
+// NewStepLimitTracer creates a new step limit tracer with the given per-transaction limit
+func NewStepLimitTracer(limit uint64) *StepLimitTracer {
+	return &StepLimitTracer{limit: limit, txIndex: -1}
+}
+
+// OnTxStart resets the step counter
+func (t *StepLimitTracer) OnTxStart(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+	t.txIndex++
+	t.txHash = tx.Hash()
+	t.steps = 0
+}
+
+// OnSystemCallStart marks the start of a system call, system calls are not limited
+func (t *StepLimitTracer) OnSystemCallStart() {
+	t.systemCall = true
+}
+
+// OnSystemCallEnd marks the end of a system call
+func (t *StepLimitTracer) OnSystemCallEnd() {
+	t.systemCall = false
+}
+
+// OnOpcode counts the step and aborts the execution if the limit is exceeded
+func (t *StepLimitTracer) OnOpcode(_ uint64, _ byte, _, _ uint64, _ tracing.OpContext, _ []byte, _ int, _ error) {
+	if t.systemCall || t.txIndex < 0 {
+		return
+	}
+	t.steps++
+	if t.steps > t.limit {
+		t.exceeded = &StepLimitError{TxIndex: t.txIndex, TxHash: t.txHash, Steps: t.steps, Limit: t.limit}
+		panic(t.exceeded)
+	}
+}
+
+// Exceeded returns the step limit error of the aborted transaction, if any
+func (t *StepLimitTracer) Exceeded() *StepLimitError {
+	return t.exceeded
+}
+
+// Hooks returns the step limit tracer hooks
+func (t *StepLimitTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart:         t.OnTxStart,
+		OnSystemCallStart: t.OnSystemCallStart,
+		OnSystemCallEnd:   t.OnSystemCallEnd,
+		OnOpcode:          t.OnOpcode,
+	}
+}
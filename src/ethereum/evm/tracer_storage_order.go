@@ -0,0 +1,70 @@
+package evm
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// StorageAccessOrder holds the storage slots of an account in the order they were first accessed
+type StorageAccessOrder struct {
+	Address gethcommon.Address `json:"address"`
+	Slots   []gethcommon.Hash  `json:"slots"`
+}
+
+// StorageOrderTracer is an EVM tracer that records the storage slots accessed by SLOAD and SSTORE in first-access order across the block
+// System calls (e.g. EIP-4788 beacon root storage) are recorded as well, accounts are ordered by the first access to any of their slots.
+type StorageOrderTracer struct {
+	accounts []*StorageAccessOrder
+	index    map[gethcommon.Address]int
+	seen     map[gethcommon.Address]map[gethcommon.Hash]struct{}
+}
+
+// NewStorageOrderTracer creates a new storage order tracer
+func NewStorageOrderTracer() *StorageOrderTracer {
+	return &StorageOrderTracer{
+		index: make(map[gethcommon.Address]int),
+		seen:  make(map[gethcommon.Address]map[gethcommon.Hash]struct{}),
+	}
+}
+
+// OnOpcode records the slot accessed by SLOAD and SSTORE opcodes
+func (t *StorageOrderTracer) OnOpcode(_ uint64, op byte, _, _ uint64, scope tracing.OpContext, _ []byte, _ int, err error) {
+	if err != nil {
+		return
+	}
+	if opcode := vm.OpCode(op); opcode != vm.SLOAD && opcode != vm.SSTORE {
+		return
+	}
+	stack := scope.StackData()
+	if len(stack) == 0 {
+		return
+	}
+
+	addr, slot := scope.Address(), gethcommon.Hash(stack[len(stack)-1].Bytes32())
+	slots, ok := t.seen[addr]
+	if !ok {
+		slots = make(map[gethcommon.Hash]struct{})
+		t.seen[addr] = slots
+		t.index[addr] = len(t.accounts)
+		t.accounts = append(t.accounts, &StorageAccessOrder{Address: addr})
+	}
+	if _, ok := slots[slot]; ok {
+		return
+	}
+	slots[slot] = struct{}{}
+	account := t.accounts[t.index[addr]]
+	account.Slots = append(account.Slots, slot)
+}
+
+// StorageAccessOrder returns the accessed storage slots per account, in first-access order
+func (t *StorageOrderTracer) StorageAccessOrder() []*StorageAccessOrder {
+	return t.accounts
+}
+
+// Hooks returns the storage order tracer hooks
+func (t *StorageOrderTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnOpcode: t.OnOpcode,
+	}
+}
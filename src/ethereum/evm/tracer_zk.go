@@ -0,0 +1,179 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// ZKStep is an opcode execution step of a zk trace
+type ZKStep struct {
+	PC      uint64 `json:"pc"`
+	Op      string `json:"op"`
+	Depth   int    `json:"depth"`
+	Gas     uint64 `json:"gas"`
+	GasCost uint64 `json:"gasCost"`
+
+	// Access flags
+	StackAccess  bool `json:"stackAccess"`
+	MemoryAccess bool `json:"memoryAccess"`
+	StorageRead  bool `json:"storageRead"`
+	StorageWrite bool `json:"storageWrite"`
+
+	// StorageKey is the storage slot accessed by SLOAD and SSTORE steps
+	StorageKey *gethcommon.Hash `json:"storageKey,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// ZKTxTrace is the zk trace of a transaction, consisting in the ordered opcode steps executed by the transaction
+type ZKTxTrace struct {
+	TxIndex int             `json:"txIndex"`
+	TxHash  gethcommon.Hash `json:"txHash"`
+	Steps   []*ZKStep       `json:"steps"`
+}
+
+// ZKTraceWriter persists transaction zk traces
+type ZKTraceWriter interface {
+	// WriteTxTrace persists the zk trace of a transaction
+	WriteTxTrace(trace *ZKTxTrace) error
+}
+
+// ZKTracer is an EVM tracer that emits zk traces of executed transactions
+type ZKTracer struct {
+	writer ZKTraceWriter
+
+	traces  []*ZKTxTrace
+	current *ZKTxTrace
+	err     error
+}
+
+// NewZKTracer creates a new zk tracer
+// If writer is not nil, every transaction trace is persisted with the writer once the transaction is executed
+func NewZKTracer(writer ZKTraceWriter) *ZKTracer {
+	return &ZKTracer{writer: writer}
+}
+
+// OnTxStart starts a new transaction trace
+func (t *ZKTracer) OnTxStart(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+	t.current = &ZKTxTrace{
+		TxIndex: len(t.traces),
+		TxHash:  tx.Hash(),
+		Steps:   make([]*ZKStep, 0),
+	}
+}
+
+// OnTxEnd ends the current transaction trace and persists it
+func (t *ZKTracer) OnTxEnd(_ *gethtypes.Receipt, _ error) {
+	if t.current == nil {
+		return
+	}
+	t.traces = append(t.traces, t.current)
+	if t.writer != nil && t.err == nil {
+		if err := t.writer.WriteTxTrace(t.current); err != nil {
+			t.err = fmt.Errorf("failed to write trace of tx %v: %v", t.current.TxHash.Hex(), err)
+		}
+	}
+	t.current = nil
+}
+
+// OnOpcode records an opcode step
+func (t *ZKTracer) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, _ []byte, depth int, err error) {
+	if t.current == nil {
+		return
+	}
+
+	opCode := vm.OpCode(op)
+	step := &ZKStep{
+		PC:           pc,
+		Op:           opCode.String(),
+		Depth:        depth,
+		Gas:          gas,
+		GasCost:      cost,
+		StackAccess:  accessesStack(opCode),
+		MemoryAccess: accessesMemory(opCode),
+		StorageRead:  opCode == vm.SLOAD,
+		StorageWrite: opCode == vm.SSTORE,
+	}
+	if opCode == vm.SLOAD || opCode == vm.SSTORE {
+		if stack := scope.StackData(); len(stack) > 0 {
+			key := gethcommon.Hash(stack[len(stack)-1].Bytes32())
+			step.StorageKey = &key
+		}
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+
+	t.current.Steps = append(t.current.Steps, step)
+}
+
+// Traces returns the traces of every executed transaction
+func (t *ZKTracer) Traces() []*ZKTxTrace {
+	return t.traces
+}
+
+// Err returns the first error that occurred while persisting traces
+func (t *ZKTracer) Err() error {
+	return t.err
+}
+
+// Hooks returns the zk tracer hooks
+func (t *ZKTracer) Hooks() *tracing.Hooks {
+	return &tracing.Hooks{
+		OnTxStart: t.OnTxStart,
+		OnTxEnd:   t.OnTxEnd,
+		OnOpcode:  t.OnOpcode,
+	}
+}
+
+func accessesStack(op vm.OpCode) bool {
+	switch op {
+	case vm.STOP, vm.JUMPDEST, vm.INVALID:
+		return false
+	default:
+		return true
+	}
+}
+
+func accessesMemory(op vm.OpCode) bool {
+	switch op {
+	case vm.MLOAD, vm.MSTORE, vm.MSTORE8, vm.MCOPY, vm.KECCAK256,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY,
+		vm.LOG0, vm.LOG1, vm.LOG2, vm.LOG3, vm.LOG4,
+		vm.CREATE, vm.CREATE2, vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL,
+		vm.RETURN, vm.REVERT:
+		return true
+	default:
+		return false
+	}
+}
+
+type zkTraceDirWriter struct {
+	dir string
+}
+
+// NewZKTraceDirWriter creates a writer persisting each transaction zk trace as a JSON file in the given directory
+// Files are named after the transaction index and hash (e.g. tx_0_0x1234...json)
+func NewZKTraceDirWriter(dir string) ZKTraceWriter {
+	return &zkTraceDirWriter{dir: dir}
+}
+
+func (w *zkTraceDirWriter) WriteTxTrace(trace *ZKTxTrace) error {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(w.dir, fmt.Sprintf("tx_%d_%s.json", trace.TxIndex, trace.TxHash.Hex())), b, 0o644)
+}
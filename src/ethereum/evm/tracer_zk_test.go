@@ -0,0 +1,57 @@
+package evm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZKTracer(t *testing.T) {
+	// PUSH1 0x2a PUSH1 0x01 SSTORE PUSH1 0x01 SLOAD PUSH1 0x00 MSTORE STOP
+	code := []byte{
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x01, byte(vm.SLOAD),
+		byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+		byte(vm.STOP),
+	}
+
+	dir := t.TempDir()
+	tracer := NewZKTracer(NewZKTraceDirWriter(dir))
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{
+		ChainConfig: params.MergedTestChainConfig,
+		EVMConfig:   vm.Config{Tracer: tracer.Hooks()},
+	})
+	require.NoError(t, err)
+	require.NoError(t, tracer.Err())
+
+	require.Len(t, tracer.Traces(), 1)
+	trace := tracer.Traces()[0]
+
+	var ops []string
+	for _, step := range trace.Steps {
+		ops = append(ops, step.Op)
+	}
+	assert.Equal(t, []string{"PUSH1", "PUSH1", "SSTORE", "PUSH1", "SLOAD", "PUSH1", "MSTORE", "STOP"}, ops)
+
+	slot := gethcommon.BigToHash(gethcommon.Big1)
+	assert.Equal(t, &ZKStep{PC: 4, Op: "SSTORE", Depth: 1, Gas: trace.Steps[2].Gas, GasCost: trace.Steps[2].GasCost, StackAccess: true, StorageWrite: true, StorageKey: &slot}, trace.Steps[2])
+	assert.True(t, trace.Steps[4].StorageRead)
+	assert.Equal(t, &slot, trace.Steps[4].StorageKey)
+	assert.True(t, trace.Steps[6].MemoryAccess)
+	assert.False(t, trace.Steps[7].StackAccess)
+
+	// Trace is persisted per transaction
+	b, err := os.ReadFile(filepath.Join(dir, "tx_0_"+trace.TxHash.Hex()+".json"))
+	require.NoError(t, err)
+	var persisted ZKTxTrace
+	require.NoError(t, json.Unmarshal(b, &persisted))
+	assert.Equal(t, trace, &persisted)
+}
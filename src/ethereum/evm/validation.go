@@ -0,0 +1,122 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+)
+
+// ValidationCheck is a set of block validation checks, checks combine as a bitmask
+type ValidationCheck uint
+
+const (
+	CheckFeeCaps            ValidationCheck = 1 << iota // Transactions max fee per gas cover the block base fee
+	CheckBlobGas                                        // Blob gas fields of Cancun blocks follow the EIP-4844 update rule
+	CheckEffectiveGasPrices                             // Transactions are charged their effective gas price
+	CheckRequests                                       // EIP-7685 requests hash of Prague blocks
+	CheckGasUsed                                        // Block gas used
+	CheckBloom                                          // Block logs bloom
+	CheckReceiptsRoot                                   // Block receipts root
+	CheckStateRoot                                      // Block post-state root
+	CheckFeeRecipient                                   // Transaction fees are credited to the chain fee recipient (L2s)
+	CheckBeaconRoot                                     // Beacon roots contract storage holds the parent beacon root
+
+	// AllChecks selects every check
+	AllChecks = CheckFeeCaps | CheckBlobGas | CheckEffectiveGasPrices | CheckRequests | CheckGasUsed | CheckBloom | CheckReceiptsRoot | CheckStateRoot | CheckFeeRecipient | CheckBeaconRoot
+)
+
+var checkNames = []struct {
+	check ValidationCheck
+	name  string
+}{
+	{CheckFeeCaps, "feeCaps"},
+	{CheckBlobGas, "blobGas"},
+	{CheckEffectiveGasPrices, "effectiveGasPrices"},
+	{CheckRequests, "requests"},
+	{CheckGasUsed, "gasUsed"},
+	{CheckBloom, "bloom"},
+	{CheckReceiptsRoot, "receiptsRoot"},
+	{CheckStateRoot, "stateRoot"},
+	{CheckFeeRecipient, "feeRecipient"},
+	{CheckBeaconRoot, "beaconRoot"},
+}
+
+// Has returns whether the set includes every check of c
+func (checks ValidationCheck) Has(c ValidationCheck) bool {
+	return checks&c == c
+}
+
+// Names returns the names of the checks of the set, in the order they run
+func (checks ValidationCheck) Names() []string {
+	var names []string
+	for _, c := range checkNames {
+		if checks.Has(c.check) {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// ParseValidationChecks parses a comma separated list of check names (e.g. "gasUsed,stateRoot")
+func ParseValidationChecks(s string) (ValidationCheck, error) {
+	var checks ValidationCheck
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, c := range checkNames {
+			if c.name == name {
+				checks |= c.check
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("unknown validation check %q", name)
+		}
+	}
+	return checks, nil
+}
+
+// validateResult validates the block processing result and post-state against the block header, restricted to the given checks
+// It performs the checks of core.BlockValidator.ValidateState, with the same errors.
+func validateResult(params *ExecParams, res *core.ProcessResult, checks ValidationCheck) error {
+	if res == nil {
+		return errors.New("nil ProcessResult value")
+	}
+	header := params.Block.Header()
+	if checks.Has(CheckGasUsed) && header.GasUsed != res.GasUsed {
+		return fmt.Errorf("invalid gas used (remote: %d local: %d)", header.GasUsed, res.GasUsed)
+	}
+	if checks.Has(CheckBloom) {
+		if bloom := gethtypes.CreateBloom(res.Receipts); bloom != header.Bloom {
+			return fmt.Errorf("invalid bloom (remote: %x  local: %x)", header.Bloom, bloom)
+		}
+	}
+	if checks.Has(CheckReceiptsRoot) {
+		if receiptSha := gethtypes.DeriveSha(res.Receipts, gethtrie.NewStackTrie(nil)); receiptSha != header.ReceiptHash {
+			return fmt.Errorf("invalid receipt root hash (remote: %x local: %x)", header.ReceiptHash, receiptSha)
+		}
+	}
+	if checks.Has(CheckRequests) {
+		if header.RequestsHash != nil {
+			if reqHash := gethtypes.CalcRequestsHash(res.Requests); reqHash != *header.RequestsHash {
+				return fmt.Errorf("invalid requests hash (remote: %x local: %x)", *header.RequestsHash, reqHash)
+			}
+		} else if res.Requests != nil {
+			return errors.New("block has requests before prague fork")
+		}
+	}
+	if checks.Has(CheckStateRoot) {
+		if root := params.State.IntermediateRoot(params.Chain.Config().IsEIP158(header.Number)); header.Root != root {
+			return fmt.Errorf("invalid merkle root (remote: %x local: %x) dberr: %v", header.Root, root, params.State.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// BlockRewards overrides the mining rewards of PoW blocks
+type BlockRewards struct {
+	// BlockReward is the base reward credited to the block coinbase
+	BlockReward *uint256.Int
+
+	// UncleReward is the reward credited to the coinbase of each included uncle (optional)
+	// If nil, the standard uncle reward derived from the block reward is used, i.e. (uncle number + 8 - block number) * block reward / 8
+	UncleReward *uint256.Int
+}
+
+// EngineDecorator is a function that decorates a consensus engine.
+type EngineDecorator func(consensus.Engine) consensus.Engine
+
+// WithBlockRewards decorates a consensus engine so PoW blocks are finalized with the given rewards
+// PoS blocks are finalized by the decorated engine
+func WithBlockRewards(rewards *BlockRewards) EngineDecorator {
+	return func(engine consensus.Engine) consensus.Engine {
+		return &rewardsEngine{Engine: engine, rewards: rewards}
+	}
+}
+
+type rewardsEngine struct {
+	consensus.Engine
+
+	rewards *BlockRewards
+}
+
+// Finalize implements consensus.Engine
+func (e *rewardsEngine) Finalize(chain consensus.ChainHeaderReader, header *gethtypes.Header, state vm.StateDB, body *gethtypes.Body) {
+	if header.Difficulty == nil || header.Difficulty.Sign() == 0 {
+		e.Engine.Finalize(chain, header, state, body)
+		return
+	}
+	e.accumulateRewards(state, header, body.Uncles)
+}
+
+// accumulateRewards credits the block and uncles coinbases similarly to ethash but with the overridden rewards
+func (e *rewardsEngine) accumulateRewards(state vm.StateDB, header *gethtypes.Header, uncles []*gethtypes.Header) {
+	blockReward := e.rewards.BlockReward
+	if blockReward == nil {
+		blockReward = new(uint256.Int)
+	}
+
+	reward := new(uint256.Int).Set(blockReward)
+	hNum, _ := uint256.FromBig(header.Number)
+	for _, uncle := range uncles {
+		r := new(uint256.Int)
+		if e.rewards.UncleReward != nil {
+			r.Set(e.rewards.UncleReward)
+		} else {
+			uNum, _ := uint256.FromBig(uncle.Number)
+			r.AddUint64(uNum, 8)
+			r.Sub(r, hNum)
+			r.Mul(r, blockReward)
+			r.Rsh(r, 3)
+		}
+		state.AddBalance(uncle.Coinbase, r, tracing.BalanceIncreaseRewardMineUncle)
+
+		// Inclusion reward
+		reward.Add(reward, new(uint256.Int).Rsh(blockReward, 5))
+	}
+	state.AddBalance(header.Coinbase, reward, tracing.BalanceIncreaseRewardMineBlock)
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// StateRead is a read of an account or of a storage slot
+type StateRead struct {
+	StateRoot gethcommon.Hash         `json:"stateRoot"`
+	Address   gethcommon.Address      `json:"address"`
+	Account   *gethtypes.StateAccount `json:"account,omitempty"` // Account read, nil for storage reads and absent accounts
+	Slot      *gethcommon.Hash        `json:"slot,omitempty"`    // Slot read, nil for account reads
+	Value     *gethcommon.Hash        `json:"value,omitempty"`   // Value of the slot, nil for account reads
+}
+
+// ReadSink receives the state reads recorded by a RecorderDatabase
+type ReadSink interface {
+	// RecordRead records a state read, an error aborts the read
+	RecordRead(read *StateRead) error
+}
+
+// RecorderDatabase is a state database that records every account and storage read into a sink
+type RecorderDatabase struct {
+	gethstate.Database
+
+	sink ReadSink
+}
+
+// NewRecorderDatabase creates a new state database forwarding to the given database and recording reads into the sink
+func NewRecorderDatabase(db gethstate.Database, sink ReadSink) *RecorderDatabase {
+	return &RecorderDatabase{
+		Database: db,
+		sink:     sink,
+	}
+}
+
+// Reader implements the gethstate.Database interface.
+func (db *RecorderDatabase) Reader(stateRoot gethcommon.Hash) (gethstate.Reader, error) {
+	reader, err := db.Database.Reader(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &recorderReader{reader: reader, stateRoot: stateRoot, sink: db.sink}, nil
+}
+
+// recorderReader is a state reader that records the reads into a sink
+type recorderReader struct {
+	reader    gethstate.Reader
+	stateRoot gethcommon.Hash
+	sink      ReadSink
+}
+
+// Account implementing Reader interface, retrieving the account associated with
+// a particular address.
+func (r *recorderReader) Account(addr gethcommon.Address) (*gethtypes.StateAccount, error) {
+	account, err := r.reader.Account(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	read := &StateRead{StateRoot: r.stateRoot, Address: addr}
+	if account != nil {
+		read.Account = account.Copy()
+	}
+	if err := r.sink.RecordRead(read); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Storage implementing Reader interface, retrieving the storage slot associated
+// with a particular account address and slot key.
+func (r *recorderReader) Storage(addr gethcommon.Address, slot gethcommon.Hash) (gethcommon.Hash, error) {
+	value, err := r.reader.Storage(addr, slot)
+	if err != nil {
+		return gethcommon.Hash{}, err
+	}
+
+	if err := r.sink.RecordRead(&StateRead{StateRoot: r.stateRoot, Address: addr, Slot: &slot, Value: &value}); err != nil {
+		return gethcommon.Hash{}, err
+	}
+
+	return value, nil
+}
+
+// Copy implementing Reader interface, returning a deep-copied state reader recording into the same sink.
+func (r *recorderReader) Copy() gethstate.Reader {
+	return &recorderReader{
+		reader:    r.reader.Copy(),
+		stateRoot: r.stateRoot,
+		sink:      r.sink,
+	}
+}
+
+type jsonReadSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONReadSink creates a sink writing every state read as a JSON line
+func NewJSONReadSink(w io.Writer) ReadSink {
+	return &jsonReadSink{enc: json.NewEncoder(w)}
+}
+
+func (s *jsonReadSink) RecordRead(read *StateRead) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(read)
+}
@@ -118,8 +118,11 @@ func (r *stateAccessTrackerReader) Account(addr gethcommon.Address) (*gethtypes.
 		return nil, err
 	}
 
+	// Accounts absent from the state are also tracked (with a nil value) as they may be created during execution
 	if account != nil {
 		r.tracker.Accounts[addr] = account.Copy()
+	} else {
+		r.tracker.Accounts[addr] = nil
 	}
 
 	return account, nil
@@ -157,6 +160,10 @@ func (r *stateAccessTrackerReader) Copy() gethstate.Reader {
 func copyAccounts(accounts map[gethcommon.Address]*gethtypes.StateAccount) map[gethcommon.Address]*gethtypes.StateAccount {
 	copied := make(map[gethcommon.Address]*gethtypes.StateAccount)
 	for addr, acct := range accounts {
+		if acct == nil {
+			copied[addr] = nil
+			continue
+		}
 		copied[addr] = acct.Copy()
 	}
 	return copied
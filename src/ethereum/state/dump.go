@@ -0,0 +1,85 @@
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Dump is a flat key-value dump of the state touched during a block execution
+type Dump struct {
+	Accounts []*DumpAccount `json:"accounts"`
+	Storage  []*DumpSlot    `json:"storage"`
+}
+
+// DumpAccount is an account entry of a state dump
+type DumpAccount struct {
+	Address  gethcommon.Address `json:"address"`
+	Nonce    hexutil.Uint64     `json:"nonce"`
+	Balance  *hexutil.Big       `json:"balance"`
+	CodeHash gethcommon.Hash    `json:"codeHash"`
+}
+
+// DumpSlot is a storage slot entry of a state dump
+type DumpSlot struct {
+	Address gethcommon.Address `json:"address"`
+	Key     gethcommon.Hash    `json:"key"`
+	Value   gethcommon.Hash    `json:"value"`
+}
+
+// HashedAddresses maps every account recorded by the tracker to its account trie key (keccak256 of the address)
+// It includes accounts absent from the pre-state, which are keyed in the trie once created.
+func HashedAddresses(tracker *AccessTracker) map[gethcommon.Address]gethcommon.Hash {
+	keys := make(map[gethcommon.Address]gethcommon.Hash, len(tracker.Accounts))
+	for addr := range tracker.Accounts {
+		keys[addr] = crypto.Keccak256Hash(addr[:])
+	}
+	return keys
+}
+
+// DumpTouchedState dumps every account and storage slot recorded by the tracker with its value read from the given post-state
+// Accounts that do not exist in the post-state (e.g. self-destructed) are omitted together with their storage
+// Entries are sorted by address (and by key for storage slots)
+func DumpTouchedState(tracker *AccessTracker, post *gethstate.StateDB) *Dump {
+	dump := &Dump{
+		Accounts: make([]*DumpAccount, 0, len(tracker.Accounts)),
+		Storage:  make([]*DumpSlot, 0),
+	}
+
+	for addr := range tracker.Accounts {
+		if !post.Exist(addr) {
+			continue
+		}
+
+		dump.Accounts = append(dump.Accounts, &DumpAccount{
+			Address:  addr,
+			Nonce:    hexutil.Uint64(post.GetNonce(addr)),
+			Balance:  (*hexutil.Big)(post.GetBalance(addr).ToBig()),
+			CodeHash: post.GetCodeHash(addr),
+		})
+
+		for key := range tracker.Storage[addr] {
+			dump.Storage = append(dump.Storage, &DumpSlot{
+				Address: addr,
+				Key:     key,
+				Value:   post.GetState(addr, key),
+			})
+		}
+	}
+
+	sort.Slice(dump.Accounts, func(i, j int) bool {
+		return bytes.Compare(dump.Accounts[i].Address.Bytes(), dump.Accounts[j].Address.Bytes()) < 0
+	})
+	sort.Slice(dump.Storage, func(i, j int) bool {
+		if c := bytes.Compare(dump.Storage[i].Address.Bytes(), dump.Storage[j].Address.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(dump.Storage[i].Key.Bytes(), dump.Storage[j].Key.Bytes()) < 0
+	})
+
+	return dump
+}
@@ -0,0 +1,249 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ModifiedNode is a trie node modified by a state transition, identified by the owner of its trie and its path
+type ModifiedNode struct {
+	Owner gethcommon.Hash `json:"owner"` // Owner of the trie (zero for the account trie, account hash for storage tries)
+	Path  hexutil.Bytes   `json:"path"`  // Path of the node in hex nibbles
+	Blob  hexutil.Bytes   `json:"blob"`  // RLP encoded node, empty if the node was deleted
+}
+
+// DiffNodes returns the nodes of the account and storage tries modified between the pre-state and post-state roots
+// Nodes are resolved from the given node set (indexed by hash), sub-tries missing from the node set are considered unchanged.
+// Only nodes referenced by hash are returned (embedded nodes are part of their parent) and entries are sorted by owner then path.
+func DiffNodes(preRoot, postRoot gethcommon.Hash, nodes map[gethcommon.Hash][]byte) ([]*ModifiedNode, error) {
+	preAccounts, preStorageRoots, err := collectNodes(preRoot, nodes, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pre-state nodes: %v", err)
+	}
+	postAccounts, postStorageRoots, err := collectNodes(postRoot, nodes, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect post-state nodes: %v", err)
+	}
+
+	modified := diffPaths(AccountTrieOwner(), preAccounts, postAccounts)
+
+	owners := make(map[gethcommon.Hash]struct{})
+	for owner := range preStorageRoots {
+		owners[owner] = struct{}{}
+	}
+	for owner := range postStorageRoots {
+		owners[owner] = struct{}{}
+	}
+	for owner := range owners {
+		if preStorageRoots[owner] == postStorageRoots[owner] {
+			continue
+		}
+		preSlots, _, err := collectNodes(preStorageRoots[owner], nodes, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect pre-state storage nodes of %v: %v", owner.Hex(), err)
+		}
+		postSlots, _, err := collectNodes(postStorageRoots[owner], nodes, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect post-state storage nodes of %v: %v", owner.Hex(), err)
+		}
+		modified = append(modified, diffPaths(owner, preSlots, postSlots)...)
+	}
+
+	sortModifiedNodes(modified)
+
+	return modified, nil
+}
+
+// EncodeModifiedNodes returns the canonical serialization of modified nodes, i.e. the RLP encoding of the [owner, path, blob] entries sorted by owner then path
+// The encoding only depends on the set of nodes, not on their order.
+func EncodeModifiedNodes(nodes []*ModifiedNode) ([]byte, error) {
+	sorted := make([]*ModifiedNode, len(nodes))
+	copy(sorted, nodes)
+	sortModifiedNodes(sorted)
+
+	entries := make([]modifiedNodeRLP, 0, len(sorted))
+	for _, node := range sorted {
+		entries = append(entries, modifiedNodeRLP{Owner: node.Owner, Path: node.Path, Blob: node.Blob})
+	}
+	return rlp.EncodeToBytes(entries)
+}
+
+// DecodeModifiedNodes decodes modified nodes serialized with EncodeModifiedNodes
+func DecodeModifiedNodes(b []byte) ([]*ModifiedNode, error) {
+	var entries []modifiedNodeRLP
+	if err := rlp.DecodeBytes(b, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode modified nodes: %v", err)
+	}
+
+	nodes := make([]*ModifiedNode, 0, len(entries))
+	for _, entry := range entries {
+		nodes = append(nodes, &ModifiedNode{Owner: entry.Owner, Path: entry.Path, Blob: entry.Blob})
+	}
+	return nodes, nil
+}
+
+type modifiedNodeRLP struct {
+	Owner gethcommon.Hash
+	Path  []byte
+	Blob  []byte
+}
+
+func sortModifiedNodes(nodes []*ModifiedNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if c := bytes.Compare(nodes[i].Owner.Bytes(), nodes[j].Owner.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(nodes[i].Path, nodes[j].Path) < 0
+	})
+}
+
+type pathNode struct {
+	hash gethcommon.Hash
+	blob []byte
+}
+
+// collectNodes returns the nodes of the trie indexed by path, and the storage roots of the accounts if the trie is the account trie
+func collectNodes(root gethcommon.Hash, nodes map[gethcommon.Hash][]byte, accounts bool) (map[string]pathNode, map[gethcommon.Hash]gethcommon.Hash, error) {
+	byPath := make(map[string]pathNode)
+	storageRoots := make(map[gethcommon.Hash]gethcommon.Hash)
+	if root == (gethcommon.Hash{}) {
+		return byPath, storageRoots, nil
+	}
+
+	var leafErr error
+	err := Walk(root, nodes, &Visitor{
+		OnNode: func(path []byte, hash gethcommon.Hash, blob []byte) {
+			byPath[string(path)] = pathNode{hash: hash, blob: blob}
+		},
+		OnLeaf: func(key, value []byte) {
+			if !accounts {
+				return
+			}
+			var account gethtypes.StateAccount
+			if err := rlp.DecodeBytes(value, &account); err != nil {
+				leafErr = fmt.Errorf("failed to decode account %x: %v", key, err)
+				return
+			}
+			if account.Root != gethtypes.EmptyRootHash {
+				storageRoots[gethcommon.BytesToHash(key)] = account.Root
+			}
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if leafErr != nil {
+		return nil, nil, leafErr
+	}
+	return byPath, storageRoots, nil
+}
+
+func diffPaths(owner gethcommon.Hash, pre, post map[string]pathNode) []*ModifiedNode {
+	modified := make([]*ModifiedNode, 0)
+	for path, node := range post {
+		if preNode, ok := pre[path]; ok && preNode.hash == node.hash {
+			continue
+		}
+		modified = append(modified, &ModifiedNode{Owner: owner, Path: []byte(path), Blob: node.blob})
+	}
+	for path := range pre {
+		if _, ok := post[path]; !ok {
+			modified = append(modified, &ModifiedNode{Owner: owner, Path: []byte(path), Blob: []byte{}})
+		}
+	}
+	return modified
+}
+
+// SlotChange is a storage slot whose value changed between two states, identified by the owner of its storage trie and the hash of its key
+type SlotChange struct {
+	Owner   gethcommon.Hash `json:"owner"`   // Account hash of the storage trie owner
+	KeyHash gethcommon.Hash `json:"keyHash"` // Hash of the slot key
+	Pre     gethcommon.Hash `json:"pre"`     // Pre-state value (zero if the slot did not exist)
+	Post    gethcommon.Hash `json:"post"`    // Post-state value (zero if the slot was deleted)
+}
+
+// DiffStorage returns the storage slots whose value changed between the pre-state and post-state roots, derived from the storage tries leaves
+// Nodes are resolved from the given node set (indexed by hash), sub-tries missing from the node set are considered unchanged.
+// Entries are sorted by owner then key hash.
+func DiffStorage(preRoot, postRoot gethcommon.Hash, nodes map[gethcommon.Hash][]byte) ([]*SlotChange, error) {
+	_, preStorageRoots, err := collectNodes(preRoot, nodes, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect pre-state nodes: %v", err)
+	}
+	_, postStorageRoots, err := collectNodes(postRoot, nodes, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect post-state nodes: %v", err)
+	}
+
+	owners := make(map[gethcommon.Hash]struct{})
+	for owner := range preStorageRoots {
+		owners[owner] = struct{}{}
+	}
+	for owner := range postStorageRoots {
+		owners[owner] = struct{}{}
+	}
+
+	changes := make([]*SlotChange, 0)
+	for owner := range owners {
+		if preStorageRoots[owner] == postStorageRoots[owner] {
+			continue
+		}
+		preSlots, err := collectSlots(preStorageRoots[owner], nodes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect pre-state storage of %v: %v", owner.Hex(), err)
+		}
+		postSlots, err := collectSlots(postStorageRoots[owner], nodes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect post-state storage of %v: %v", owner.Hex(), err)
+		}
+
+		for key, post := range postSlots {
+			if pre := preSlots[key]; pre != post {
+				changes = append(changes, &SlotChange{Owner: owner, KeyHash: key, Pre: pre, Post: post})
+			}
+		}
+		for key, pre := range preSlots {
+			if _, ok := postSlots[key]; !ok {
+				changes = append(changes, &SlotChange{Owner: owner, KeyHash: key, Pre: pre})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if c := bytes.Compare(changes[i].Owner.Bytes(), changes[j].Owner.Bytes()); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(changes[i].KeyHash.Bytes(), changes[j].KeyHash.Bytes()) < 0
+	})
+
+	return changes, nil
+}
+
+// collectSlots returns the values of the storage trie leaves indexed by key hash
+func collectSlots(root gethcommon.Hash, nodes map[gethcommon.Hash][]byte) (map[gethcommon.Hash]gethcommon.Hash, error) {
+	slots := make(map[gethcommon.Hash]gethcommon.Hash)
+	var leafErr error
+	err := Walk(root, nodes, &Visitor{
+		OnLeaf: func(key, value []byte) {
+			_, content, _, err := rlp.Split(value)
+			if err != nil {
+				leafErr = fmt.Errorf("failed to decode slot %x: %v", key, err)
+				return
+			}
+			slots[gethcommon.BytesToHash(key)] = gethcommon.BytesToHash(content)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if leafErr != nil {
+		return nil, leafErr
+	}
+	return slots, nil
+}
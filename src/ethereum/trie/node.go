@@ -0,0 +1,175 @@
+package trie
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Node is a decoded MPT node
+// It is one of *FullNode, *ShortNode, HashNode or ValueNode
+type Node interface{}
+
+type (
+	// FullNode is a branch node, Children[16] holds the value of the node if any
+	FullNode struct {
+		Children [17]Node
+	}
+
+	// ShortNode is an extension or leaf node
+	// Key is expressed in hex nibbles and terminated by 16 for leaf nodes
+	ShortNode struct {
+		Key []byte
+		Val Node
+	}
+
+	// HashNode is a reference to a node by its hash
+	HashNode gethcommon.Hash
+
+	// ValueNode is a leaf value
+	ValueNode []byte
+)
+
+// IsLeaf returns true if the short node is a leaf node
+func (n *ShortNode) IsLeaf() bool {
+	return hasTerm(n.Key)
+}
+
+// NodesByHash indexes the given MPT node blobs by their hash
+func NodesByHash[T ~[]byte](blobs ...T) map[gethcommon.Hash][]byte {
+	nodes := make(map[gethcommon.Hash][]byte, len(blobs))
+	for _, blob := range blobs {
+		nodes[crypto.Keccak256Hash(blob)] = blob
+	}
+	return nodes
+}
+
+// DecodeNode decodes the RLP encoding of a MPT node
+func DecodeNode(blob []byte) (Node, error) {
+	elems, _, err := rlp.SplitList(blob)
+	if err != nil {
+		return nil, fmt.Errorf("node decode error: %v", err)
+	}
+
+	switch c, _ := rlp.CountValues(elems); c {
+	case 2:
+		return decodeShort(elems)
+	case 17:
+		return decodeFull(elems)
+	default:
+		return nil, fmt.Errorf("invalid number of node list elements: %v", c)
+	}
+}
+
+func decodeShort(elems []byte) (*ShortNode, error) {
+	kbuf, rest, err := rlp.SplitString(elems)
+	if err != nil {
+		return nil, fmt.Errorf("invalid short node key: %v", err)
+	}
+
+	key := compactToHex(kbuf)
+	if hasTerm(key) {
+		val, _, err := rlp.SplitString(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value node: %v", err)
+		}
+		return &ShortNode{Key: key, Val: ValueNode(val)}, nil
+	}
+
+	ref, _, err := decodeRef(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid short node value: %v", err)
+	}
+
+	return &ShortNode{Key: key, Val: ref}, nil
+}
+
+func decodeFull(elems []byte) (*FullNode, error) {
+	n := new(FullNode)
+	for i := 0; i < 16; i++ {
+		ref, rest, err := decodeRef(elems)
+		if err != nil {
+			return nil, fmt.Errorf("invalid full node child [%d]: %v", i, err)
+		}
+		n.Children[i], elems = ref, rest
+	}
+
+	val, _, err := rlp.SplitString(elems)
+	if err != nil {
+		return nil, fmt.Errorf("invalid full node value: %v", err)
+	}
+	if len(val) > 0 {
+		n.Children[16] = ValueNode(val)
+	}
+
+	return n, nil
+}
+
+func decodeRef(buf []byte) (Node, []byte, error) {
+	kind, val, rest, err := rlp.Split(buf)
+	if err != nil {
+		return nil, buf, err
+	}
+
+	switch {
+	case kind == rlp.List:
+		// Embedded node
+		n, err := DecodeNode(buf[:len(buf)-len(rest)])
+		return n, rest, err
+	case kind == rlp.String && len(val) == 0:
+		return nil, rest, nil
+	case kind == rlp.String && len(val) == 32:
+		return HashNode(gethcommon.BytesToHash(val)), rest, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid RLP string size %d (want 0 or 32)", len(val))
+	}
+}
+
+// compactToHex converts a compact encoded key to hex nibbles (as in go-ethereum)
+func compactToHex(compact []byte) []byte {
+	if len(compact) == 0 {
+		return compact
+	}
+	base := keybytesToHex(compact)
+	// delete terminator flag
+	if base[0] < 2 {
+		base = base[:len(base)-1]
+	}
+	// apply odd flag
+	chop := 2 - base[0]&1
+	return base[chop:]
+}
+
+func keybytesToHex(str []byte) []byte {
+	l := len(str)*2 + 1
+	nibbles := make([]byte, l)
+	for i, b := range str {
+		nibbles[i*2] = b / 16
+		nibbles[i*2+1] = b % 16
+	}
+	nibbles[l-1] = 16
+	return nibbles
+}
+
+// HexToKeybytes converts hex nibbles (optionally terminated) into key bytes
+// It returns false if the nibbles can not be converted into key bytes (odd length)
+func HexToKeybytes(hex []byte) ([]byte, bool) {
+	if hasTerm(hex) {
+		hex = hex[:len(hex)-1]
+	}
+	if len(hex)&1 != 0 {
+		return nil, false
+	}
+	key := make([]byte, len(hex)/2)
+	for bi, ni := 0, 0; ni < len(hex); bi, ni = bi+1, ni+2 {
+		key[bi] = hex[ni]<<4 | hex[ni+1]
+	}
+	return key, true
+}
+
+// hasTerm returns whether a hex key has the terminator flag.
+func hasTerm(s []byte) bool {
+	return len(s) > 0 && s[len(s)-1] == 16
+}
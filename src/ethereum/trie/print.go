@@ -0,0 +1,110 @@
+package trie
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// PrintOptions configures how a trie is printed
+type PrintOptions struct {
+	// MaxDepth is the maximum depth of printed nodes (root is at depth 0), zero means no limit
+	MaxDepth int
+
+	// FormatValue formats leaf values, by default values are printed in hex
+	FormatValue func(value []byte) string
+}
+
+// Print writes a human-readable representation of the trie with the given root by resolving nodes from the given node set (indexed by hash)
+// Nodes that are referenced but missing from the node set are printed as <missing>
+func Print(w io.Writer, root gethcommon.Hash, nodes map[gethcommon.Hash][]byte, opts *PrintOptions) error {
+	if opts == nil {
+		opts = &PrintOptions{}
+	}
+	if root == gethtypes.EmptyRootHash || root == (gethcommon.Hash{}) {
+		_, err := fmt.Fprintln(w, "<empty>")
+		return err
+	}
+	p := &printer{w: w, nodes: nodes, opts: opts}
+	return p.print(nil, HashNode(root), 0, "root")
+}
+
+type printer struct {
+	w     io.Writer
+	nodes map[gethcommon.Hash][]byte
+	opts  *PrintOptions
+}
+
+func (p *printer) printf(depth int, format string, args ...any) error {
+	_, err := fmt.Fprintf(p.w, strings.Repeat("  ", depth)+format+"\n", args...)
+	return err
+}
+
+func (p *printer) formatValue(value []byte) string {
+	if p.opts.FormatValue != nil {
+		return p.opts.FormatValue(value)
+	}
+	return fmt.Sprintf("%#x", value)
+}
+
+func (p *printer) print(path []byte, n Node, depth int, label string) error {
+	var hash string
+	if h, ok := n.(HashNode); ok {
+		blob, ok := p.nodes[gethcommon.Hash(h)]
+		if !ok {
+			return p.printf(depth, "%s: <missing> %v", label, gethcommon.Hash(h).Hex())
+		}
+		decoded, err := DecodeNode(blob)
+		if err != nil {
+			return fmt.Errorf("failed to decode node %v at path %x: %v", gethcommon.Hash(h).Hex(), path, err)
+		}
+		n, hash = decoded, " "+gethcommon.Hash(h).Hex()
+	}
+
+	if p.opts.MaxDepth > 0 && depth > p.opts.MaxDepth {
+		return p.printf(depth, "%s: ...", label)
+	}
+
+	switch n := n.(type) {
+	case *FullNode:
+		if err := p.printf(depth, "%s: branch%s", label, hash); err != nil {
+			return err
+		}
+		for i, child := range n.Children {
+			if child == nil {
+				continue
+			}
+			if err := p.print(append(append([]byte{}, path...), byte(i)), child, depth+1, fmt.Sprintf("[%x]", i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ShortNode:
+		childPath := append(append([]byte{}, path...), n.Key...)
+		if value, ok := n.Val.(ValueNode); ok {
+			key, _ := HexToKeybytes(childPath)
+			return p.printf(depth, "%s: leaf%s key=%#x %s", label, hash, key, p.formatValue(value))
+		}
+		if err := p.printf(depth, "%s: extension%s nibbles=%s", label, hash, formatNibbles(n.Key)); err != nil {
+			return err
+		}
+		return p.print(childPath, n.Val, depth+1, "child")
+	case ValueNode:
+		return p.printf(depth, "%s: value %s", label, p.formatValue(n))
+	default:
+		return fmt.Errorf("unexpected node type %T", n)
+	}
+}
+
+func formatNibbles(nibbles []byte) string {
+	var sb strings.Builder
+	for _, nibble := range nibbles {
+		if nibble < 16 {
+			sb.WriteByte("0123456789abcdef"[nibble])
+		}
+	}
+	return sb.String()
+}
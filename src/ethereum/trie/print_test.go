@@ -0,0 +1,40 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrint(t *testing.T) {
+	kvs := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		kvs[string(key)] = "v"
+	}
+	root, nodes := newTestTrieNodes(t, kvs)
+
+	var buf bytes.Buffer
+	require.NoError(t, Print(&buf, root, nodes, nil))
+	assert.True(t, strings.HasPrefix(buf.String(), "root: branch "+root.Hex()))
+	assert.Contains(t, buf.String(), fmt.Sprintf("key=%#x 0x76", crypto.Keccak256([]byte{0})))
+	assert.Equal(t, len(kvs), strings.Count(buf.String(), "leaf"))
+
+	t.Run("max depth", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, Print(&buf, root, nodes, &PrintOptions{MaxDepth: 1}))
+		assert.Less(t, strings.Count(buf.String(), "leaf"), len(kvs))
+		assert.Contains(t, buf.String(), "...")
+	})
+
+	t.Run("missing nodes", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, Print(&buf, root, NodesByHash[[]byte](), nil))
+		assert.Equal(t, fmt.Sprintf("root: <missing> %v\n", root.Hex()), buf.String())
+	})
+}
@@ -0,0 +1,78 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// ProofNode is a node of a Merkle proof path, resolved by hash
+type ProofNode struct {
+	Path hexutil.Bytes   `json:"path"` // Path of the node in hex nibbles
+	Hash gethcommon.Hash `json:"hash"`
+	Blob hexutil.Bytes   `json:"blob"` // RLP encoded node
+}
+
+// KeyProof is the Merkle proof path of a key, from the root to the leaf (or to the node proving the key absence)
+type KeyProof struct {
+	Nodes []*ProofNode
+	Value []byte // Value of the key, nil if the key is absent from the trie
+
+	// Missing is the hash of the first node of the path absent from the node set, if any
+	// In which case the proof is incomplete and the key is not covered by the node set.
+	Missing *gethcommon.Hash
+}
+
+// ProveKey returns the Merkle proof path of the key in the trie with the given root, resolving nodes from the given node set (indexed by hash)
+// Embedded nodes are part of their parent, so only nodes referenced by hash are part of the path.
+func ProveKey(root gethcommon.Hash, key []byte, nodes map[gethcommon.Hash][]byte) (*KeyProof, error) {
+	proof := new(KeyProof)
+	if root == gethtypes.EmptyRootHash || root == (gethcommon.Hash{}) {
+		return proof, nil
+	}
+
+	hexKey := keybytesToHex(key)
+	var (
+		n    Node = HashNode(root)
+		path []byte
+	)
+	for {
+		switch node := n.(type) {
+		case nil:
+			return proof, nil
+		case HashNode:
+			hash := gethcommon.Hash(node)
+			blob, ok := nodes[hash]
+			if !ok {
+				proof.Missing = &hash
+				return proof, nil
+			}
+			proof.Nodes = append(proof.Nodes, &ProofNode{Path: gethcommon.CopyBytes(path), Hash: hash, Blob: blob})
+			decoded, err := DecodeNode(blob)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode node %v at path %x: %v", hash.Hex(), path, err)
+			}
+			n = decoded
+		case *ShortNode:
+			rest := hexKey[len(path):]
+			if len(rest) < len(node.Key) || !bytes.Equal(node.Key, rest[:len(node.Key)]) {
+				// Key diverges from the node key
+				return proof, nil
+			}
+			path = append(path, node.Key...)
+			n = node.Val
+		case *FullNode:
+			nibble := hexKey[len(path)]
+			path = append(path, nibble)
+			n = node.Children[nibble]
+		case ValueNode:
+			proof.Value = node
+			return proof, nil
+		default:
+			return nil, fmt.Errorf("unexpected node type %T", n)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// TxInclusionProof is a Merkle proof of the inclusion of a transaction in the transactions trie of a block
+type TxInclusionProof struct {
+	Index uint64          `json:"index"` // Index of the transaction in the block
+	Nodes []hexutil.Bytes `json:"nodes"` // Trie nodes on the path from the transactions root to the transaction
+}
+
+// ProveTxInclusion generates the proof of inclusion of the transaction at the given index under the block transactions root
+func ProveTxInclusion(block *gethtypes.Block, index int) (*TxInclusionProof, error) {
+	txs := block.Transactions()
+	if index < 0 || index >= len(txs) {
+		return nil, fmt.Errorf("transaction index %d out of range (block has %d transactions)", index, len(txs))
+	}
+
+	tr := trie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	var buf bytes.Buffer
+	for i := range txs {
+		buf.Reset()
+		txs.EncodeIndex(i, &buf)
+		if err := tr.Update(rlp.AppendUint64(nil, uint64(i)), gethcommon.CopyBytes(buf.Bytes())); err != nil {
+			return nil, fmt.Errorf("failed to insert transaction %d: %v", i, err)
+		}
+	}
+	if root := tr.Hash(); root != block.TxHash() {
+		return nil, fmt.Errorf("transactions root mismatch (header %v, computed %v)", block.TxHash().Hex(), root.Hex())
+	}
+
+	var nodes trienode.ProofList
+	if err := tr.Prove(rlp.AppendUint64(nil, uint64(index)), &nodes); err != nil {
+		return nil, fmt.Errorf("failed to prove transaction %d: %v", index, err)
+	}
+
+	proof := &TxInclusionProof{Index: uint64(index)}
+	for _, node := range nodes {
+		proof.Nodes = append(proof.Nodes, hexutil.Bytes(node))
+	}
+	return proof, nil
+}
+
+// VerifyTxInclusion verifies the proof against the given transactions root (e.g. header.TxHash) and returns the proved transaction
+func VerifyTxInclusion(txRoot gethcommon.Hash, proof *TxInclusionProof) (*gethtypes.Transaction, error) {
+	nodes := make(trienode.ProofList, 0, len(proof.Nodes))
+	for _, node := range proof.Nodes {
+		nodes = append(nodes, rlp.RawValue(node))
+	}
+
+	value, err := trie.VerifyProof(txRoot, rlp.AppendUint64(nil, proof.Index), nodes.Set())
+	if err != nil {
+		return nil, fmt.Errorf("invalid inclusion proof of transaction %d: %v", proof.Index, err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("transaction %d is not included under transactions root %v", proof.Index, txRoot.Hex())
+	}
+
+	tx := new(gethtypes.Transaction)
+	if err := tx.UnmarshalBinary(value); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction %d: %v", proof.Index, err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,56 @@
+package trie
+
+import (
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBlock(n int) *gethtypes.Block {
+	to := gethcommon.HexToAddress("0xdead")
+	txs := make([]*gethtypes.Transaction, 0, n)
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			txs = append(txs, gethtypes.NewTx(&gethtypes.LegacyTx{Nonce: uint64(i), To: &to, Value: big.NewInt(int64(i)), Gas: 21000, GasPrice: big.NewInt(1)}))
+		} else {
+			txs = append(txs, gethtypes.NewTx(&gethtypes.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: uint64(i), To: &to, Value: big.NewInt(int64(i)), Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2)}))
+		}
+	}
+	return gethtypes.NewBlock(&gethtypes.Header{Number: big.NewInt(1)}, &gethtypes.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+}
+
+func TestTxInclusionProof(t *testing.T) {
+	block := newTestBlock(150)
+
+	for _, index := range []int{0, 1, 42, 127, 128, 149} {
+		proof, err := ProveTxInclusion(block, index)
+		require.NoError(t, err)
+
+		tx, err := VerifyTxInclusion(block.TxHash(), proof)
+		require.NoError(t, err)
+		assert.Equal(t, block.Transactions()[index].Hash(), tx.Hash())
+	}
+
+	proof, err := ProveTxInclusion(block, 42)
+	require.NoError(t, err)
+
+	t.Run("wrong root", func(t *testing.T) {
+		_, err := VerifyTxInclusion(newTestBlock(10).TxHash(), proof)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong index", func(t *testing.T) {
+		_, err := VerifyTxInclusion(block.TxHash(), &TxInclusionProof{Index: 43, Nodes: proof.Nodes})
+		require.Error(t, err)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, err := ProveTxInclusion(block, 150)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package trie
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Visitor holds the callbacks invoked while walking a trie
+// Every callback is optional
+type Visitor struct {
+	// OnNode is called for every node resolved by hash, path is the hex nibbles path of the node
+	OnNode func(path []byte, hash gethcommon.Hash, blob []byte)
+
+	// OnLeaf is called for every leaf, key is the full trie key of the leaf
+	OnLeaf func(key, value []byte)
+
+	// OnMissing is called for every node referenced but absent from the node set
+	// The sub-trie below a missing node is skipped
+	OnMissing func(path []byte, hash gethcommon.Hash)
+}
+
+// Walk walks the trie with the given root by resolving nodes from the given node set (indexed by hash)
+// It visits nodes depth-first in key order
+// Nodes that are referenced but missing are reported to the visitor and their sub-trie is skipped
+// It returns an error only if a node can not be decoded
+func Walk(root gethcommon.Hash, nodes map[gethcommon.Hash][]byte, v *Visitor) error {
+	if root == gethtypes.EmptyRootHash || root == (gethcommon.Hash{}) {
+		return nil
+	}
+	return walk(nil, HashNode(root), nodes, v)
+}
+
+func walk(path []byte, n Node, nodes map[gethcommon.Hash][]byte, v *Visitor) error {
+	switch n := n.(type) {
+	case nil:
+		return nil
+	case HashNode:
+		blob, ok := nodes[gethcommon.Hash(n)]
+		if !ok {
+			if v.OnMissing != nil {
+				v.OnMissing(path, gethcommon.Hash(n))
+			}
+			return nil
+		}
+		if v.OnNode != nil {
+			v.OnNode(path, gethcommon.Hash(n), blob)
+		}
+		decoded, err := DecodeNode(blob)
+		if err != nil {
+			return fmt.Errorf("failed to decode node %v at path %x: %v", gethcommon.Hash(n).Hex(), path, err)
+		}
+		return walk(path, decoded, nodes, v)
+	case *ShortNode:
+		return walk(append(append([]byte{}, path...), n.Key...), n.Val, nodes, v)
+	case *FullNode:
+		for i, child := range n.Children {
+			if err := walk(append(append([]byte{}, path...), byte(i)), child, nodes, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ValueNode:
+		if v.OnLeaf != nil {
+			key, ok := HexToKeybytes(path)
+			if !ok {
+				return fmt.Errorf("invalid leaf path %x", path)
+			}
+			v.OnLeaf(key, n)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected node type %T", n)
+	}
+}
@@ -0,0 +1,67 @@
+package trie
+
+import (
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTrieNodes builds a trie with the given key/values and returns its root and nodes
+func newTestTrieNodes(t *testing.T, kvs map[string]string) (gethcommon.Hash, map[gethcommon.Hash][]byte) {
+	tr := trie.NewEmpty(newTestTrieDB())
+	for k, v := range kvs {
+		require.NoError(t, tr.Update([]byte(k), []byte(v)))
+	}
+	root, set := tr.Commit(false)
+
+	nodes := make(map[gethcommon.Hash][]byte)
+	for _, n := range set.Nodes {
+		nodes[n.Hash] = n.Blob
+	}
+	return root, nodes
+}
+
+func TestWalk(t *testing.T) {
+	kvs := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		kvs[string(key)] = string(crypto.Keccak256(key))
+	}
+	kvs["short"] = "v"
+
+	root, nodes := newTestTrieNodes(t, kvs)
+
+	t.Run("complete trie", func(t *testing.T) {
+		leaves := make(map[string]string)
+		var visited int
+		err := Walk(root, nodes, &Visitor{
+			OnNode: func(_ []byte, _ gethcommon.Hash, _ []byte) { visited++ },
+			OnLeaf: func(key, value []byte) { leaves[string(key)] = string(value) },
+			OnMissing: func(path []byte, hash gethcommon.Hash) {
+				t.Errorf("unexpected missing node %v at path %x", hash.Hex(), path)
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, kvs, leaves)
+		assert.Equal(t, len(nodes), visited)
+	})
+
+	t.Run("incomplete trie", func(t *testing.T) {
+		partial := make(map[gethcommon.Hash][]byte)
+		for hash, blob := range nodes {
+			partial[hash] = blob
+		}
+		delete(partial, root)
+
+		var missing []gethcommon.Hash
+		err := Walk(root, partial, &Visitor{
+			OnMissing: func(_ []byte, hash gethcommon.Hash) { missing = append(missing, hash) },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []gethcommon.Hash{root}, missing)
+	})
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Attestation is a compact summary of an execution, meant to be attested on-chain (e.g. for optimistic proving)
+// The prover input is identified by its chain ID, block number and block hash, and by the hash of its witness (see input.WitnessHash).
+// The witness hash is used rather than the input checksum, as the checksum is optional and covers encoding details (e.g. version).
+type Attestation struct {
+	ChainID       *big.Int        `json:"chainId"`
+	BlockNumber   uint64          `json:"blockNumber"`
+	BlockHash     gethcommon.Hash `json:"blockHash"`
+	WitnessHash   gethcommon.Hash `json:"witnessHash"`
+	PreStateRoot  gethcommon.Hash `json:"preStateRoot"`
+	PostStateRoot gethcommon.Hash `json:"postStateRoot"`
+	ReceiptsRoot  gethcommon.Hash `json:"receiptsRoot"`
+	GasUsed       uint64          `json:"gasUsed"`
+}
+
+// Hash returns the keccak256 hash of the ABI encoded attestation fields
+// It matches keccak256(abi.encode(chainId, blockNumber, blockHash, witnessHash, preStateRoot, postStateRoot, receiptsRoot, gasUsed)) in Solidity
+// A nil chain ID is encoded as zero.
+func (a Attestation) Hash() gethcommon.Hash {
+	chainID := new(big.Int)
+	if a.ChainID != nil {
+		chainID.Set(a.ChainID)
+	}
+	return crypto.Keccak256Hash(
+		math.U256Bytes(chainID),
+		math.U256Bytes(new(big.Int).SetUint64(a.BlockNumber)),
+		a.BlockHash.Bytes(),
+		a.WitnessHash.Bytes(),
+		a.PreStateRoot.Bytes(),
+		a.PostStateRoot.Bytes(),
+		a.ReceiptsRoot.Bytes(),
+		math.U256Bytes(new(big.Int).SetUint64(a.GasUsed)),
+	)
+}
+
+// Attestation returns the attestation of the execution
+// It is the zero value on the partial result of a failed execution, which must not be attested.
+func (res *Result) Attestation() Attestation {
+	return res.attestation
+}
@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultAttestation(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+	header := proverInput.Blocks[0].Header
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	attestation := res.Attestation()
+	assert.Equal(t, proverInput.ChainConfig.ChainID, attestation.ChainID)
+	assert.Equal(t, header.Number.Uint64(), attestation.BlockNumber)
+	assert.Equal(t, header.Hash(), attestation.BlockHash)
+	witnessHash, err := input.WitnessHash(proverInput.Witness)
+	require.NoError(t, err)
+	assert.Equal(t, witnessHash, attestation.WitnessHash)
+	assert.Equal(t, proverInput.Witness.Ancestors[0].Root, attestation.PreStateRoot)
+	assert.Equal(t, header.Root, attestation.PostStateRoot)
+	assert.Equal(t, header.ReceiptHash, attestation.ReceiptsRoot)
+	assert.Equal(t, header.GasUsed, attestation.GasUsed)
+
+	// The attestation hash is stable across executions
+	assert.Equal(t, gethcommon.HexToHash("0x30ca498e4c8abc0bf1ceabf479c28e8ae8d6cc80748eba47f3b0369376591445"), attestation.Hash())
+
+	res, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, attestation.Hash(), res.Attestation().Hash())
+}
+
+func TestAttestationHashZero(t *testing.T) {
+	// The zero attestation of a partial result hashes with a zero chain ID
+	assert.Equal(t, Attestation{ChainID: new(big.Int)}.Hash(), new(Result).Attestation().Hash())
+}
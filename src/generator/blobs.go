@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// VersionedHash is a blob versioned hash referenced by a blob transaction of the block
+type VersionedHash struct {
+	TxIndex int             `json:"txIndex"`
+	Hash    gethcommon.Hash `json:"hash"`
+}
+
+// blockVersionedHashes returns the versioned hashes referenced by the block blob transactions, in block order
+// It errors if any of them is not a valid KZG versioned hash (i.e. not prefixed with the 0x01 version byte)
+func blockVersionedHashes(block *gethtypes.Block) ([]*VersionedHash, error) {
+	var hashes []*VersionedHash
+	for i, tx := range block.Transactions() {
+		for j, hash := range tx.BlobHashes() {
+			if !kzg4844.IsValidVersionedHash(hash[:]) {
+				return nil, fmt.Errorf("invalid versioned hash %v (transaction %d, blob %d)", hash.Hex(), i, j)
+			}
+			hashes = append(hashes, &VersionedHash{TxIndex: i, Hash: hash})
+		}
+	}
+	return hashes, nil
+}
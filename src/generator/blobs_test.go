@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signTestBlobTx(t *testing.T, nonce uint64, baseFee *big.Int, hashes ...gethcommon.Hash) *gethtypes.Transaction {
+	tx, err := gethtypes.SignNewTx(testKey, gethtypes.LatestSigner(testChainConfig), &gethtypes.BlobTx{
+		ChainID:    uint256.MustFromBig(testChainConfig.ChainID),
+		Nonce:      nonce,
+		To:         testContractAddr,
+		Gas:        21_000,
+		GasTipCap:  uint256.NewInt(params.GWei),
+		GasFeeCap:  uint256.MustFromBig(new(big.Int).Add(baseFee, big.NewInt(params.GWei))),
+		BlobFeeCap: uint256.NewInt(params.GWei),
+		BlobHashes: hashes,
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func TestExecutorVersionedHashes(t *testing.T) {
+	hashes := []gethcommon.Hash{{0x01, 0x01}, {0x01, 0x02}, {0x01, 0x03}}
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestBlobTx(t, b.TxNonce(testAddr), b.BaseFee(), hashes[0], hashes[1]))
+		b.AddTx(signTestBlobTx(t, b.TxNonce(testAddr), b.BaseFee(), hashes[2]))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, []*VersionedHash{
+		{TxIndex: 0, Hash: hashes[0]},
+		{TxIndex: 0, Hash: hashes[1]},
+		{TxIndex: 1, Hash: hashes[2]},
+	}, res.VersionedHashes)
+
+	// Second transaction references a blob hash with an invalid version
+	block := proverInput.Blocks[0]
+	block.Transactions[1] = signTestBlobTx(t, block.Transactions[1].Nonce(), block.Header.BaseFee, gethcommon.Hash{0x02, 0x03})
+	_, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid versioned hash 0x0203000000000000000000000000000000000000000000000000000000000000 (transaction 1, blob 0)")
+}
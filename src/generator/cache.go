@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// DefaultCacheSize is the default number of headers and codes held by a Cache
+const DefaultCacheSize = 4096
+
+// Cache is a cross-execution LRU cache of ancestors headers and codes, meant to be shared by the executions of overlapping blocks (e.g. a batch of blocks from the same chain region)
+// Cached entries hold the database writes of each header and code, so they are encoded and hashed once across executions.
+// Headers are keyed by chain ID and hash, so that executions on different chains are isolated, codes are keyed by their content.
+// It is safe for concurrent use.
+type Cache struct {
+	headers *lru.Cache[headerCacheKey, *cachedHeader]
+	codes   *lru.Cache[string, []dbWrite]
+}
+
+type headerCacheKey struct {
+	chainID string
+	hash    gethcommon.Hash
+}
+
+type cachedHeader struct {
+	header *gethtypes.Header
+	writes []dbWrite
+}
+
+type dbWrite struct {
+	key, value []byte
+}
+
+// NewCache creates a new cache holding up to size headers and size codes
+// If size is zero or negative, DefaultCacheSize is used
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		headers: lru.NewCache[headerCacheKey, *cachedHeader](size),
+		codes:   lru.NewCache[string, []dbWrite](size),
+	}
+}
+
+// writeHeaders writes the ancestors headers into the database, parentHash being the hash of the first ancestor
+// As ancestors are contiguous, the hash of each ancestor is known from its child, so cached headers are written without being re-encoded.
+// It returns the ancestors with cached headers substituted to the provided ones.
+func (c *Cache) writeHeaders(db ethdb.KeyValueWriter, chainID *big.Int, parentHash gethcommon.Hash, headers []*gethtypes.Header) []*gethtypes.Header {
+	ancestors := make([]*gethtypes.Header, 0, len(headers))
+	hash := parentHash
+	for _, header := range headers {
+		if header == nil {
+			ancestors = append(ancestors, header)
+			hash = gethcommon.Hash{}
+			continue
+		}
+
+		cached, ok := c.headers.Get(headerCacheKey{chainID.String(), hash})
+		if !ok {
+			w := &recordingWriter{KeyValueWriter: db}
+			rawdb.WriteHeader(w, header)
+			cached = &cachedHeader{header: header, writes: w.writes}
+			c.headers.Add(headerCacheKey{chainID.String(), header.Hash()}, cached)
+		} else {
+			replayWrites(db, cached.writes)
+		}
+
+		ancestors = append(ancestors, cached.header)
+		hash = cached.header.ParentHash
+	}
+	return ancestors
+}
+
+// writeCodes writes the codes into the database
+func (c *Cache) writeCodes(db ethdb.KeyValueWriter, codes ...[]byte) {
+	for _, code := range codes {
+		if writes, ok := c.codes.Get(string(code)); ok {
+			replayWrites(db, writes)
+			continue
+		}
+
+		w := &recordingWriter{KeyValueWriter: db}
+		rawdb.WriteCode(w, crypto.Keccak256Hash(code), code)
+		c.codes.Add(string(code), w.writes)
+	}
+}
+
+func replayWrites(db ethdb.KeyValueWriter, writes []dbWrite) {
+	//nolint:errcheck // Can't fail
+	for _, write := range writes {
+		db.Put(write.key, write.value)
+	}
+}
+
+// recordingWriter records the writes made to the underlying writer
+type recordingWriter struct {
+	ethdb.KeyValueWriter
+	writes []dbWrite
+}
+
+func (w *recordingWriter) Put(key, value []byte) error {
+	w.writes = append(w.writes, dbWrite{key: gethcommon.CopyBytes(key), value: gethcommon.CopyBytes(value)})
+	return w.KeyValueWriter.Put(key, value)
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithCache(t *testing.T) {
+	// Chains with the same genesis but different chain IDs share the genesis header hash
+	cfgA := *testChainConfig
+	cfgA.ChainID = big.NewInt(1001)
+	cfgB := *testChainConfig
+	cfgB.ChainID = big.NewInt(1002)
+
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	inputA := generateTestProverInput(t, &cfgA, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, &cfgA, &testContractAddr, nil, 100_000, nil))
+	})
+	inputB := generateTestProverInput(t, &cfgB, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, &cfgB, &testContractAddr, nil, 100_000, nil))
+	})
+	genesisHash := inputA.Blocks[0].Header.ParentHash
+	require.Equal(t, genesisHash, inputB.Blocks[0].Header.ParentHash)
+
+	cache := NewCache(0)
+	for i := 0; i < 2; i++ {
+		_, err := NewExecutor(WithCache(cache)).Execute(context.Background(), inputA)
+		require.NoError(t, err)
+		_, err = NewExecutor(WithCache(cache)).Execute(context.Background(), inputB)
+		require.NoError(t, err)
+	}
+
+	// Headers are cached per chain, codes are shared
+	assert.True(t, cache.headers.Contains(headerCacheKey{cfgA.ChainID.String(), genesisHash}))
+	assert.True(t, cache.headers.Contains(headerCacheKey{cfgB.ChainID.String(), genesisHash}))
+	assert.Equal(t, 2, cache.headers.Len())
+	assert.Equal(t, 1, cache.codes.Len())
+
+	// A header cached for a chain is not used on another chain
+	corrupted := *inputB.Witness.Ancestors[0]
+	corrupted.Root = gethcommon.HexToHash("0xbad")
+	cache.headers.Add(headerCacheKey{cfgB.ChainID.String(), genesisHash}, &cachedHeader{header: &corrupted})
+
+	_, err := NewExecutor(WithCache(cache)).Execute(context.Background(), inputA)
+	require.NoError(t, err)
+	_, err = NewExecutor(WithCache(cache)).Execute(context.Background(), inputB)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-state root mismatch")
+}
+
+// BenchmarkExecutorWithCache benchmarks the pre-state preparation of overlapping blocks sharing their ancestors and codes
+func BenchmarkExecutorWithCache(b *testing.B) {
+	const n = 20
+
+	// Large contracts provided in the witness of every block
+	alloc := make(gethtypes.GenesisAlloc)
+	codes := make([]hexutil.Bytes, 0)
+	for i := 0; i < 10; i++ {
+		code := append([]byte{byte(vm.STOP)}, crypto.Keccak256(big.NewInt(int64(i)).Bytes())...)
+		for len(code) < 24_000 {
+			code = append(code, code...)
+		}
+		alloc[gethcommon.BigToAddress(big.NewInt(int64(0xc0de00+i)))] = gethtypes.Account{Code: code}
+		codes = append(codes, code)
+	}
+
+	inputs := generateTestProverInputs(b, testChainConfig, alloc, n, func(_ int, _ *core.BlockGen) {})
+
+	// Each block has every previous block as ancestors
+	ancestors := []*gethtypes.Header{inputs[0].Witness.Ancestors[0]}
+	for i, proverInput := range inputs {
+		proverInput.Witness.Ancestors = ancestors
+		proverInput.Witness.Codes = append(proverInput.Witness.Codes, codes...)
+		if i > 0 {
+			proverInput.Witness.Ancestors = append([]*gethtypes.Header{inputs[i-1].Blocks[0].Header}, proverInput.Witness.Ancestors...)
+		}
+		ancestors = proverInput.Witness.Ancestors
+	}
+
+	// prepare times the pre-state preparation of every block, excluding the context creation
+	prepare := func(b *testing.B, e *executor, inputs []*input.ProverInput) {
+		for _, proverInput := range inputs {
+			b.StopTimer()
+			execCtx, err := e.prepareContext(context.Background(), proverInput)
+			require.NoError(b, err)
+			b.StartTimer()
+			require.NoError(b, e.preparePreState(execCtx, proverInput))
+		}
+	}
+
+	b.Run("without cache", func(b *testing.B) {
+		e := NewExecutor().(*executor)
+		for i := 0; i < b.N; i++ {
+			prepare(b, e, inputs)
+		}
+	})
+
+	b.Run("with cache", func(b *testing.B) {
+		e := NewExecutor(WithCache(NewCache(0))).(*executor)
+		for i := 0; i < b.N; i++ {
+			prepare(b, e, inputs)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// ProcessAndCanonicalize validates a raw JSON prover input by execution and returns its canonical serialization along with the execution result
+// The canonical input is migrated to the current schema version, its witness is minimized to the state nodes, codes and ancestors consumed by
+// the execution, state nodes and codes are sorted and deduplicated, ancestors are inlined and sorted from the parent backwards,
+// and its checksum is embedded. When the execution records no witness (e.g. before Byzantium or without prefetching), the witness is normalized but not minimized.
+func ProcessAndCanonicalize(ctx context.Context, raw []byte, opts ...ExecutorOption) ([]byte, *Result, error) {
+	var pi input.ProverInput
+	if err := json.Unmarshal(raw, &pi); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode prover input: %v", err)
+	}
+	if pi.Witness == nil {
+		return nil, nil, fmt.Errorf("prover input has no witness")
+	}
+
+	e := NewExecutor(opts...).(*executor)
+	if e.noGlobalMetrics {
+		return nil, nil, fmt.Errorf("canonicalization requires the execution witness, which is not recorded without global metrics")
+	}
+	res, err := e.Execute(ctx, &pi)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !res.Validated {
+		return nil, nil, fmt.Errorf("canonicalization failed: execution result not validated")
+	}
+
+	migrated, err := input.Migrate(&pi)
+	if err != nil {
+		return nil, nil, err
+	}
+	ancestors, err := migrated.Witness.LoadAncestors()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load ancestors: %v", err)
+	}
+
+	witness := &input.Witness{
+		State:     migrated.Witness.State,
+		Ancestors: ancestors,
+		Codes:     migrated.Witness.Codes,
+	}
+	if res.witness != nil {
+		// Only items of the input witness are kept, so the canonical witness is a subset of the input one
+		witness.State = consumedItems(witness.State, res.witness.State)
+		witness.Codes = consumedItems(witness.Codes, res.witness.Codes)
+		witness.Ancestors = res.witness.Headers
+	}
+	witness.State = sortedUniqueItems(witness.State)
+	witness.Codes = sortedUniqueItems(witness.Codes)
+	if witness.Ancestors, err = input.SortAncestors(witness.Ancestors); err != nil {
+		return nil, nil, err
+	}
+
+	canonical := &input.ProverInput{
+		Version:     migrated.Version,
+		Blocks:      migrated.Blocks,
+		Witness:     witness,
+		ChainConfig: migrated.ChainConfig,
+	}
+	if canonical, err = input.WithChecksum(canonical); err != nil {
+		return nil, nil, err
+	}
+
+	b, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize canonical prover input: %v", err)
+	}
+	return b, res, nil
+}
+
+func consumedItems(items []hexutil.Bytes, consumed map[string]struct{}) []hexutil.Bytes {
+	kept := make([]hexutil.Bytes, 0, len(consumed))
+	for _, item := range items {
+		if _, ok := consumed[string(item)]; ok {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func sortedUniqueItems(items []hexutil.Bytes) []hexutil.Bytes {
+	sorted := make([]hexutil.Bytes, len(items))
+	copy(sorted, items)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+
+	unique := sorted[:0]
+	for i, item := range sorted {
+		if i == 0 || !bytes.Equal(item, sorted[i-1]) {
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessAndCanonicalize(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	inputs := generateTestProverInputs(t, testChainConfig, alloc, 2, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	// Pad the witness with duplicated items and the stale nodes of the previous block witness
+	proverInput := inputs[1]
+	proverInput.Witness.State = append(append(proverInput.Witness.State, inputs[0].Witness.State...), proverInput.Witness.State[0])
+	proverInput.Witness.Codes = append(proverInput.Witness.Codes, proverInput.Witness.Codes...)
+	raw, err := json.Marshal(proverInput)
+	require.NoError(t, err)
+
+	canonical, res, err := ProcessAndCanonicalize(context.Background(), raw)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+	assert.Less(t, len(canonical), len(raw))
+
+	// The canonical input is a fixed point of the canonicalization
+	again, _, err := ProcessAndCanonicalize(context.Background(), canonical)
+	require.NoError(t, err)
+	assert.Equal(t, string(canonical), string(again))
+
+	// The canonical input re-validates
+	var pi input.ProverInput
+	require.NoError(t, json.Unmarshal(canonical, &pi))
+	require.NoError(t, input.VerifyChecksum(&pi))
+	assert.Equal(t, "1", pi.Version)
+	assert.Len(t, pi.Witness.Codes, 1)
+	assert.Less(t, len(pi.Witness.State), len(proverInput.Witness.State))
+
+	revalidated, err := NewExecutor().Execute(context.Background(), &pi)
+	require.NoError(t, err)
+	assert.True(t, revalidated.Validated)
+	assert.Equal(t, res.Fingerprint(), revalidated.Fingerprint())
+
+	// Witness is not recorded without global metrics
+	_, _, err = ProcessAndCanonicalize(context.Background(), raw, WithoutGlobalMetrics())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "canonicalization requires the execution witness")
+
+	_, _, err = ProcessAndCanonicalize(context.Background(), raw[:len(raw)/2])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode prover input")
+}
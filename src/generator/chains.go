@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -21,3 +22,12 @@ func getChainConfig(chainID *big.Int) (*params.ChainConfig, error) {
 	}
 	return cfg, nil
 }
+
+// OPSequencerFeeVault is the OP Stack predeploy collecting the sequencer fees, set as coinbase of every OP Stack block
+var OPSequencerFeeVault = gethcommon.HexToAddress("0x4200000000000000000000000000000000000011")
+
+// FeeRecipients are the expected fee recipients of L2s routing fees to a protocol address rather than to a block producer, by chain ID
+var FeeRecipients = map[string]gethcommon.Address{
+	"10":   OPSequencerFeeVault, // OP Mainnet
+	"8453": OPSequencerFeeVault, // Base
+}
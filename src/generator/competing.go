@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// CompetingResults are the execution results of two competing blocks at the same height (e.g. the two sides of a reorg)
+type CompetingResults struct {
+	A *Result
+	B *Result
+}
+
+// ExecuteCompeting executes two competing blocks against the same parent pre-state, given by the parent prover input witness and chain configuration
+// The parent witness must cover the state accessed by both blocks (e.g. the union of the witnesses of both blocks), the parent input blocks are ignored.
+func ExecuteCompeting(ctx context.Context, parent *input.ProverInput, blockA, blockB *input.Block, opts ...ExecutorOption) (*CompetingResults, error) {
+	if blockA.Header.ParentHash != blockB.Header.ParentHash {
+		return nil, fmt.Errorf("competing blocks must share the same parent (got %v and %v)", blockA.Header.ParentHash.Hex(), blockB.Header.ParentHash.Hex())
+	}
+	if blockA.Header.Number.Cmp(blockB.Header.Number) != 0 {
+		return nil, fmt.Errorf("competing blocks must be at the same height (got %v and %v)", blockA.Header.Number, blockB.Header.Number)
+	}
+
+	executor := NewExecutor(opts...)
+	execute := func(block *input.Block) (*Result, error) {
+		pi := *parent
+		pi.Blocks = []*input.Block{block}
+		pi.Checksum = nil
+		return executor.Execute(ctx, &pi)
+	}
+
+	a, err := execute(blockA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute block A (%v): %v", blockA.Header.Hash().Hex(), err)
+	}
+	b, err := execute(blockB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute block B (%v): %v", blockB.Header.Hash().Hex(), err)
+	}
+
+	return &CompetingResults{A: a, B: b}, nil
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCompeting(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	inputA := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 100_000, nil))
+	})
+	inputB := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testAddr, big.NewInt(1), params.TxGas, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(2), params.TxGas, nil))
+	})
+	require.Equal(t, inputA.Blocks[0].Header.ParentHash, inputB.Blocks[0].Header.ParentHash)
+
+	// The parent witness covers the state accessed by both blocks
+	parent := &input.ProverInput{
+		ChainConfig: inputA.ChainConfig,
+		Witness: &input.Witness{
+			State:     append(append([]hexutil.Bytes(nil), inputA.Witness.State...), inputB.Witness.State...),
+			Ancestors: inputA.Witness.Ancestors,
+			Codes:     append(append([]hexutil.Bytes(nil), inputA.Witness.Codes...), inputB.Witness.Codes...),
+		},
+	}
+
+	res, err := ExecuteCompeting(context.Background(), parent, inputA.Blocks[0], inputB.Blocks[0])
+	require.NoError(t, err)
+	assert.True(t, res.A.Validated)
+	assert.True(t, res.B.Validated)
+	assert.Equal(t, inputA.Blocks[0].Header.Root, res.A.Attestation().PostStateRoot)
+	assert.Equal(t, inputB.Blocks[0].Header.Root, res.B.Attestation().PostStateRoot)
+	assert.NotEqual(t, res.A.Attestation().PostStateRoot, res.B.Attestation().PostStateRoot)
+	assert.NotEqual(t, res.A.ProcessResult.GasUsed, res.B.ProcessResult.GasUsed)
+
+	// Blocks of different parents do not compete
+	inputC := generateTestProverInputs(t, testChainConfig, alloc, 2, func(int, *core.BlockGen) {})[1]
+	_, err = ExecuteCompeting(context.Background(), parent, inputA.Blocks[0], inputC.Blocks[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "competing blocks must share the same parent")
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/kkrt-labs/go-utils/log"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// CrossStateInput returns a prover input executing the transactions and withdrawals of block against the pre-state of base, ignoring chain continuity
+// The header is the base header with the transactions and withdrawals roots of block, so the block executes in the base block environment
+// (number, timestamp, base fee, coinbase...). Witnesses of both inputs are combined, so the state accessed by block is available wherever
+// it is unchanged between both pre-states.
+func CrossStateInput(base, block *input.ProverInput) (*input.ProverInput, error) {
+	if len(base.Blocks) == 0 || base.Witness == nil || len(block.Blocks) == 0 || block.Witness == nil {
+		return nil, fmt.Errorf("invalid prover inputs: missing block or witness")
+	}
+	baseBlock, txBlock := base.Blocks[0], block.Blocks[0]
+
+	header := gethtypes.CopyHeader(baseBlock.Header)
+	header.TxHash = gethtypes.DeriveSha(gethtypes.Transactions(txBlock.Transactions), gethtrie.NewStackTrie(nil))
+	if header.WithdrawalsHash != nil {
+		hash := gethtypes.DeriveSha(gethtypes.Withdrawals(txBlock.Withdrawals), gethtrie.NewStackTrie(nil))
+		header.WithdrawalsHash = &hash
+	}
+
+	// The base witness is copied so its ancestors (and ancestors file resolution) are kept
+	witness := *base.Witness
+	witness.State = mergeWitnessBlobs(base.Witness.State, block.Witness.State)
+	witness.Codes = mergeWitnessBlobs(base.Witness.Codes, block.Witness.Codes)
+
+	return &input.ProverInput{
+		Version:     base.Version,
+		ChainConfig: base.ChainConfig,
+		Blocks: []*input.Block{
+			{
+				Header:       header,
+				Transactions: txBlock.Transactions,
+				Uncles:       baseBlock.Uncles,
+				Withdrawals:  txBlock.Withdrawals,
+			},
+		},
+		Witness: &witness,
+	}, nil
+}
+
+// ExecuteCrossState executes the transactions and withdrawals of block against the pre-state of base (see CrossStateInput)
+// It is meant for what-if analysis: validation is disabled as the result does not correspond to any canonical block.
+func ExecuteCrossState(ctx context.Context, base, block *input.ProverInput, opts ...ExecutorOption) (*Result, error) {
+	pi, err := CrossStateInput(base, block)
+	if err != nil {
+		return nil, err
+	}
+
+	log.LoggerFromContext(ctx).Warn(
+		"Executing block transactions against the state of another block, results are non-canonical",
+		zap.Uint64("block.number", block.Blocks[0].Header.Number.Uint64()),
+		zap.Uint64("base.number", base.Blocks[0].Header.Number.Uint64()),
+	)
+
+	return NewExecutor(append(opts, WithDryRun())...).Execute(ctx, pi)
+}
+
+// mergeWitnessBlobs returns the blobs of both lists deduplicated by hash
+func mergeWitnessBlobs(a, b []hexutil.Bytes) []hexutil.Bytes {
+	seen := make(map[gethcommon.Hash]struct{}, len(a)+len(b))
+	merged := make([]hexutil.Bytes, 0, len(a)+len(b))
+	for _, blobs := range [][]hexutil.Bytes{a, b} {
+		for _, blob := range blobs {
+			hash := crypto.Keccak256Hash(blob)
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			merged = append(merged, blob)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCrossState(t *testing.T) {
+	// Contract incrementing storage slot 0 (PUSH1 0x00 SLOAD PUSH1 0x01 ADD PUSH1 0x00 SSTORE STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+		}},
+	}
+	callContract := func(t *testing.T, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	}
+
+	// The base chain leaves the contract slot untouched before its second block, the other chain sets it in its first block
+	base := generateTestProverInputs(t, testChainConfig, alloc, 2, func(i int, b *core.BlockGen) {
+		if i == 0 {
+			b.AddTx(signTestTx(t, b, testChainConfig, &gethcommon.Address{0xde, 0xad}, big.NewInt(1), params.TxGas, nil))
+			return
+		}
+		callContract(t, b)
+	})[1]
+	other := generateTestProverInputs(t, testChainConfig, alloc, 2, func(_ int, b *core.BlockGen) { callContract(t, b) })[1]
+
+	res, err := ExecuteCrossState(context.Background(), base, other)
+	require.NoError(t, err)
+	assert.False(t, res.Validated)
+	require.Len(t, res.ProcessResult.Receipts, 1)
+	assert.Equal(t, gethtypes.ReceiptStatusSuccessful, res.ProcessResult.Receipts[0].Status)
+
+	// The transaction writes a fresh slot on the base state, unlike on its own block state
+	assert.Equal(t, base.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+	assert.NotEqual(t, other.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+}
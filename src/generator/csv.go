@@ -0,0 +1,51 @@
+package generator
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVHeader is the header of the CSV summary of a manifest run
+var CSVHeader = []string{"block_number", "status", "gas_used", "tx_count", "duration_ms", "witness_size", "error"}
+
+// WriteCSV writes the manifest results as a CSV summary with one row per result, for spreadsheet analysis
+// Metrics unknown for failed entries (e.g. the gas used of an input that failed to load) are left empty.
+func WriteCSV(w io.Writer, results []*ManifestResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVHeader); err != nil {
+		return fmt.Errorf("failed to write csv header: %v", err)
+	}
+	for _, res := range results {
+		if err := writer.Write(csvRecord(res)); err != nil {
+			return fmt.Errorf("failed to write csv row: %v", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv writer: %v", err)
+	}
+	return nil
+}
+
+func csvRecord(res *ManifestResult) []string {
+	row := NewBlockResultRow(res)
+
+	// The block is unknown if the input failed to load or verify
+	var blockNumber, txCount string
+	if res.Err == nil || res.BlockNumber != 0 {
+		blockNumber = strconv.FormatUint(row.BlockNumber, 10)
+		txCount = strconv.FormatInt(row.TxCount, 10)
+	}
+	var gasUsed, witnessSize string
+	if res.Result != nil {
+		if res.Result.ProcessResult != nil {
+			gasUsed = strconv.FormatUint(row.GasUsed, 10)
+		}
+		witnessSize = strconv.FormatUint(row.WitnessSize, 10)
+	}
+	duration := strconv.FormatFloat(row.LoadDurationMs+row.ExecDurationMs, 'f', -1, 64)
+
+	return []string{blockNumber, row.Status, gasUsed, txCount, duration, witnessSize, row.Error}
+}
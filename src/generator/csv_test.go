@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	results := []*ManifestResult{
+		{
+			Line:         1,
+			BlockNumber:  21465322,
+			TxCount:      3,
+			LoadDuration: 1500 * time.Microsecond,
+			ExecDuration: 20 * time.Millisecond,
+			Result: &Result{
+				ProcessResult: &core.ProcessResult{GasUsed: 63_000},
+				WitnessSize:   12_600,
+			},
+		},
+		{
+			Line:         2,
+			BlockNumber:  21465323,
+			TxCount:      1,
+			LoadDuration: time.Millisecond,
+			ExecDuration: time.Millisecond,
+			Err:          fmt.Errorf("invalid ancestors"),
+		},
+		{
+			Line: 3,
+			Err:  fmt.Errorf("failed to open prover input"),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, results))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(results)+1)
+	assert.Equal(t, CSVHeader, records[0])
+	assert.Equal(t, []string{"21465322", BlockStatusSucceeded, "63000", "3", "21.5", "12600", ""}, records[1])
+	assert.Equal(t, []string{"21465323", BlockStatusFailed, "", "1", "2", "", "invalid ancestors"}, records[2])
+	assert.Equal(t, []string{"", BlockStatusFailed, "", "", "0", "", "failed to open prover input"}, records[3])
+}
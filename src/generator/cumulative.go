@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/go-utils/tag"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// DefaultCumulativeMaxBlock is the default highest block a genesis-to-block cumulative input can be generated for
+const DefaultCumulativeMaxBlock = 1024
+
+type cumulativeConfig struct {
+	maxBlock uint64
+}
+
+// CumulativeOption is an option for genesis-to-block cumulative input generation
+type CumulativeOption func(*cumulativeConfig)
+
+// WithCumulativeMaxBlock sets the highest block a cumulative input can be generated for (defaults to DefaultCumulativeMaxBlock)
+// Every block from genesis is replayed, so the generation cost grows with the block height.
+func WithCumulativeMaxBlock(n uint64) CumulativeOption {
+	return func(cfg *cumulativeConfig) {
+		cfg.maxBlock = n
+	}
+}
+
+// GenerateCumulativeInput generates the prover input of the block with the given number starting from the genesis state
+// The blocks from 1 up to the target block are imported and validated on an in-memory archive chain initialized from genesis,
+// the target block is then executed on the resulting state to record its witness. It is meant for shallow chains (e.g. testnets)
+// whose state is not available from a node.
+func GenerateCumulativeInput(ctx context.Context, genesis *core.Genesis, blocks []*gethtypes.Block, blockNumber *big.Int, opts ...CumulativeOption) (*input.ProverInput, error) {
+	ctx = tag.WithComponent(ctx, "cumulative")
+	ctx = tag.WithTags(ctx, tag.Key("block.number").Int64(blockNumber.Int64()))
+
+	proverInput, err := generateCumulativeInput(ctx, genesis, blocks, blockNumber, opts...)
+	if err != nil {
+		log.LoggerFromContext(ctx).Error("Cumulative prover input generation failed", zap.Error(err))
+		return nil, err
+	}
+	log.LoggerFromContext(ctx).Info("Cumulative prover input generation succeeded")
+
+	return proverInput, nil
+}
+
+func generateCumulativeInput(ctx context.Context, genesis *core.Genesis, blocks []*gethtypes.Block, blockNumber *big.Int, opts ...CumulativeOption) (*input.ProverInput, error) {
+	cfg := &cumulativeConfig{maxBlock: DefaultCumulativeMaxBlock}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	number := blockNumber.Uint64()
+	switch {
+	case number == 0:
+		return nil, fmt.Errorf("can not generate prover input for genesis block")
+	case number > cfg.maxBlock:
+		return nil, fmt.Errorf("block %d exceeds the cumulative input maximum block %d", number, cfg.maxBlock)
+	case uint64(len(blocks)) < number:
+		return nil, fmt.Errorf("block %d not found (%d block(s) provided)", number, len(blocks))
+	}
+	for i, block := range blocks[:number] {
+		if block.NumberU64() != uint64(i+1) {
+			return nil, fmt.Errorf("blocks must be contiguous from block 1: got block %d at position %d", block.NumberU64(), i)
+		}
+	}
+
+	// The chain is replayed in archive mode, so the state of every imported block is committed to the database
+	db := rawdb.NewMemoryDatabase()
+	engine, err := ethconfig.CreateConsensusEngine(genesis.Config, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consensus engine: %v", err)
+	}
+	cacheCfg := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheCfg.TrieDirtyDisabled = true
+	cacheCfg.SnapshotLimit = 0
+	chain, err := core.NewBlockChain(db, cacheCfg, genesis, nil, engine, vm.Config{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize chain from genesis: %v", err)
+	}
+	defer chain.Stop()
+
+	if n, err := chain.InsertChain(blocks[:number]); err != nil {
+		return nil, fmt.Errorf("failed to import block %d: %v", blocks[n].NumberU64(), err)
+	}
+
+	g, err := newDatadirGenerator(db)
+	if err != nil {
+		return nil, err
+	}
+	return g.generate(ctx, blockNumber)
+}
@@ -0,0 +1,42 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCumulativeInput(t *testing.T) {
+	genesis := testGenesis(testChainConfig, gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}})
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, beacon.New(ethash.NewFaker()), 6, func(_ int, b *core.BlockGen) {
+		b.SetPoS()
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	proverInput, err := GenerateCumulativeInput(context.Background(), genesis, blocks, big.NewInt(5))
+	require.NoError(t, err)
+	assert.Equal(t, blocks[4].Hash(), proverInput.Blocks[0].Header.Hash())
+	assert.Equal(t, blocks[3].Root(), proverInput.Witness.Ancestors[0].Root)
+	assert.NotEmpty(t, proverInput.Witness.State)
+	assert.NotEmpty(t, proverInput.Witness.Codes)
+
+	// Generated prover input executes statelessly
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+
+	_, err = GenerateCumulativeInput(context.Background(), genesis, blocks, big.NewInt(5), WithCumulativeMaxBlock(4))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block 5 exceeds the cumulative input maximum block 4")
+
+	_, err = GenerateCumulativeInput(context.Background(), genesis, blocks, big.NewInt(7))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block 7 not found")
+}
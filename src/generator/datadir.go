@@ -0,0 +1,262 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/go-utils/tag"
+	"github.com/kkrt-labs/zk-pig/src/ethereum"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// DatadirGenerator generates prover inputs by reading the chain data and the state trie directly from a Geth chaindata directory
+// It is much faster than generating prover inputs over RPC, as no state proofs need to be fetched.
+type DatadirGenerator interface {
+	// Generate generates the prover input of the block with the given number
+	Generate(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error)
+
+	// VerifyWitness re-derives the minimal witness of the prover input block from the full state and compares it against the prover input witness
+	VerifyWitness(ctx context.Context, proverInput *input.ProverInput) (*WitnessDiff, error)
+
+	// Close closes the chaindata database
+	Close() error
+}
+
+type datadirGenerator struct {
+	db         ethdb.Database
+	trieDB     *triedb.Database
+	hc         *core.HeaderChain
+	scheme     string
+	targetFork *params.Rules
+}
+
+// DatadirOption is an option for the datadir generator
+type DatadirOption func(*datadirGenerator)
+
+// WithDatadirTargetFork also executes blocks under the rules of a future fork, so the witness covers the state the future fork accesses
+// (e.g. new system contracts storage) and generated prover inputs can be executed under both the current and the future fork.
+func WithDatadirTargetFork(rules *params.Rules) DatadirOption {
+	return func(g *datadirGenerator) {
+		g.targetFork = rules
+	}
+}
+
+// OpenDatadir opens a Geth chaindata directory (e.g. <datadir>/geth/chaindata) in read-only mode
+// Both hash based (hashdb) and path based (pathdb) state schemes are supported. With pathdb, only the states retained by the node
+// (the most recent ones) are available.
+//
+// Both LevelDB and Pebble (Geth default) databases are supported, the database type being detected from the directory content.
+//
+// The database is opened read-only, so nothing is ever written to it. As Geth holds an exclusive lock on its database,
+// opening the chaindata of a running node fails rather than interfering with it.
+func OpenDatadir(path string, opts ...DatadirOption) (DatadirGenerator, error) {
+	var (
+		kvdb ethdb.KeyValueStore
+		err  error
+	)
+	if isPebbleDB(path) {
+		kvdb, err = pebble.New(path, 16, 16, "", true)
+	} else {
+		kvdb, err = leveldb.New(path, 16, 16, "", true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chaindata database: %v", err)
+	}
+
+	var db ethdb.Database
+	if ancient := filepath.Join(path, "ancient"); dirExists(ancient) {
+		db, err = rawdb.NewDatabaseWithFreezer(kvdb, ancient, "", true)
+		if err != nil {
+			kvdb.Close()
+			return nil, fmt.Errorf("failed to open ancient store: %v", err)
+		}
+	} else {
+		db = rawdb.NewDatabase(kvdb)
+	}
+
+	g, err := newDatadirGenerator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+func newDatadirGenerator(db ethdb.Database) (*datadirGenerator, error) {
+	genesisHash := rawdb.ReadCanonicalHash(db, 0)
+	chainCfg := rawdb.ReadChainConfig(db, genesisHash)
+	if chainCfg == nil {
+		return nil, fmt.Errorf("missing chain configuration for genesis %v", genesisHash.Hex())
+	}
+
+	scheme := rawdb.ReadStateScheme(db)
+	var trieDB *triedb.Database
+	switch scheme {
+	case rawdb.HashScheme:
+		trieDB = triedb.NewDatabase(db, &triedb.Config{HashDB: hashdb.Defaults})
+	case rawdb.PathScheme:
+		trieDB = triedb.NewDatabase(db, &triedb.Config{PathDB: pathdb.ReadOnly})
+	default:
+		return nil, fmt.Errorf("no state found in chaindata database")
+	}
+
+	engine, err := ethconfig.CreateConsensusEngine(chainCfg, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consensus engine: %v", err)
+	}
+
+	hc, err := core.NewHeaderChain(db, chainCfg, engine, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create header chain: %v", err)
+	}
+
+	return &datadirGenerator{
+		db:     db,
+		trieDB: trieDB,
+		hc:     hc,
+		scheme: scheme,
+	}, nil
+}
+
+// Generate generates the prover input of the block with the given number, by executing the block on the state stored in the database
+func (g *datadirGenerator) Generate(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error) {
+	ctx = tag.WithComponent(ctx, "datadir")
+	ctx = tag.WithTags(
+		ctx,
+		tag.Key("chain.id").String(g.hc.Config().ChainID.String()),
+		tag.Key("block.number").Int64(blockNumber.Int64()),
+		tag.Key("state.scheme").String(g.scheme),
+	)
+
+	proverInput, err := g.generate(ctx, blockNumber)
+	if err != nil {
+		log.LoggerFromContext(ctx).Error("Prover input generation from datadir failed", zap.Error(err))
+		return nil, err
+	}
+	log.LoggerFromContext(ctx).Info("Prover input generation from datadir succeeded")
+
+	return proverInput, nil
+}
+
+func (g *datadirGenerator) generate(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error) {
+	number := blockNumber.Uint64()
+	if number == 0 {
+		return nil, fmt.Errorf("can not generate prover input for genesis block")
+	}
+
+	hash := rawdb.ReadCanonicalHash(g.db, number)
+	block := rawdb.ReadBlock(g.db, hash, number)
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+
+	parentHeader := g.hc.GetHeader(block.ParentHash(), number-1)
+	if parentHeader == nil {
+		return nil, fmt.Errorf("parent header %v not found", block.ParentHash().Hex())
+	}
+
+	preState, err := gethstate.New(parentHeader.Root, gethstate.NewDatabase(g.trieDB, nil))
+	if err != nil {
+		return nil, fmt.Errorf("state of parent block %d not available (state root %v): %v", number-1, parentHeader.Root.Hex(), err)
+	}
+
+	// The block is executed with stateless self validation, so the state accessed during execution is recorded into a witness
+	execParams := &evm.ExecParams{
+		VMConfig: &vm.Config{
+			StatelessSelfValidation: true,
+		},
+		Block:    block,
+		Validate: true, // We validate the block execution to ensure the result and final state are correct
+		Chain:    g.hc,
+		State:    preState,
+	}
+	if _, err := evm.ExecutorWithTags("evm")(evm.ExecutorWithLog()(evm.NewExecutor())).Execute(ctx, execParams); err != nil {
+		return nil, fmt.Errorf("failed to execute block: %v", err)
+	}
+
+	if g.targetFork != nil {
+		targetCfg, err := ethereum.ChainConfigWithRules(g.hc.Config(), g.targetFork)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target fork: %v", err)
+		}
+		hc, err := core.NewHeaderChain(g.db, targetCfg, g.hc.Engine(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create target fork chain: %v", err)
+		}
+		witness, err := executeTargetFork(ctx, hc, gethstate.NewDatabase(g.trieDB, nil), block, parentHeader.Root)
+		if err != nil {
+			return nil, err
+		}
+		mergeWitness(preState.Witness(), witness)
+	}
+
+	return new(preparer).prepareProverInput(nil, execParams), nil
+}
+
+// VerifyWitness re-derives the witness of the prover input block by executing it on the full state stored in the database
+// and returns its difference with the prover input witness
+func (g *datadirGenerator) VerifyWitness(ctx context.Context, proverInput *input.ProverInput) (*WitnessDiff, error) {
+	if len(proverInput.Blocks) == 0 || proverInput.Witness == nil {
+		return nil, fmt.Errorf("invalid prover input: missing block or witness")
+	}
+	header := proverInput.Blocks[0].Header
+
+	derived, err := g.generate(tag.WithComponent(ctx, "datadir"), header.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-derive witness: %v", err)
+	}
+	if hash := derived.Blocks[0].Header.Hash(); hash != header.Hash() {
+		return nil, fmt.Errorf("prover input block %v does not match canonical block %v", header.Hash().Hex(), hash.Hex())
+	}
+
+	return DiffWitness(derived.Witness, proverInput.Witness)
+}
+
+// Close closes the chaindata database
+func (g *datadirGenerator) Close() error {
+	if err := g.trieDB.Close(); err != nil {
+		return err
+	}
+	return g.db.Close()
+}
+
+// isPebbleDB returns whether the directory holds a Pebble database (which stores its options in OPTIONS-* files)
+func isPebbleDB(path string) bool {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "OPTIONS-") {
+			return true
+		}
+	}
+	return false
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
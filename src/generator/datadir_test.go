@@ -0,0 +1,168 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/ethdb/pebble"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDatadir writes a small chain into a LevelDB chaindata directory with the given state scheme
+func newTestDatadir(t *testing.T, scheme string, n int) string {
+	return newTestDatadirWithDB(t, rawdb.DBLeveldb, scheme, n)
+}
+
+// newTestDatadirWithDB writes a small chain into a chaindata directory with the given database type (leveldb or pebble) and state scheme
+func newTestDatadirWithDB(t *testing.T, dbType, scheme string, n int) string {
+	dir := filepath.Join(t.TempDir(), "chaindata")
+	var (
+		kvdb ethdb.KeyValueStore
+		err  error
+	)
+	if dbType == rawdb.DBPebble {
+		kvdb, err = pebble.New(dir, 16, 16, "", false)
+	} else {
+		kvdb, err = leveldb.New(dir, 16, 16, "", false)
+	}
+	require.NoError(t, err)
+	db, err := rawdb.NewDatabaseWithFreezer(kvdb, filepath.Join(dir, "ancient"), "", false)
+	require.NoError(t, err)
+
+	// Contract incrementing storage slot 0 (PUSH1 0x00 SLOAD PUSH1 0x01 ADD PUSH1 0x00 SSTORE STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+		}},
+		// EIP-2935 history storage contract, so blocks can also be executed under Prague
+		params.HistoryStorageAddress: {Code: params.HistoryStorageCode},
+	}
+	genesis := testGenesis(testChainConfig, alloc)
+	engine := beacon.New(ethash.NewFaker())
+
+	cacheCfg := core.DefaultCacheConfigWithScheme(scheme)
+	cacheCfg.SnapshotLimit = 0
+	chain, err := core.NewBlockChain(db, cacheCfg, genesis, nil, engine, vm.Config{}, nil)
+	require.NoError(t, err)
+
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, n, func(_ int, b *core.BlockGen) {
+		b.SetPoS()
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+	_, err = chain.InsertChain(blocks)
+	require.NoError(t, err)
+
+	chain.Stop()
+	require.NoError(t, db.Close())
+
+	return dir
+}
+
+func TestDatadirGenerator(t *testing.T) {
+	for _, scheme := range []string{rawdb.HashScheme, rawdb.PathScheme} {
+		t.Run(scheme, func(t *testing.T) {
+			dir := newTestDatadir(t, scheme, 3)
+
+			g, err := OpenDatadir(dir)
+			require.NoError(t, err)
+			defer g.Close()
+
+			proverInput, err := g.Generate(context.Background(), big.NewInt(3))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(3), proverInput.Blocks[0].Header.Number.Uint64())
+			assert.NotEmpty(t, proverInput.Witness.State)
+			assert.NotEmpty(t, proverInput.Witness.Codes)
+
+			// Generated prover input executes statelessly
+			_, err = NewExecutor().Execute(context.Background(), proverInput)
+			require.NoError(t, err)
+
+			_, err = g.Generate(context.Background(), big.NewInt(4))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "block 4 not found")
+		})
+	}
+}
+
+func TestDatadirGeneratorPebble(t *testing.T) {
+	dir := newTestDatadirWithDB(t, rawdb.DBPebble, rawdb.PathScheme, 2)
+	assert.True(t, isPebbleDB(dir))
+
+	g, err := OpenDatadir(dir)
+	require.NoError(t, err)
+	defer g.Close()
+
+	proverInput, err := g.Generate(context.Background(), big.NewInt(2))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), proverInput.Blocks[0].Header.Number.Uint64())
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+}
+
+func TestDatadirGeneratorVerifyWitness(t *testing.T) {
+	g, err := OpenDatadir(newTestDatadir(t, rawdb.HashScheme, 2))
+	require.NoError(t, err)
+	defer g.Close()
+
+	proverInput, err := g.Generate(context.Background(), big.NewInt(2))
+	require.NoError(t, err)
+
+	diff, err := g.VerifyWitness(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+
+	// Drop a node required by the execution from the witness
+	dropped := proverInput.Witness.State[0]
+	proverInput.Witness.State = proverInput.Witness.State[1:]
+
+	diff, err = g.VerifyWitness(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, []gethcommon.Hash{crypto.Keccak256Hash(dropped)}, diff.MissingState)
+	assert.Empty(t, diff.ExtraState)
+}
+
+func TestDatadirGeneratorTargetFork(t *testing.T) {
+	dir := newTestDatadir(t, rawdb.HashScheme, 2)
+	prague := testChainConfig.Rules(big.NewInt(2), true, 0)
+	prague.IsPrague = true
+
+	g, err := OpenDatadir(dir)
+	require.NoError(t, err)
+	current, err := g.Generate(context.Background(), big.NewInt(2))
+	require.NoError(t, err)
+	require.NoError(t, g.Close())
+
+	g, err = OpenDatadir(dir, WithDatadirTargetFork(&prague))
+	require.NoError(t, err)
+	defer g.Close()
+	forward, err := g.Generate(context.Background(), big.NewInt(2))
+	require.NoError(t, err)
+
+	// The forward compatible witness includes the history storage contract code executed under Prague
+	assert.NotContains(t, current.Witness.Codes, hexutil.Bytes(params.HistoryStorageCode))
+	assert.Contains(t, forward.Witness.Codes, hexutil.Bytes(params.HistoryStorageCode))
+
+	// The forward compatible input executes under both the current fork and the target fork
+	_, err = NewExecutor().Execute(context.Background(), forward)
+	require.NoError(t, err)
+	_, err = NewExecutor(WithRules(&prague)).Execute(context.Background(), forward)
+	require.NoError(t, err)
+}
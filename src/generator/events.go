@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"context"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// EventType is the type of an execution event
+type EventType string
+
+const (
+	EventPhaseStarted  EventType = "phaseStarted"
+	EventPhaseFinished EventType = "phaseFinished"
+	EventTxCompleted   EventType = "txCompleted"
+)
+
+// Event is a structured event emitted during execution (e.g. to report progress)
+type Event struct {
+	Type EventType
+
+	// Phase events
+	Phase    string        // Execution phase (prepareContext, preparePreState, prepareExecParams or execEVM)
+	Duration time.Duration // Duration of the phase (only set on phase finished)
+	Err      error         // Error the phase failed with (only set on phase finished)
+	Nodes    int           // Number of witness state nodes (only set on preparePreState finished)
+	Codes    int           // Number of witness codes (only set on preparePreState finished)
+
+	// Transaction events
+	TxIndex int
+	TxHash  gethcommon.Hash
+	GasUsed uint64
+}
+
+// eventEmitter sends execution events to a channel, it is a no-op if the channel is nil
+type eventEmitter struct {
+	ctx context.Context
+	ch  chan<- *Event
+}
+
+func newEventEmitter(ctx context.Context, ch chan<- *Event) *eventEmitter {
+	return &eventEmitter{ctx: ctx, ch: ch}
+}
+
+// emit blocks until the event is received or the context is done
+func (em *eventEmitter) emit(event *Event) {
+	if em == nil || em.ch == nil {
+		return
+	}
+	select {
+	case em.ch <- event:
+	case <-em.ctx.Done():
+	}
+}
+
+func (em *eventEmitter) phaseStarted(phase string) {
+	em.emit(&Event{Type: EventPhaseStarted, Phase: phase})
+}
+
+func (em *eventEmitter) phaseFinished(phase string, duration time.Duration, err error) {
+	em.emit(&Event{Type: EventPhaseFinished, Phase: phase, Duration: duration, Err: err})
+}
+
+// hooks returns tracing hooks emitting an event for every completed transaction, nil if the emitter is a no-op
+func (em *eventEmitter) hooks() *tracing.Hooks {
+	if em == nil || em.ch == nil {
+		return nil
+	}
+
+	var (
+		index  = -1
+		txHash gethcommon.Hash
+	)
+	return &tracing.Hooks{
+		OnTxStart: func(_ *tracing.VMContext, tx *gethtypes.Transaction, _ gethcommon.Address) {
+			index++
+			txHash = tx.Hash()
+		},
+		OnTxEnd: func(receipt *gethtypes.Receipt, _ error) {
+			// Receipt is nil when the transaction execution is aborted
+			if receipt == nil {
+				return
+			}
+			em.emit(&Event{Type: EventTxCompleted, TxIndex: index, TxHash: txHash, GasUsed: receipt.GasUsed})
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithEvents(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	ch := make(chan *Event)
+	events := make(chan []*Event)
+	go func() {
+		var collected []*Event
+		for event := range ch {
+			collected = append(collected, event)
+		}
+		events <- collected
+	}()
+
+	_, err := NewExecutor(WithEvents(ch)).Execute(context.Background(), proverInput)
+	close(ch)
+	require.NoError(t, err)
+	collected := <-events
+
+	type step struct {
+		typ   EventType
+		phase string
+	}
+	var steps []step
+	for _, event := range collected {
+		steps = append(steps, step{event.Type, event.Phase})
+		assert.NoError(t, event.Err)
+	}
+	assert.Equal(t, []step{
+		{EventPhaseStarted, "prepareContext"},
+		{EventPhaseFinished, "prepareContext"},
+		{EventPhaseStarted, "preparePreState"},
+		{EventPhaseFinished, "preparePreState"},
+		{EventPhaseStarted, "prepareExecParams"},
+		{EventPhaseFinished, "prepareExecParams"},
+		{EventPhaseStarted, "execEVM"},
+		{EventTxCompleted, ""},
+		{EventTxCompleted, ""},
+		{EventPhaseFinished, "execEVM"},
+	}, steps)
+
+	assert.Equal(t, len(proverInput.Witness.State), collected[3].Nodes)
+	assert.Equal(t, len(proverInput.Witness.Codes), collected[3].Codes)
+	for i, event := range collected[7:9] {
+		tx := proverInput.Blocks[0].Transactions[i]
+		assert.Equal(t, i, event.TxIndex)
+		assert.Equal(t, tx.Hash(), event.TxHash)
+		assert.NotZero(t, event.GasUsed)
+	}
+}
@@ -2,18 +2,33 @@ package generator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"time"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/kkrt-labs/go-utils/log"
 	"github.com/kkrt-labs/go-utils/tag"
 	"github.com/kkrt-labs/zk-pig/src/ethereum"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/ethdb/rpcdb"
 	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/state"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
 	input "github.com/kkrt-labs/zk-pig/src/prover-input"
 	"go.uber.org/zap"
 )
@@ -23,22 +38,594 @@ import (
 // It is primarily meant to validate that the provable inputs are correct and enable proper EVM execution.
 type Executor interface {
 	// Execute runs a full EVM block execution on provable inputs
-	Execute(ctx context.Context, inputs *input.ProverInput) (*core.ProcessResult, error)
+	// If the block fails processing or validation, the partial result produced up to the failure is returned alongside the error (when available).
+	Execute(ctx context.Context, inputs *input.ProverInput) (*Result, error)
 }
 
-type executor struct{}
+// Result is the result of an EVM execution on provable inputs.
+type Result struct {
+	ProcessResult *core.ProcessResult // Result of the block processing
+	GasRefunded   uint64              // Total gas refunded across the block's transactions (capped per EIP-3529 post-London)
+	CoinbaseFees  *big.Int            // Total transaction fees credited to the coinbase (only the priority fees post-London, as base fees are burnt)
+	TouchedState  *state.Dump         // Flat dump of the accounts and storage slots touched during execution, with post-state values
+	Calls         []*evm.CallFrame    // Call tree of every transaction (only set if call tracing is enabled)
+	LoadedCodes   []*evm.LoadedCode   // Accounts whose code was loaded during execution, sorted by address (only set if code load tracing is enabled)
+
+	// Block quality signals, transactions hitting such conditions are still valid block-wise
+	InvalidOpcode    bool  // Whether any transaction hit an invalid opcode
+	OutOfGas         bool  // Whether any transaction ran out of gas
+	InvalidOpcodeTxs []int // Indexes of the transactions that hit an invalid opcode
+	OutOfGasTxs      []int // Indexes of the transactions that ran out of gas
+
+	PeakHeapAlloc uint64 // Approximate peak heap allocation in bytes during execution (only set if memory sampling is enabled)
+
+	ChainConfigOverrides []string // JSON names of the input chain configuration fields overridden by the external chain configuration
+
+	WitnessSize       uint64  // Size in bytes of the witness state nodes and codes
+	WitnessSizePerGas float64 // Witness size per unit of gas used, unusually large ratios may indicate state bloat (zero if no gas was used)
+
+	VersionedHashes []*VersionedHash // Blob versioned hashes referenced by the block blob transactions, in block order
+
+	RequestsHash *gethcommon.Hash // EIP-7685 requests hash computed from the requests collected during execution (only set for Prague blocks)
+
+	ModifiedNodes []*trie.ModifiedNode // Trie nodes modified by the execution, sorted by owner and path (only set if modified nodes export is enabled)
+
+	StorageChanges []*StorageChanges // Storage slots modified by the execution with pre and post values, sorted by address (only set if storage changes export is enabled)
+
+	Validated        bool     // Whether the execution result and post-state were validated against the block (not the case with custom rules, rewards, gas limit or in dry run mode)
+	ValidationChecks []string // Names of the validation checks performed, in the order they ran (empty if not validated)
+
+	GasTargetReached *evm.GasTargetReached // Transaction at which the execution stopped (only set when executing with a gas target reached before the end of the block)
+
+	GasByCategory map[evm.OpcodeCategory]uint64 // Gas consumed by the block transactions per opcode category (only set if gas breakdown is enabled)
+
+	StepCapture *evm.StepCapture // EVM state at the captured transaction step (only set if step capture is enabled)
+
+	StorageAccessOrder []*evm.StorageAccessOrder // Storage slots accessed per account in first-access order, accounts ordered by first access (only set if storage order tracing is enabled)
+
+	GasPool *evm.GasPoolReport // Block gas pool depletion per transaction (only set if gas pool tracing is enabled)
+
+	HashedAddresses map[gethcommon.Address]gethcommon.Hash // Account trie keys (keccak256 of the address) of the accounts touched during execution (only set if hashed addresses export is enabled)
+
+	attestation Attestation
+	witness     *stateless.Witness // Witness consumed by the execution (nil if not recorded)
+}
+
+// DefaultMaxCodeSize is the default maximum size of a witness code
+// It is the EIP-170 limit with a margin for contracts deployed before Spurious Dragon
+const DefaultMaxCodeSize = 2 * params.MaxCodeSize
+
+type executor struct {
+	maxCodeSize  int
+	rules        *params.Rules
+	traceCalls   bool
+	traceCodes   bool
+	maxBlockSize uint64
+	precompiles  map[gethcommon.Address]vm.PrecompiledContract
+	stateTest    bool
+	onError      func(context.Context, *input.ProverInput, error)
+	rewards      *ethereum.BlockRewards
+	zkTraces     evm.ZKTraceWriter
+
+	sampleMemory   bool
+	memoryInterval time.Duration
+
+	chainConfig *params.ChainConfig
+	cache       *Cache
+
+	prewarm        bool
+	prewarmWorkers int
+
+	noGlobalMetrics bool
+	modifiedNodes   bool
+	storageChanges  bool
+	stepLimit       uint64
+	callDepthLimit  int
+	slowThreshold   time.Duration
+	pruneCodes      bool
+	readSink        state.ReadSink
+	events          chan<- *Event
+
+	cpuProfile  string
+	heapProfile string
+
+	witnessRatioThreshold float64
+	sortAncestors         bool
+	gasLimitOverride      uint64
+	assertions            []Assertion
+	nodeBatchSize         int
+	compareLegacy         bool
+	legacyPath            bool
+	logger                *zap.Logger
+	db                    ethdb.Database // Database reused across executions (a new in-memory database is created per execution if nil)
+	dryRun                bool
+	feeRecipient          *gethcommon.Address
+	gasTarget             uint64
+	nodeFetcher           rpcdb.NodeFetcher
+	nodeFetchRetries      int
+	gasBreakdown          bool
+	deploymentOverride    evm.DeploymentOverride
+	captureTx             int
+	captureStep           uint64
+	captureEnabled        bool
+	checks                evm.ValidationCheck
+	validateNonces        bool
+	extraEIPs             []int
+	headerDB              ethdb.Reader
+	noPrefetch            bool
+	expectedWitnessHash   *gethcommon.Hash
+	hashedAddresses       bool
+	checkUnreachable      bool
+	traceGasPool          bool
+	stateRootOracle       StateRootOracle
+	traceStorageOrder     bool
+	validateCodes         bool
+	validateEOF           bool
+}
+
+// ExecutorOption is an option to configure an Executor.
+type ExecutorOption func(*executor)
+
+// WithMaxCodeSize sets the maximum size of a witness code, inputs with larger codes are rejected
+// If size is zero or negative, code size is not checked
+func WithMaxCodeSize(size int) ExecutorOption {
+	return func(e *executor) {
+		e.maxCodeSize = size
+	}
+}
+
+// WithBlockRewards overrides the mining rewards applied to PoW blocks (for testing purpose)
+// As the resulting state is not expected to match the block, block validation is disabled.
+func WithBlockRewards(rewards *ethereum.BlockRewards) ExecutorOption {
+	return func(e *executor) {
+		e.rewards = rewards
+	}
+}
+
+// WithMaxBlockSize sets the maximum RLP encoded size of a block, larger blocks are rejected before execution
+// If size is zero, block size is not checked
+func WithMaxBlockSize(size uint64) ExecutorOption {
+	return func(e *executor) {
+		e.maxBlockSize = size
+	}
+}
+
+// WithChainConfig sets an external chain configuration merged with the input chain configuration (e.g. when the input configuration is stale)
+// Fields set in the external configuration take precedence over the input configuration, unset fields are kept from the input.
+// Overridden fields are reported in the execution result.
+func WithChainConfig(cfg *params.ChainConfig) ExecutorOption {
+	return func(e *executor) {
+		e.chainConfig = cfg
+	}
+}
+
+// WithRules sets explicit EVM rules used during execution, bypassing the rules derived from the input chain configuration
+// It is meant for protocol research (e.g. toggling an individual EIP), as the execution result is not expected to match the block
+// so block validation is disabled.
+func WithRules(rules *params.Rules) ExecutorOption {
+	return func(e *executor) {
+		e.rules = rules
+	}
+}
+
+// WithPrecompiles sets additional precompiled contracts made available to the EVM (e.g. for L2s with custom precompiles)
+// They are merged with the fork default precompiles, overriding them on address collision
+func WithPrecompiles(precompiles map[gethcommon.Address]vm.PrecompiledContract) ExecutorOption {
+	return func(e *executor) {
+		e.precompiles = precompiles
+	}
+}
+
+// WithStateTestMode only validates the resulting state root after applying the block transactions and withdrawals
+// Consensus related checks (gas used, bloom, receipts and requests roots) are skipped, as in state transition tests
+func WithStateTestMode() ExecutorOption {
+	return func(e *executor) {
+		e.stateTest = true
+	}
+}
+
+// WithErrorHook sets a hook invoked with the execution context and the inputs before returning any execution error
+// It is meant for centralized error reporting, the context holds the block tags
+func WithErrorHook(onError func(ctx context.Context, inputs *input.ProverInput, err error)) ExecutorOption {
+	return func(e *executor) {
+		e.onError = onError
+	}
+}
+
+// WithZKTraceWriter enables emitting the zk trace of every transaction, traces are persisted with the given writer
+func WithZKTraceWriter(writer evm.ZKTraceWriter) ExecutorOption {
+	return func(e *executor) {
+		e.zkTraces = writer
+	}
+}
+
+// WithMemorySampling enables sampling the heap usage at the given interval to report its approximate peak in the execution result
+// If interval is zero, DefaultMemorySamplingInterval is used
+func WithMemorySampling(interval time.Duration) ExecutorOption {
+	return func(e *executor) {
+		e.sampleMemory = true
+		e.memoryInterval = interval
+	}
+}
+
+// WithCache sets a cache of ancestors headers and codes shared across executions (e.g. when executing a batch of overlapping blocks)
+func WithCache(cache *Cache) ExecutorOption {
+	return func(e *executor) {
+		e.cache = cache
+	}
+}
+
+// WithCallTracing enables capturing the internal call tree of every transaction in the execution result
+func WithCallTracing() ExecutorOption {
+	return func(e *executor) {
+		e.traceCalls = true
+	}
+}
+
+// WithCodeLoadTracing enables reporting the accounts whose code was loaded during execution, with their code hashes, in the execution result
+func WithCodeLoadTracing() ExecutorOption {
+	return func(e *executor) {
+		e.traceCodes = true
+	}
+}
+
+// WithPrewarm enables recovering transaction senders in parallel with the given number of workers before sequential execution
+// Pre-warming is deterministic and does not access the state, so results are identical to serial execution
+// If workers is zero, the number of CPUs is used
+func WithPrewarm(workers int) ExecutorOption {
+	return func(e *executor) {
+		e.prewarm = true
+		e.prewarmWorkers = workers
+	}
+}
+
+// WithoutGlobalMetrics disables go-ethereum global metrics registration during execution
+// The state prefetcher is the only component of the execution path registering meters at runtime (in go-ethereum default registry,
+// shared by every consumer of the process), so it is not started. Other go-ethereum meters of the execution path (e.g. hashdb cache meters)
+// are registered at package initialization and are no-ops unless metrics are enabled, the rest of the execution state is instance-scoped.
+//
+// The prefetcher also records the execution witness, so no witness is recorded: the option is rejected by callers relying on it
+// (i.e. ProcessAndCanonicalize, whose witness minimization would otherwise be silently skipped).
+func WithoutGlobalMetrics() ExecutorOption {
+	return func(e *executor) {
+		e.noGlobalMetrics = true
+	}
+}
+
+// WithModifiedNodes enables exporting the (owner, path) -> node entries of the tries modified by the execution in the execution result
+// It is meant for external provers building their own proofs of the state transition
+func WithModifiedNodes() ExecutorOption {
+	return func(e *executor) {
+		e.modifiedNodes = true
+	}
+}
+
+// WithStorageChanges enables exporting the storage slots modified by the execution, with their pre and post values, in the execution result
+// Changes are derived from the modified storage tries rather than from traced storage writes, so slots written back to their original value are omitted.
+func WithStorageChanges() ExecutorOption {
+	return func(e *executor) {
+		e.storageChanges = true
+	}
+}
+
+// WithStepLimit sets a maximum number of opcode steps per transaction (including internal calls), independent from gas
+// A transaction exceeding it aborts the execution, the error reports the transaction and the step count
+func WithStepLimit(limit uint64) ExecutorOption {
+	return func(e *executor) {
+		e.stepLimit = limit
+	}
+}
+
+// WithCallDepthLimit sets a soft cap on the call depth of transactions, below the 1024 call depth enforced by the EVM (the top-level call has depth 0)
+// A transaction exceeding it aborts the execution, the error reports the transaction, the depth and the address of the call frame
+func WithCallDepthLimit(limit int) ExecutorOption {
+	return func(e *executor) {
+		e.callDepthLimit = limit
+	}
+}
+
+// WithSlowThreshold enables logging a warning with the timings of every execution phase for executions lasting longer than the threshold
+func WithSlowThreshold(threshold time.Duration) ExecutorOption {
+	return func(e *executor) {
+		e.slowThreshold = threshold
+	}
+}
+
+// WithCodePruning skips writing the witness codes that no witness account references
+// Every account leaf of the witness state nodes is considered, including those not reachable from the pre-state root.
+// Pruning only applies when the block is validated (i.e. without custom rules, rewards or gas limit), so that a wrongly pruned code can not go unnoticed.
+func WithCodePruning() ExecutorOption {
+	return func(e *executor) {
+		e.pruneCodes = true
+	}
+}
+
+// WithReadRecorder records every account and storage read of the execution into the given sink (e.g. for auditing)
+func WithReadRecorder(sink state.ReadSink) ExecutorOption {
+	return func(e *executor) {
+		e.readSink = sink
+	}
+}
+
+// WithEvents enables emitting structured execution events (phase started/finished, transaction completed) to the given channel
+// Sending an event blocks until it is received or the execution context is done, so the channel should be drained concurrently.
+func WithEvents(ch chan<- *Event) ExecutorOption {
+	return func(e *executor) {
+		e.events = ch
+	}
+}
+
+// WithProfiling enables capturing a CPU profile of every execution, written to the given path (overwritten by each execution)
+// If heapProfile is not empty, a heap profile is also written to it at the end of the execution.
+// As a process can only capture one CPU profile at a time, executions fail while another execution is being profiled.
+func WithProfiling(cpuProfile, heapProfile string) ExecutorOption {
+	return func(e *executor) {
+		e.cpuProfile = cpuProfile
+		e.heapProfile = heapProfile
+	}
+}
+
+// WithWitnessSizePerGasThreshold enables logging a warning for blocks whose witness size per gas used exceeds the threshold
+// Large witnesses with little gas used often indicate state bloat attacks.
+func WithWitnessSizePerGasThreshold(threshold float64) ExecutorOption {
+	return func(e *executor) {
+		e.witnessRatioThreshold = threshold
+	}
+}
+
+// WithAncestorsSorting sorts the witness ancestors by descending block number on load, for inputs emitted with unordered ancestors
+// Sorted ancestors must form a contiguous chain.
+func WithAncestorsSorting() ExecutorOption {
+	return func(e *executor) {
+		e.sortAncestors = true
+	}
+}
+
+// WithGasLimitOverride overrides the block gas limit used during execution (e.g. to simulate how many of the block transactions fit)
+// As the result is not expected to match the block, block validation is disabled.
+func WithGasLimitOverride(gasLimit uint64) ExecutorOption {
+	return func(e *executor) {
+		e.gasLimitOverride = gasLimit
+	}
+}
+
+// Assertion is a predicate run on the result of a successful execution, returning an error if the result violates it
+type Assertion func(*Result) error
+
+// WithAssertion registers a post-execution assertion (e.g. "gas used < X" or "no reverts"), failing the execution if it is violated
+// Assertions are run in registration order and the execution fails on the first violated one.
+func WithAssertion(assertion Assertion) ExecutorOption {
+	return func(e *executor) {
+		e.assertions = append(e.assertions, assertion)
+	}
+}
+
+// WithNodeWriteBatchSize writes the witness trie nodes to the execution database in batches of the given number of nodes
+// It improves throughput on large witnesses, by default nodes are written one at a time.
+func WithNodeWriteBatchSize(size int) ExecutorOption {
+	return func(e *executor) {
+		e.nodeBatchSize = size
+	}
+}
+
+// WithLegacyComparison cross-checks the stateless self-validation path against the classic (non-stateless) validation path
+// The block is re-executed on a complete in-memory state built from the witness and the execution fails if both paths disagree.
+func WithLegacyComparison() ExecutorOption {
+	return func(e *executor) {
+		e.compareLegacy = true
+	}
+}
+
+// WithLogger sets the logger of the executor, overriding the logger of the execution context (and the global logger)
+// Use WithSilentLogging to suppress every executor log, e.g. when embedding the executor in another tool.
+func WithLogger(logger *zap.Logger) ExecutorOption {
+	return func(e *executor) {
+		e.logger = logger
+	}
+}
+
+// WithSilentLogging suppresses every executor log
+func WithSilentLogging() ExecutorOption {
+	return WithLogger(zap.NewNop())
+}
+
+// WithDryRun executes blocks without validating the execution result and post-state against the block
+func WithDryRun() ExecutorOption {
+	return func(e *executor) {
+		e.dryRun = true
+	}
+}
+
+// WithFeeRecipient validates that transaction fees are credited to the given address, overriding the fee recipient derived from the chain ID (see FeeRecipients)
+func WithFeeRecipient(addr gethcommon.Address) ExecutorOption {
+	return func(e *executor) {
+		e.feeRecipient = &addr
+	}
+}
+
+// WithGasTarget stops the execution once the cumulative gas used by the block transactions reaches the given target (e.g. for gas based bisection)
+// The transaction reaching the target completes and the result holds the intermediate state and the stop point (see Result.GasTargetReached).
+// The following transactions, withdrawals and end of block system calls are not executed, so block validation is disabled.
+func WithGasTarget(target uint64) ExecutorOption {
+	return func(e *executor) {
+		e.gasTarget = target
+	}
+}
+
+// WithNodeFallback fetches the trie nodes missing from the witness with the given node fetcher (e.g. an archive node over RPC)
+// rather than failing the execution. Failed fetches are retried up to retries times, so transient remote failures do not abort the execution.
+func WithNodeFallback(fetcher rpcdb.NodeFetcher, retries int) ExecutorOption {
+	return func(e *executor) {
+		e.nodeFetcher = fetcher
+		e.nodeFetchRetries = retries
+	}
+}
+
+// WithGasBreakdown enables the breakdown of the gas consumed by the block transactions per opcode category (e.g. for circuit cost modeling)
+func WithGasBreakdown() ExecutorOption {
+	return func(e *executor) {
+		e.gasBreakdown = true
+	}
+}
+
+// WithDeploymentOverride overrides the inputs of the CREATE and CREATE2 opcodes executed by the block (e.g. the CREATE2 salt to explore address derivation)
+// It is a research mode: the execution diverges from the canonical one, so block validation is disabled.
+func WithDeploymentOverride(override evm.DeploymentOverride) ExecutorOption {
+	return func(e *executor) {
+		e.deploymentOverride = override
+	}
+}
+
+// WithStepCapture captures the EVM memory, stack and storage right before the transaction at the given index executes the given step (see Result.StepCapture)
+// Steps are the opcodes executed by the transaction, including internal calls, indexed from 0. The execution fails if the step is not reached.
+func WithStepCapture(txIndex int, step uint64) ExecutorOption {
+	return func(e *executor) {
+		e.captureTx = txIndex
+		e.captureStep = step
+		e.captureEnabled = true
+	}
+}
+
+// WithValidationChecks restricts the block validation to the given checks (e.g. evm.CheckStateRoot | evm.CheckGasUsed)
+// The checks performed are reported in Result.ValidationChecks.
+func WithValidationChecks(checks evm.ValidationCheck) ExecutorOption {
+	return func(e *executor) {
+		e.checks = checks
+	}
+}
+
+// WithNonceValidation validates the block transactions nonces are sequential per sender, starting from the sender pre-state nonce, before execution
+// It reports the first violating transaction (see ValidateNonces), rather than the transaction processing error.
+func WithNonceValidation() ExecutorOption {
+	return func(e *executor) {
+		e.validateNonces = true
+	}
+}
+
+// WithExtraEIPs enables additional EVM interpreter features on top of the block fork (e.g. 3855 for PUSH0 before Shanghai)
+// EIPs must be supported by the EVM interpreter. EOF (EIP-3540 and related) cannot be toggled: the bundled EVM does not implement
+// the EOF opcodes (they panic with "not implemented"), so EIP-3540 is rejected rather than enabled.
+// The execution diverges from the canonical one, so block validation is disabled.
+func WithExtraEIPs(eips ...int) ExecutorOption {
+	return func(e *executor) {
+		e.extraEIPs = eips
+	}
+}
+
+// WithHeaderDB cross-checks the input blocks against a local database of known-good headers before execution
+// Inputs whose blocks are missing from the database or do not match its canonical hashes are rejected (see VerifyHeaderDB).
+func WithHeaderDB(db ethdb.Reader) ExecutorOption {
+	return func(e *executor) {
+		e.headerDB = db
+	}
+}
+
+// WithoutPrefetching disables state prefetching and senders pre-warming, so timings reflect pure sequential execution (e.g. for benchmarking)
+// It takes precedence over WithPrewarm.
+func WithoutPrefetching() ExecutorOption {
+	return func(e *executor) {
+		e.noPrefetch = true
+	}
+}
+
+// WithExpectedWitnessHash verifies the input witness matches the expected witness hash before execution (see input.WitnessHash)
+// It detects witness tampering between generation and execution, given a hash obtained from a trusted source.
+func WithExpectedWitnessHash(hash gethcommon.Hash) ExecutorOption {
+	return func(e *executor) {
+		e.expectedWitnessHash = &hash
+	}
+}
+
+// WithHashedAddresses enables exporting the account trie keys of the accounts touched during execution in the execution result
+// It is meant for tools working in hashed-key space (e.g. matching the witness trie leaves).
+func WithHashedAddresses() ExecutorOption {
+	return func(e *executor) {
+		e.hashedAddresses = true
+	}
+}
+
+// WithUnreachableNodesCheck fails the execution if the witness contains state nodes not reachable from the pre-state root (see input.UnreachableNodes)
+// The error reports the hashes of the unreachable nodes.
+func WithUnreachableNodesCheck() ExecutorOption {
+	return func(e *executor) {
+		e.checkUnreachable = true
+	}
+}
+
+// WithGasPoolTracing enables tracking the block gas pool depletion per transaction in the execution result
+// Accounting inconsistencies (e.g. cumulative gas used exceeding the block gas limit) are reported in the result rather than failing the execution.
+func WithGasPoolTracing() ExecutorOption {
+	return func(e *executor) {
+		e.traceGasPool = true
+	}
+}
+
+// WithStorageOrderTracing enables recording the storage slots accessed by the execution in first-access order in the execution result
+// It is meant for provers whose circuit layout depends on the storage access order.
+func WithStorageOrderTracing() ExecutorOption {
+	return func(e *executor) {
+		e.traceStorageOrder = true
+	}
+}
+
+// StateRootOracle returns the trusted post-state root of the block with the given number (e.g. from an external finality source)
+type StateRootOracle func(ctx context.Context, blockNumber uint64) (gethcommon.Hash, error)
+
+// WithStateRootOracle validates the computed post-state root against the root provided by a trusted oracle, independently of the block header
+// It applies even when block validation is disabled (e.g. in dry run mode).
+func WithStateRootOracle(oracle StateRootOracle) ExecutorOption {
+	return func(e *executor) {
+		e.stateRootOracle = oracle
+	}
+}
+
+// WithCodeValidation rejects inputs whose witness holds empty codes
+// It is a hardening measure against crafted witnesses, codes do not need to be valid to be executed otherwise.
+func WithCodeValidation() ExecutorOption {
+	return func(e *executor) {
+		e.validateCodes = true
+	}
+}
+
+// WithEOFValidation enables code validation (see WithCodeValidation) and also rejects EOF-tagged codes (0xEF00 prefix) that are not valid EOF containers
+// EOF is not activated by any fork of the bundled EVM (Prague included), where 0xEF00-prefixed codes are legacy codes, so it must only be enabled
+// for chains enforcing EOF.
+func WithEOFValidation() ExecutorOption {
+	return func(e *executor) {
+		e.validateCodes = true
+		e.validateEOF = true
+	}
+}
 
 // NewExecutor creates a new instance of the BaseExecutor.
-func NewExecutor() Executor {
-	return &executor{}
+func NewExecutor(opts ...ExecutorOption) Executor {
+	e := &executor{
+		maxCodeSize: DefaultMaxCodeSize,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // Execute runs the ProvableBlockInputs data for the EVM prover engine.
-func (e *executor) Execute(ctx context.Context, inputs *input.ProverInput) (*core.ProcessResult, error) {
+func (e *executor) Execute(ctx context.Context, inputs *input.ProverInput) (*Result, error) {
+	if e.logger != nil {
+		ctx = log.WithLogger(ctx, e.logger)
+	}
+
 	if len(inputs.Blocks) == 0 {
-		return nil, fmt.Errorf("no blocks provided")
+		err := fmt.Errorf("no blocks provided")
+		e.reportError(ctx, inputs, err)
+		return nil, err
 	}
 
+	migrated, err := input.Migrate(inputs)
+	if err != nil {
+		e.reportError(ctx, inputs, err)
+		return nil, err
+	}
+	inputs = migrated
 	block := inputs.Blocks[0]
 
 	ctx = tag.WithComponent(ctx, "execute")
@@ -49,9 +636,60 @@ func (e *executor) Execute(ctx context.Context, inputs *input.ProverInput) (*cor
 		tag.Key("block.hash").String(block.Header.Hash().Hex()),
 	)
 
+	if e.headerDB != nil {
+		for _, b := range inputs.Blocks {
+			if err := VerifyHeaderDB(e.headerDB, b.Header); err != nil {
+				err = fmt.Errorf("header database check failed: %v", err)
+				e.reportError(ctx, inputs, err)
+				return nil, err
+			}
+		}
+	}
+
+	if e.expectedWitnessHash != nil {
+		if err := input.VerifyWitnessHash(inputs.Witness, *e.expectedWitnessHash); err != nil {
+			err = fmt.Errorf("witness integrity check failed: %v", err)
+			e.reportError(ctx, inputs, err)
+			return nil, err
+		}
+	}
+
+	var prof *profiler
+	if e.cpuProfile != "" {
+		prof = &profiler{cpuPath: e.cpuProfile, heapPath: e.heapProfile}
+		if err := prof.start(); err != nil {
+			e.reportError(ctx, inputs, err)
+			return nil, err
+		}
+	}
+
+	var sampler *memorySampler
+	if e.sampleMemory {
+		sampler = newMemorySampler(e.memoryInterval)
+		sampler.start()
+	}
+
 	res, err := e.execute(ctx, inputs)
+	if err == nil && e.stateRootOracle != nil {
+		err = e.checkStateRootOracle(ctx, res)
+	}
+	if err == nil {
+		err = e.assert(res)
+	}
+	if prof != nil {
+		if profErr := prof.stop(); profErr != nil {
+			log.LoggerFromContext(ctx).Warn("Failed to write execution profiles", zap.Error(profErr))
+		}
+	}
+	if sampler != nil {
+		peak := sampler.stopAndPeak()
+		if res != nil {
+			res.PeakHeapAlloc = peak
+		}
+	}
 	if err != nil {
 		log.LoggerFromContext(ctx).Error("Provable execution failed", zap.Error(err))
+		e.reportError(ctx, inputs, err)
 		return res, err
 	}
 
@@ -60,69 +698,310 @@ func (e *executor) Execute(ctx context.Context, inputs *input.ProverInput) (*cor
 	return res, err
 }
 
+func (e *executor) assert(res *Result) error {
+	for i, assertion := range e.assertions {
+		if err := assertion(res); err != nil {
+			return fmt.Errorf("post-execution assertion %d failed: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// checkStateRootOracle compares the computed post-state root with the root provided by the state root oracle
+func (e *executor) checkStateRootOracle(ctx context.Context, res *Result) error {
+	expected, err := e.stateRootOracle(ctx, res.attestation.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to query state root oracle: %v", err)
+	}
+	if computed := res.attestation.PostStateRoot; computed != expected {
+		return fmt.Errorf("state root oracle mismatch for block %d (expected %v, computed %v)", res.attestation.BlockNumber, expected.Hex(), computed.Hex())
+	}
+	return nil
+}
+
+func (e *executor) reportError(ctx context.Context, inputs *input.ProverInput, err error) {
+	if e.onError != nil {
+		e.onError(ctx, inputs, err)
+	}
+}
+
 type executorContext struct {
-	ctx     context.Context
-	stateDB gethstate.Database
-	hc      *core.HeaderChain
+	ctx          context.Context
+	trackers     *state.AccessTrackerManager
+	stateDB      gethstate.Database
+	hc           *core.HeaderChain
+	ancestors    []*gethtypes.Header
+	preStateRoot gethcommon.Hash
+	overrides    []string
+	events       *eventEmitter
+	witnessSize  uint64
+	witnessHash  gethcommon.Hash
+
+	versionedHashes []*VersionedHash
+
+	// Post-state committed into the database (only set once committed when exporting modified nodes or storage changes)
+	postStateRoot  gethcommon.Hash
+	committedNodes map[gethcommon.Hash][]byte
 }
 
-func (e *executor) execute(ctx context.Context, inputs *input.ProverInput) (*core.ProcessResult, error) {
+func (e *executor) execute(ctx context.Context, inputs *input.ProverInput) (*Result, error) {
 	log.LoggerFromContext(ctx).Info("Process provable execution...")
 
+	timings := newPhaseTimings()
+	if e.slowThreshold > 0 {
+		defer func() {
+			if total := timings.total(); total > e.slowThreshold {
+				log.LoggerFromContext(ctx).Warn("Slow execution", append([]zap.Field{zap.Duration("threshold", e.slowThreshold), zap.Duration("total", total)}, timings.fields...)...)
+			}
+		}()
+	}
+
+	events := newEventEmitter(ctx, e.events)
+
+	events.phaseStarted("prepareContext")
 	execCtx, err := e.prepareContext(ctx, inputs)
+	events.phaseFinished("prepareContext", timings.lap("prepareContext"), err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare execution context: %v", err)
 	}
+	execCtx.events = events
 
-	e.preparePreState(execCtx, inputs)
+	events.phaseStarted("preparePreState")
+	err = e.preparePreState(execCtx, inputs)
+	events.emit(&Event{
+		Type:     EventPhaseFinished,
+		Phase:    "preparePreState",
+		Duration: timings.lap("preparePreState"),
+		Err:      err,
+		Nodes:    len(inputs.Witness.State),
+		Codes:    len(inputs.Witness.Codes),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare pre-state: %v", err)
+	}
 
+	events.phaseStarted("prepareExecParams")
 	execParams, err := e.prepareExecParams(execCtx, inputs)
+	events.phaseFinished("prepareExecParams", timings.lap("prepareExecParams"), err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare execution exec params: %v", err)
 	}
 
-	return e.execEVM(execCtx, execParams)
+	events.phaseStarted("execEVM")
+	res, err := e.execEVM(execCtx, execParams)
+	events.phaseFinished("execEVM", timings.lap("execEVM"), err)
+	if err != nil || !e.compareLegacy {
+		return res, err
+	}
+
+	events.phaseStarted("compareLegacy")
+	err = e.compareLegacyPath(ctx, inputs, res)
+	events.phaseFinished("compareLegacy", timings.lap("compareLegacy"), err)
+	return res, err
+}
+
+// phaseTimings records the duration of consecutive execution phases
+type phaseTimings struct {
+	start, last time.Time
+	fields      []zap.Field
+}
+
+func newPhaseTimings() *phaseTimings {
+	now := time.Now()
+	return &phaseTimings{start: now, last: now}
+}
+
+// lap records the duration of the phase ending now and returns it
+func (t *phaseTimings) lap(phase string) time.Duration {
+	now := time.Now()
+	duration := now.Sub(t.last)
+	t.fields = append(t.fields, zap.Duration(phase, duration))
+	t.last = now
+	return duration
+}
+
+func (t *phaseTimings) total() time.Duration {
+	return t.last.Sub(t.start)
 }
 
 func (e *executor) prepareContext(ctx context.Context, inputs *input.ProverInput) (*executorContext, error) {
 	log.LoggerFromContext(ctx).Debug("Prepare context...")
 
 	// --- Create necessary database and chain instances ---
-	db := rawdb.NewMemoryDatabase()
+	trackers := state.NewAccessTrackerManager()
+	db := e.db
+	if db == nil {
+		db = rawdb.NewMemoryDatabase()
+	}
+	if e.nodeFetcher != nil {
+		db = rpcdb.NodeFallback(db, e.nodeFetcher, e.nodeFetchRetries)
+	}
 	trieDB := triedb.NewDatabase(db, &triedb.Config{HashDB: &hashdb.Config{}})
-	stateDB := gethstate.NewDatabase(trieDB, nil) // We use a modified trie database to track trie modifications
+	var gethDB gethstate.Database = gethstate.NewDatabase(trieDB, nil)
+	if e.readSink != nil {
+		gethDB = state.NewRecorderDatabase(gethDB, e.readSink)
+	}
+	stateDB := state.NewAccessTrackerDatabase(gethDB, trackers) // We track state accesses to dump the touched state
+
+	chainCfg := inputs.ChainConfig
+	var overrides []string
+	if e.chainConfig != nil {
+		chainCfg, overrides = ethereum.MergeChainConfig(chainCfg, e.chainConfig)
+		if len(overrides) > 0 {
+			log.LoggerFromContext(ctx).Warn("Input chain configuration overridden", zap.Strings("fields", overrides))
+		}
+	}
+	if e.rules != nil {
+		log.LoggerFromContext(ctx).Warn("Executing with custom EVM rules, block validation is disabled")
+		var err error
+		if chainCfg, err = ethereum.ChainConfigWithRules(chainCfg, e.rules); err != nil {
+			return nil, fmt.Errorf("invalid custom rules: %v", err)
+		}
+	}
+
+	var decorators []ethereum.EngineDecorator
+	if e.rewards != nil {
+		log.LoggerFromContext(ctx).Warn("Executing with custom block rewards, block validation is disabled")
+		decorators = append(decorators, ethereum.WithBlockRewards(e.rewards))
+	}
 
-	hc, err := ethereum.NewChain(inputs.ChainConfig, stateDB)
+	hc, err := ethereum.NewChain(chainCfg, stateDB, decorators...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chain: %v", err)
 	}
 
 	return &executorContext{
-		ctx:     ctx,
-		stateDB: stateDB,
-		hc:      hc,
+		ctx:       ctx,
+		trackers:  trackers,
+		stateDB:   stateDB,
+		hc:        hc,
+		overrides: overrides,
 	}, nil
 }
 
-func (e *executor) preparePreState(ctx *executorContext, inputs *input.ProverInput) {
+func (e *executor) preparePreState(ctx *executorContext, inputs *input.ProverInput) error {
 	log.LoggerFromContext(ctx.ctx).Info("Prepare pre-state...")
 
 	// -- Preload the ancestors of the block into database ---
-	ethereum.WriteHeaders(ctx.stateDB.TrieDB().Disk(), inputs.Witness.Ancestors...)
+	ancestors, err := inputs.Witness.LoadAncestors()
+	if err != nil {
+		return fmt.Errorf("failed to load ancestors: %v", err)
+	}
+	// The witness hash identifies the input in the attestation, ancestors are hashed as loaded (see input.WitnessHash)
+	if ctx.witnessHash, err = input.WitnessHash(&input.Witness{State: inputs.Witness.State, Ancestors: ancestors, Codes: inputs.Witness.Codes}); err != nil {
+		return err
+	}
+	if e.sortAncestors {
+		if ancestors, err = input.SortAncestors(ancestors); err != nil {
+			return fmt.Errorf("failed to sort ancestors: %v", err)
+		}
+	}
+	if err := input.VerifyAncestorHashes(inputs.Blocks[0].Header, ancestors); err != nil {
+		return fmt.Errorf("invalid ancestors: %v", err)
+	}
+	if e.cache != nil {
+		ancestors = e.cache.writeHeaders(ctx.stateDB.TrieDB().Disk(), ctx.hc.Config().ChainID, inputs.Blocks[0].Header.ParentHash, ancestors)
+	} else {
+		ethereum.WriteHeaders(ctx.stateDB.TrieDB().Disk(), ancestors...)
+	}
+	ctx.ancestors = ancestors
 
 	// --- Preload the account bytecodes into the database ---
+	var referenced map[gethcommon.Hash]struct{}
+	header := inputs.Blocks[0].Header
+	if e.pruneCodes && e.validates() && !ctx.hc.Config().IsVerkle(header.Number, header.Time) {
+		referenced = referencedCodeHashes(inputs.Witness)
+	}
 	codes := make([][]byte, 0)
 	for _, code := range inputs.Witness.Codes {
+		if e.maxCodeSize > 0 && len(code) > e.maxCodeSize {
+			return fmt.Errorf("code %v exceeds maximum code size (size=%d, max=%d)", crypto.Keccak256Hash(code).Hex(), len(code), e.maxCodeSize)
+		}
+		if e.validateCodes {
+			if err := validateCode(code, e.validateEOF); err != nil {
+				return fmt.Errorf("invalid code %v: %v", crypto.Keccak256Hash(code).Hex(), err)
+			}
+		}
+		if referenced != nil {
+			if _, ok := referenced[crypto.Keccak256Hash(code)]; !ok {
+				continue
+			}
+		}
 		codes = append(codes, code)
 	}
-	ethereum.WriteCodes(ctx.stateDB.TrieDB().Disk(), codes...)
+	if referenced != nil && len(codes) < len(inputs.Witness.Codes) {
+		log.LoggerFromContext(ctx.ctx).Debug("Pruned unreferenced codes", zap.Int("pruned", len(inputs.Witness.Codes)-len(codes)))
+	}
+	if e.cache != nil {
+		e.cache.writeCodes(ctx.stateDB.TrieDB().Disk(), codes...)
+	} else {
+		ethereum.WriteCodes(ctx.stateDB.TrieDB().Disk(), codes...)
+	}
+
+	ctx.witnessSize = witnessSize(inputs.Witness)
+
+	// -- Preload the pre-state witness to database ---
+	if len(ancestors) == 0 {
+		return fmt.Errorf("no ancestors provided")
+	}
+	if ancestors[0] == nil {
+		return fmt.Errorf("first ancestor must be the parent of the first block")
+	}
+
+	if e.checkUnreachable {
+		unreachable, err := input.UnreachableNodes(ancestors[0].Root, inputs.Witness)
+		if err != nil {
+			return fmt.Errorf("failed to check witness reachability: %v", err)
+		}
+		if len(unreachable) > 0 {
+			return fmt.Errorf("witness has %d node(s) unreachable from the pre-state root: %v", len(unreachable), unreachable)
+		}
+	}
+	backend := selectWitnessBackend(ctx.hc.Config(), inputs.Blocks[0].Header, e.nodeBatchSize)
+	if err := backend.LoadPreState(ctx.stateDB.TrieDB().Disk(), ancestors[0].Root, inputs.Witness); err != nil {
+		return fmt.Errorf("failed to load pre-state witness: %v", err)
+	}
+
+	return nil
+}
+
+// witnessSize returns the size in bytes of the witness state nodes and codes
+func witnessSize(witness *input.Witness) uint64 {
+	var size uint64
+	for _, node := range witness.State {
+		size += uint64(len(node))
+	}
+	for _, code := range witness.Codes {
+		size += uint64(len(code))
+	}
+	return size
+}
 
-	// -- Preload the pre-state nodes to database ---
-	nodes := make([][]byte, 0)
-	for _, node := range inputs.Witness.State {
-		nodes = append(nodes, node)
+// referencedCodeHashes returns the code hashes of the accounts found in the witness state nodes
+// Every node is decoded rather than walking the trie from the pre-state root, so accounts in nodes not yet attached to the loaded trie are not missed
+func referencedCodeHashes(witness *input.Witness) map[gethcommon.Hash]struct{} {
+	hashes := make(map[gethcommon.Hash]struct{})
+	for _, blob := range witness.State {
+		n, err := trie.DecodeNode(blob)
+		if err != nil {
+			continue
+		}
+		short, ok := n.(*trie.ShortNode)
+		if !ok || !short.IsLeaf() {
+			continue
+		}
+		value, ok := short.Val.(trie.ValueNode)
+		if !ok {
+			continue
+		}
+		var account gethtypes.StateAccount
+		if err := rlp.DecodeBytes(value, &account); err != nil {
+			// Not an account leaf (e.g. a storage slot)
+			continue
+		}
+		hashes[gethcommon.BytesToHash(account.CodeHash)] = struct{}{}
 	}
-	ethereum.WriteNodesToHashDB(ctx.stateDB.TrieDB().Disk(), nodes...)
+	return hashes
 }
 
 func (e *executor) prepareExecParams(ctx *executorContext, inputs *input.ProverInput) (*evm.ExecParams, error) {
@@ -132,39 +1011,486 @@ func (e *executor) prepareExecParams(ctx *executorContext, inputs *input.ProverI
 
 	log.LoggerFromContext(ctx.ctx).Debug("Prepare execution parameters...")
 
-	if len(inputs.Witness.Ancestors) == 0 {
-		return nil, fmt.Errorf("no ancestors provided")
-	}
-
-	parentHeader := inputs.Witness.Ancestors[0]
+	parentHeader := ctx.ancestors[0]
 
 	if parentHeader == nil || parentHeader.Hash() == inputs.Blocks[0].Header.Hash() {
 		return nil, fmt.Errorf("first ancestor must be the parent of the first block")
 	}
 
+	if err := checkOmmers(inputs.Blocks[0]); err != nil {
+		return nil, err
+	}
+	if e.validates() && !e.stateTest {
+		if err := validateOmmers(inputs.Blocks[0], ctx.ancestors); err != nil {
+			return nil, fmt.Errorf("block validation failed: %v", err)
+		}
+		if err := validateGasLimit(ctx.hc.Config(), inputs.Blocks[0].Header, parentHeader); err != nil {
+			return nil, fmt.Errorf("block validation failed: %v", err)
+		}
+	}
+
+	// Custom rules may activate forks regardless of the block fields
+	if e.rules == nil {
+		if err := checkForkFields(ctx.hc.Config(), inputs.Blocks[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	block := inputs.Blocks[0].Block()
+	if e.gasLimitOverride > 0 {
+		log.LoggerFromContext(ctx.ctx).Warn("Executing with custom gas limit, block validation is disabled", zap.Uint64("gasLimit", e.gasLimitOverride), zap.Uint64("blockGasLimit", block.GasLimit()))
+		header := block.Header()
+		header.GasLimit = e.gasLimitOverride
+		block = gethtypes.NewBlockWithHeader(header).WithBody(*block.Body())
+	}
+	if e.maxBlockSize > 0 && block.Size() > e.maxBlockSize {
+		return nil, fmt.Errorf("block exceeds maximum size (size=%d, max=%d)", block.Size(), e.maxBlockSize)
+	}
+
+	versionedHashes, err := blockVersionedHashes(block)
+	if err != nil {
+		return nil, err
+	}
+	ctx.versionedHashes = versionedHashes
+
 	preState, err := gethstate.New(parentHeader.Root, ctx.stateDB)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pre-state from parent root %v: %v", parentHeader.Root, err)
 	}
+	ctx.preStateRoot = parentHeader.Root
+
+	for _, eip := range e.extraEIPs {
+		if !vm.ValidEip(eip) {
+			return nil, fmt.Errorf("unsupported EIP %d: not available in the EVM interpreter", eip)
+		}
+	}
+
+	if e.validateNonces {
+		if err := ValidateNonces(ctx.hc.Config(), block, preState); err != nil {
+			return nil, err
+		}
+	}
 
 	return &evm.ExecParams{
 		VMConfig: &vm.Config{
-			StatelessSelfValidation: true,
+			StatelessSelfValidation: !e.noGlobalMetrics && !e.legacyPath,
+			ExtraEips:               e.extraEIPs,
 		},
-		Block:    inputs.Blocks[0].Block(),
-		Validate: true, // We validate the block execution to ensure the result and final state are correct
-		Chain:    ctx.hc,
-		State:    preState,
+		Block:          block,
+		Validate:       e.validates(), // We validate the block execution to ensure the result and final state are correct (unless executing with custom rules, rewards, gas limit, gas target, deployment override, extra EIPs or in dry run mode)
+		Chain:          ctx.hc,
+		State:          preState,
+		Precompiles:    e.precompiles,
+		StateTestMode:  e.stateTest,
+		Checks:         e.checks,
+		Prewarm:        e.prewarm,
+		PrewarmWorkers: e.prewarmWorkers,
+		NoPrefetch:     e.noPrefetch,
 	}, nil
 }
 
-func (e *executor) execEVM(ctx *executorContext, execParams *evm.ExecParams) (*core.ProcessResult, error) {
+// validates returns whether the block is validated after execution, which is not the case when executing with custom rules, rewards, gas limit, gas target, deployment override, extra EIPs or in dry run mode
+func (e *executor) validates() bool {
+	return e.rules == nil && e.rewards == nil && e.gasLimitOverride == 0 && e.gasTarget == 0 && e.deploymentOverride == nil && len(e.extraEIPs) == 0 && !e.dryRun
+}
+
+func (e *executor) execEVM(ctx *executorContext, execParams *evm.ExecParams) (*Result, error) {
 	log.LoggerFromContext(ctx.ctx).Info("Execute EVM...")
 
+	refundTracer := evm.NewRefundTracer()
+	errorTracer := evm.NewExecErrorTracer()
+	feeTracer := evm.NewFeeTracer()
+	execParams.VMConfig.Tracer = evm.MuxHooks(refundTracer.Hooks(), errorTracer.Hooks(), feeTracer.Hooks())
+
+	// The deployment override is muxed first, so every other tracer observes the overridden deployment inputs
+	if e.deploymentOverride != nil {
+		execParams.VMConfig.Tracer = evm.MuxHooks(evm.NewDeploymentOverrideTracer(e.deploymentOverride).Hooks(), execParams.VMConfig.Tracer)
+	}
+
+	var callTracer *evm.CallTracer
+	if e.traceCalls {
+		callTracer = evm.NewCallTracer()
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, callTracer.Hooks())
+	}
+
+	var codeTracer *evm.CodeLoadTracer
+	if e.traceCodes {
+		codeTracer = evm.NewCodeLoadTracer(execParams.State)
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, codeTracer.Hooks())
+	}
+
+	var zkTracer *evm.ZKTracer
+	if e.zkTraces != nil {
+		zkTracer = evm.NewZKTracer(e.zkTraces)
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, zkTracer.Hooks())
+	}
+
+	if hooks := ctx.events.hooks(); hooks != nil {
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, hooks)
+	}
+
+	var gasCategoryTracer *evm.GasCategoryTracer
+	if e.gasBreakdown {
+		gasCategoryTracer = evm.NewGasCategoryTracer()
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, gasCategoryTracer.Hooks())
+	}
+
+	var stepCaptureTracer *evm.StepCaptureTracer
+	if e.captureEnabled {
+		stepCaptureTracer = evm.NewStepCaptureTracer(e.captureTx, e.captureStep)
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, stepCaptureTracer.Hooks())
+	}
+
+	var storageOrderTracer *evm.StorageOrderTracer
+	if e.traceStorageOrder {
+		storageOrderTracer = evm.NewStorageOrderTracer()
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, storageOrderTracer.Hooks())
+	}
+
+	var gasPoolTracer *evm.GasPoolTracer
+	if e.traceGasPool {
+		gasPoolTracer = evm.NewGasPoolTracer()
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, gasPoolTracer.Hooks())
+	}
+
+	if e.stepLimit > 0 {
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, evm.NewStepLimitTracer(e.stepLimit).Hooks())
+	}
+	if e.callDepthLimit > 0 {
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, evm.NewCallDepthLimitTracer(e.callDepthLimit).Hooks())
+	}
+
+	// Receipts are collected as transactions complete, to report partial results if processing fails
+	var receipts []*gethtypes.Receipt
+	execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, &tracing.Hooks{
+		OnTxEnd: func(receipt *gethtypes.Receipt, _ error) {
+			if receipt != nil {
+				receipts = append(receipts, receipt)
+			}
+		},
+	})
+
+	// The gas target tracer is muxed last, so every other tracer observes the end of the transaction reaching the target
+	if e.gasTarget > 0 {
+		execParams.VMConfig.Tracer = evm.MuxHooks(execParams.VMConfig.Tracer, evm.NewGasTargetTracer(e.gasTarget).Hooks())
+	}
+
 	res, err := evm.ExecutorWithTags("evm")(evm.ExecutorWithLog()(evm.NewExecutor())).Execute(ctx.ctx, execParams)
+
+	var gasTargetReached *evm.GasTargetReached
+	if errors.As(err, &gasTargetReached) {
+		log.LoggerFromContext(ctx.ctx).Info("Gas target reached, execution stopped", zap.Int("tx.index", gasTargetReached.TxIndex), zap.Uint64("gasUsed", gasTargetReached.CumulativeGasUsed))
+		res, err = &core.ProcessResult{Receipts: receipts, GasUsed: gasTargetReached.CumulativeGasUsed}, nil
+	}
+
 	if err != nil {
-		return res, fmt.Errorf("failed to execute block: %v", err)
+		// Validation failures return the full processing result, processing failures only the transactions completed so far
+		if res == nil {
+			res = &core.ProcessResult{Receipts: receipts}
+			if len(receipts) > 0 {
+				res.GasUsed = receipts[len(receipts)-1].CumulativeGasUsed
+			}
+		}
+		partial := &Result{
+			ProcessResult:    res,
+			GasRefunded:      refundTracer.Refunded(),
+			CoinbaseFees:     feeTracer.Fees(),
+			InvalidOpcode:    len(errorTracer.InvalidOpcodeTxs()) > 0,
+			OutOfGas:         len(errorTracer.OutOfGasTxs()) > 0,
+			InvalidOpcodeTxs: errorTracer.InvalidOpcodeTxs(),
+			OutOfGasTxs:      errorTracer.OutOfGasTxs(),
+		}
+		if callTracer != nil {
+			partial.Calls = callTracer.Calls()
+		}
+		return partial, fmt.Errorf("failed to execute block: %v", err)
 	}
 
-	return res, nil
+	if zkTracer != nil && zkTracer.Err() != nil {
+		return nil, zkTracer.Err()
+	}
+
+	validationChecks := evm.ValidationChecks(execParams)
+	if feeRecipient := e.expectedFeeRecipient(ctx.hc.Config()); feeRecipient != nil && execParams.Validate && !e.stateTest && execParams.EnabledChecks().Has(evm.CheckFeeRecipient) {
+		if err := validateFeeRecipient(*feeRecipient, execParams.Block.Coinbase(), feeTracer.Recipients()); err != nil {
+			return nil, fmt.Errorf("failed to execute block: block validation failed: %v", err)
+		}
+		validationChecks = append(validationChecks, "feeRecipient")
+	}
+	if header := execParams.Block.Header(); header.ParentBeaconRoot != nil && execParams.Validate && !e.stateTest && execParams.EnabledChecks().Has(evm.CheckBeaconRoot) {
+		if execParams.State.GetCodeSize(params.BeaconRootsAddress) > 0 {
+			if err := validateBeaconRoot(execParams.State, header); err != nil {
+				return nil, fmt.Errorf("failed to execute block: block validation failed: %v", err)
+			}
+			validationChecks = append(validationChecks, "beaconRoot")
+		}
+	}
+
+	result := &Result{
+		ProcessResult: res,
+		GasRefunded:   refundTracer.Refunded(),
+		CoinbaseFees:  feeTracer.Fees(),
+		TouchedState:  state.DumpTouchedState(ctx.trackers.GetAccessTracker(ctx.preStateRoot), execParams.State),
+
+		InvalidOpcode:    len(errorTracer.InvalidOpcodeTxs()) > 0,
+		OutOfGas:         len(errorTracer.OutOfGasTxs()) > 0,
+		InvalidOpcodeTxs: errorTracer.InvalidOpcodeTxs(),
+		OutOfGasTxs:      errorTracer.OutOfGasTxs(),
+
+		ChainConfigOverrides: ctx.overrides,
+
+		Validated:        execParams.Validate,
+		ValidationChecks: validationChecks,
+		GasTargetReached: gasTargetReached,
+
+		attestation: Attestation{
+			ChainID:       ctx.hc.Config().ChainID,
+			BlockNumber:   execParams.Block.NumberU64(),
+			BlockHash:     execParams.Block.Hash(),
+			WitnessHash:   ctx.witnessHash,
+			PreStateRoot:  ctx.preStateRoot,
+			PostStateRoot: execParams.State.IntermediateRoot(ctx.hc.Config().IsEIP158(execParams.Block.Number())),
+			ReceiptsRoot:  gethtypes.DeriveSha(gethtypes.Receipts(res.Receipts), gethtrie.NewStackTrie(nil)),
+			GasUsed:       res.GasUsed,
+		},
+	}
+	if callTracer != nil {
+		result.Calls = callTracer.Calls()
+	}
+	if codeTracer != nil {
+		result.LoadedCodes = codeTracer.LoadedCodes()
+	}
+	if gasCategoryTracer != nil {
+		result.GasByCategory = gasCategoryTracer.GasByCategory()
+	}
+	if storageOrderTracer != nil {
+		result.StorageAccessOrder = storageOrderTracer.StorageAccessOrder()
+	}
+	if gasPoolTracer != nil {
+		result.GasPool = gasPoolTracer.Report()
+	}
+	if stepCaptureTracer != nil {
+		result.StepCapture, err = stepCaptureTracer.Capture()
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.WitnessSize = ctx.witnessSize
+	result.witness = execParams.State.Witness()
+	result.VersionedHashes = ctx.versionedHashes
+	if res.GasUsed > 0 {
+		result.WitnessSizePerGas = float64(ctx.witnessSize) / float64(res.GasUsed)
+	}
+	if e.witnessRatioThreshold > 0 && result.WitnessSizePerGas > e.witnessRatioThreshold {
+		log.LoggerFromContext(ctx.ctx).Warn(
+			"Witness size per gas above threshold",
+			zap.Float64("ratio", result.WitnessSizePerGas),
+			zap.Float64("threshold", e.witnessRatioThreshold),
+			zap.Uint64("witnessSize", result.WitnessSize),
+			zap.Uint64("gasUsed", res.GasUsed),
+		)
+	}
+	if res.Requests != nil {
+		requestsHash := gethtypes.CalcRequestsHash(res.Requests)
+		result.RequestsHash = &requestsHash
+	}
+
+	if e.modifiedNodes {
+		result.ModifiedNodes, err = modifiedNodes(ctx, execParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if e.storageChanges {
+		result.StorageChanges, err = storageChanges(ctx, execParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if e.hashedAddresses {
+		result.HashedAddresses = state.HashedAddresses(ctx.trackers.GetAccessTracker(ctx.preStateRoot))
+	}
+
+	return result, nil
+}
+
+// expectedFeeRecipient returns the address transaction fees must be credited to, if any
+func (e *executor) expectedFeeRecipient(cfg *params.ChainConfig) *gethcommon.Address {
+	if e.feeRecipient != nil {
+		return e.feeRecipient
+	}
+	if addr, ok := FeeRecipients[cfg.ChainID.String()]; ok {
+		return &addr
+	}
+	return nil
+}
+
+// validateFeeRecipient ensures the block coinbase and every transaction fee recipient is the expected fee recipient
+func validateFeeRecipient(expected, coinbase gethcommon.Address, recipients map[gethcommon.Address]*big.Int) error {
+	if coinbase != expected {
+		return fmt.Errorf("invalid fee recipient: block coinbase %v does not match chain fee recipient %v", coinbase.Hex(), expected.Hex())
+	}
+	for addr, fees := range recipients {
+		if addr != expected {
+			return fmt.Errorf("invalid fee recipient: %v fees credited to %v instead of chain fee recipient %v", fees, addr.Hex(), expected.Hex())
+		}
+	}
+	return nil
+}
+
+// beaconRootsHistoryLength is the length of the ring buffers of the EIP-4788 beacon roots contract
+const beaconRootsHistoryLength = 8191
+
+// validateBeaconRoot ensures the EIP-4788 beacon roots contract storage holds the block timestamp and parent beacon root, written at the start of the block
+func validateBeaconRoot(statedb vm.StateDB, header *gethtypes.Header) error {
+	timestampSlot := gethcommon.BigToHash(new(big.Int).SetUint64(header.Time % beaconRootsHistoryLength))
+	rootSlot := gethcommon.BigToHash(new(big.Int).SetUint64(header.Time%beaconRootsHistoryLength + beaconRootsHistoryLength))
+
+	if timestamp := statedb.GetState(params.BeaconRootsAddress, timestampSlot); timestamp != gethcommon.BigToHash(new(big.Int).SetUint64(header.Time)) {
+		return fmt.Errorf("invalid beacon root: beacon roots contract timestamp %v does not match block timestamp %d", timestamp.Big(), header.Time)
+	}
+	if root := statedb.GetState(params.BeaconRootsAddress, rootSlot); root != *header.ParentBeaconRoot {
+		return fmt.Errorf("invalid beacon root: beacon roots contract root %v does not match block parent beacon root %v", root.Hex(), header.ParentBeaconRoot.Hex())
+	}
+	return nil
+}
+
+// checkForkFields ensures the presence of the block fields matches the forks active at the block (e.g. withdrawals from Shanghai, blob gas fields from Cancun)
+func checkForkFields(cfg *params.ChainConfig, block *input.Block) error {
+	header := block.Header
+	shanghai := cfg.IsShanghai(header.Number, header.Time)
+	cancun := cfg.IsCancun(header.Number, header.Time)
+	prague := cfg.IsPrague(header.Number, header.Time)
+
+	fields := []struct {
+		name     string
+		present  bool
+		required bool
+	}{
+		{"withdrawals root", header.WithdrawalsHash != nil, shanghai},
+		{"blob gas used", header.BlobGasUsed != nil, cancun},
+		{"excess blob gas", header.ExcessBlobGas != nil, cancun},
+		{"parent beacon root", header.ParentBeaconRoot != nil, cancun},
+		{"requests root", header.RequestsHash != nil, prague},
+	}
+	for _, field := range fields {
+		if field.required && !field.present {
+			return fmt.Errorf("invalid block fields: missing %v", field.name)
+		}
+		if !field.required && field.present {
+			return fmt.Errorf("invalid block fields: unexpected %v", field.name)
+		}
+	}
+
+	if !shanghai {
+		if len(block.Withdrawals) > 0 {
+			return fmt.Errorf("invalid block fields: unexpected withdrawals")
+		}
+		return nil
+	}
+
+	if err := input.ValidateWithdrawalsRoot(block); err != nil {
+		return fmt.Errorf("invalid block fields: %v", err)
+	}
+
+	return nil
+}
+
+// Consensus limits of the ommers included by a block
+const (
+	maxOmmers     = 2 // Maximum number of ommers per block
+	maxOmmerDepth = 7 // Maximum distance between the block and its ommers heights
+)
+
+// validateOmmers enforces the consensus ommer rules: at most maxOmmers distinct ommers, each at most maxOmmerDepth blocks below the block and not one of its ancestors
+// The ommer parents can not be checked, as the witness only holds the ancestors accessed during execution.
+func validateOmmers(block *input.Block, ancestors []*gethtypes.Header) error {
+	if len(block.Uncles) > maxOmmers {
+		return fmt.Errorf("too many ommers: block includes %d ommers, at most %d allowed", len(block.Uncles), maxOmmers)
+	}
+
+	isAncestor := make(map[gethcommon.Hash]struct{}, len(ancestors))
+	for _, ancestor := range ancestors {
+		if ancestor != nil {
+			isAncestor[ancestor.Hash()] = struct{}{}
+		}
+	}
+	seen := make(map[gethcommon.Hash]struct{}, len(block.Uncles))
+	number := block.Header.Number
+	for i, ommer := range block.Uncles {
+		hash := ommer.Hash()
+		if _, ok := seen[hash]; ok {
+			return fmt.Errorf("invalid ommer %d (%v): duplicate ommer", i, hash.Hex())
+		}
+		seen[hash] = struct{}{}
+
+		if ommer.Number == nil || ommer.Number.Cmp(number) >= 0 {
+			return fmt.Errorf("invalid ommer %d (%v): height %v is not below block height %v", i, hash.Hex(), ommer.Number, number)
+		}
+		if depth := new(big.Int).Sub(number, ommer.Number); depth.Cmp(big.NewInt(maxOmmerDepth)) > 0 {
+			return fmt.Errorf("invalid ommer %d (%v): inclusion depth %v exceeds %d", i, hash.Hex(), depth, maxOmmerDepth)
+		}
+		if _, ok := isAncestor[hash]; ok {
+			return fmt.Errorf("invalid ommer %d (%v): ommer is an ancestor of the block", i, hash.Hex())
+		}
+	}
+	return nil
+}
+
+// validateGasLimit enforces the consensus bound on the gas limit adjustment between the parent and the block (strictly less than 1/1024 of the parent gas limit)
+// On the London fork block, the parent gas limit is scaled by the elasticity multiplier as EIP-1559 does.
+func validateGasLimit(cfg *params.ChainConfig, header, parent *gethtypes.Header) error {
+	parentGasLimit := parent.GasLimit
+	if cfg.IsLondon(header.Number) && !cfg.IsLondon(parent.Number) {
+		parentGasLimit *= cfg.ElasticityMultiplier()
+	}
+
+	if header.GasLimit < params.MinGasLimit {
+		return fmt.Errorf("invalid gas limit: %d below minimum %d", header.GasLimit, params.MinGasLimit)
+	}
+	delta := new(big.Int).Sub(new(big.Int).SetUint64(header.GasLimit), new(big.Int).SetUint64(parentGasLimit))
+	allowed := parentGasLimit/params.GasLimitBoundDivisor - 1
+	if parentGasLimit < params.GasLimitBoundDivisor {
+		allowed = 0
+	}
+	if new(big.Int).Abs(delta).Cmp(new(big.Int).SetUint64(allowed)) > 0 {
+		return fmt.Errorf("invalid gas limit adjustment: gas limit %d changes by %v from parent gas limit %d, at most ±%d allowed", header.GasLimit, delta, parentGasLimit, allowed)
+	}
+	return nil
+}
+
+// eofJumpTable is the instruction set EOF code sections are validated against
+var eofJumpTable = vm.NewPragueEOFInstructionSetForTesting()
+
+// validateCode checks the code is not empty and, if eof is set, that EOF-tagged code (0xEF00 prefix) is a valid EOF container
+// EIP-7702 delegation designators (0xEF01 prefix) are not EOF-tagged.
+func validateCode(code []byte, eof bool) error {
+	if len(code) == 0 {
+		return fmt.Errorf("empty code")
+	}
+	if !eof || len(code) < 2 || code[0] != 0xef || code[1] != 0x00 {
+		return nil
+	}
+
+	var container vm.Container
+	if err := container.UnmarshalBinary(code, false); err != nil {
+		return fmt.Errorf("malformed EOF container: %v", err)
+	}
+	if err := container.ValidateCode(&eofJumpTable, false); err != nil {
+		return fmt.Errorf("invalid EOF container: %v", err)
+	}
+	return nil
+}
+
+// checkOmmers ensures that the ommer headers referenced by the block header are all provided
+func checkOmmers(block *input.Block) error {
+	if uncleHash := gethtypes.CalcUncleHash(block.Uncles); uncleHash != block.Header.UncleHash {
+		if len(block.Uncles) == 0 {
+			return fmt.Errorf("missing ommer headers: block references ommers (uncle hash %v) but none were provided", block.Header.UncleHash.Hex())
+		}
+		return fmt.Errorf("invalid ommer headers: computed uncle hash %v does not match block uncle hash %v", uncleHash.Hex(), block.Header.UncleHash.Hex())
+	}
+	return nil
 }
@@ -1,10 +1,43 @@
 package generator
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/zk-pig/src/ethereum"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/ethdb/rpcdb"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/state"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestExecutor(t *testing.T) {
@@ -18,3 +51,1900 @@ func TestExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutorGasRefunded(t *testing.T) {
+	// Contract clearing storage slot 0 (PUSH1 0x00 PUSH1 0x00 SSTORE STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code:    []byte{byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)},
+			Storage: map[gethcommon.Hash]gethcommon.Hash{{}: gethcommon.BigToHash(big.NewInt(1))},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	assert.Positive(t, res.GasRefunded)
+	// Post-London refunds are capped to a fifth of the gas used before refund (EIP-3529)
+	assert.LessOrEqual(t, res.GasRefunded, (res.ProcessResult.GasUsed+res.GasRefunded)/params.RefundQuotientEIP3529)
+}
+
+func TestExecutorMissingOmmers(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(_ *core.BlockGen) {})
+
+	// Reference an ommer in the block header without providing it
+	ommer := &gethtypes.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1)}
+	proverInput.Blocks[0].Header.UncleHash = gethtypes.CalcUncleHash([]*gethtypes.Header{ommer})
+	proverInput.Blocks[0].Uncles = nil
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing ommer headers")
+}
+
+func TestExecutorMaxCodeSize(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(_ *core.BlockGen) {})
+	proverInput.Witness.Codes = append(proverInput.Witness.Codes, make([]byte, DefaultMaxCodeSize+1))
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), crypto.Keccak256Hash(make([]byte, DefaultMaxCodeSize+1)).Hex())
+
+	// Code size check can be disabled
+	_, err = NewExecutor(WithMaxCodeSize(0)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+}
+
+func TestExecutorWithRules(t *testing.T) {
+	// Contract loading storage slot 0 (PUSH1 0x00 SLOAD STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: []byte{byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.STOP)},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		tx, err := gethtypes.SignNewTx(testKey, gethtypes.LatestSigner(testChainConfig), &gethtypes.LegacyTx{
+			Nonce:    b.TxNonce(testAddr),
+			To:       &testContractAddr,
+			Gas:      100_000,
+			GasPrice: new(big.Int).Add(b.BaseFee(), big.NewInt(params.GWei)),
+		})
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+
+	rules := params.Rules{
+		ChainID:          testChainConfig.ChainID,
+		IsHomestead:      true,
+		IsEIP150:         true,
+		IsEIP155:         true,
+		IsEIP158:         true,
+		IsByzantium:      true,
+		IsConstantinople: true,
+		IsPetersburg:     true,
+		IsIstanbul:       true,
+	}
+	istanbulRes, err := NewExecutor(WithRules(&rules)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// Toggle EIP-2929 (cold storage access cost)
+	rules.IsBerlin, rules.IsEIP2929 = true, true
+	berlinRes, err := NewExecutor(WithRules(&rules)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	assert.Equal(t, params.ColdSloadCostEIP2929-params.SloadGasEIP2200, berlinRes.ProcessResult.GasUsed-istanbulRes.ProcessResult.GasUsed)
+
+	// EIP-2929 can not be enabled without Berlin, as no chain configuration yields it
+	rules.IsBerlin = false
+	_, err = NewExecutor(WithRules(&rules)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid custom rules")
+}
+
+func TestExecutorTouchedState(t *testing.T) {
+	// Contract storing 0x2a in slot 1 (PUSH1 0x2a PUSH1 0x01 SSTORE STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: []byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x01, byte(vm.SSTORE), byte(vm.STOP)},
+		},
+	}
+	recipient := gethcommon.HexToAddress("0x000000000000000000000000000000000000beef")
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &recipient, big.NewInt(params.GWei), params.TxGas, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	accounts := make(map[gethcommon.Address]*state.DumpAccount)
+	for _, account := range res.TouchedState.Accounts {
+		accounts[account.Address] = account
+	}
+
+	require.Contains(t, accounts, testAddr)
+	assert.Equal(t, uint64(2), uint64(accounts[testAddr].Nonce))
+
+	require.Contains(t, accounts, recipient)
+	assert.Equal(t, big.NewInt(params.GWei), accounts[recipient].Balance.ToInt())
+	assert.Equal(t, gethtypes.EmptyCodeHash, accounts[recipient].CodeHash)
+
+	require.Contains(t, accounts, testContractAddr)
+	assert.Equal(t, crypto.Keccak256Hash(alloc[testContractAddr].Code), accounts[testContractAddr].CodeHash)
+
+	assert.Equal(t, []*state.DumpSlot{
+		{
+			Address: testContractAddr,
+			Key:     gethcommon.BigToHash(big.NewInt(1)),
+			Value:   gethcommon.BigToHash(big.NewInt(0x2a)),
+		},
+	}, res.TouchedState.Storage)
+}
+
+func TestExecutorShadowFork(t *testing.T) {
+	// Base configuration is a post-merge configuration without Shanghai, the shadow fork activates Shanghai
+	baseCfg := *testChainConfig
+	baseCfg.ShanghaiTime = nil
+	baseCfg.CancunTime = nil
+
+	// Blocks are generated every 10 seconds so block 1 is before the shadow fork and block 2 is just past it
+	shadowTime := uint64(15)
+	cfg, err := ethereum.ShadowForkConfig(&baseCfg, &ethereum.ShadowFork{
+		Time:  &shadowTime,
+		Rules: &params.Rules{IsShanghai: true},
+	})
+	require.NoError(t, err)
+
+	// Contract using PUSH0 (introduced in Shanghai) to store 1 in slot 0 (PUSH1 0x01 PUSH0 SSTORE STOP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH0), byte(vm.SSTORE), byte(vm.STOP)},
+		},
+	}
+	proverInputs := generateTestProverInputs(t, cfg, alloc, 2, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, cfg, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInputs[0])
+	require.NoError(t, err)
+	assert.Equal(t, gethtypes.ReceiptStatusFailed, res.ProcessResult.Receipts[0].Status, "PUSH0 should be invalid before the shadow fork")
+
+	res, err = NewExecutor().Execute(context.Background(), proverInputs[1])
+	require.NoError(t, err)
+	assert.Equal(t, gethtypes.ReceiptStatusSuccessful, res.ProcessResult.Receipts[0].Status, "PUSH0 should be valid past the shadow fork")
+}
+
+func TestExecutorWithCallTracing(t *testing.T) {
+	calleeAddr := gethcommon.HexToAddress("0x00000000000000000000000000000000000ca11e")
+	alloc := gethtypes.GenesisAlloc{
+		// Contract calling the callee with 1 wei (CALL(gas, callee, 1, 0, 0, 0, 0) POP STOP)
+		testContractAddr: {
+			Code: append(append([]byte{
+				byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+				byte(vm.PUSH1), 0x01, byte(vm.PUSH20)}, calleeAddr.Bytes()...),
+				byte(vm.GAS), byte(vm.CALL), byte(vm.POP), byte(vm.STOP),
+			),
+			Balance: big.NewInt(1),
+		},
+		// Callee contract (STOP)
+		calleeAddr: {
+			Code: []byte{byte(vm.STOP)},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, []byte{0x01}))
+	})
+
+	res, err := NewExecutor(WithCallTracing()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	require.Len(t, res.Calls, 1)
+	root := res.Calls[0]
+	assert.Equal(t, vm.CALL, root.Type)
+	assert.Equal(t, testAddr, root.From)
+	assert.Equal(t, testContractAddr, root.To)
+	assert.Equal(t, []byte{0x01}, root.Input)
+	assert.Equal(t, 0, root.Depth)
+	assert.NoError(t, root.Error)
+
+	require.Len(t, root.Calls, 1)
+	internal := root.Calls[0]
+	assert.Equal(t, vm.CALL, internal.Type)
+	assert.Equal(t, testContractAddr, internal.From)
+	assert.Equal(t, calleeAddr, internal.To)
+	assert.Equal(t, big.NewInt(1), internal.Value)
+	assert.Equal(t, 1, internal.Depth)
+	assert.Empty(t, internal.Calls)
+
+	// Call tree is not captured by default
+	res, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, res.Calls)
+}
+
+func TestExecutorWithCodeLoadTracing(t *testing.T) {
+	contractCode := []byte{byte(vm.PUSH1), 0x01, byte(vm.POP), byte(vm.STOP)}
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: contractCode,
+		},
+		// Contract present in the state but never called
+		gethcommon.HexToAddress("0x00000000000000000000000000000000000abcde"): {
+			Code: []byte{byte(vm.STOP)},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor(WithCodeLoadTracing()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	require.Len(t, res.LoadedCodes, 1)
+	assert.Equal(t, testContractAddr, res.LoadedCodes[0].Address)
+	assert.Equal(t, crypto.Keccak256Hash(contractCode), res.LoadedCodes[0].CodeHash)
+
+	// Loaded codes are not reported by default
+	res, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, res.LoadedCodes)
+}
+
+func TestExecutorWithAssertion(t *testing.T) {
+	// Contract always reverting (REVERT(0, 0))
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: []byte{byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT)},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	noReverts := func(res *Result) error {
+		for i, receipt := range res.ProcessResult.Receipts {
+			if receipt.Status == gethtypes.ReceiptStatusFailed {
+				return fmt.Errorf("transaction %d reverted", i)
+			}
+		}
+		return nil
+	}
+
+	res, err := NewExecutor(WithAssertion(noReverts)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post-execution assertion 0 failed: transaction 0 reverted")
+	// The result is still returned for inspection
+	require.NotNil(t, res)
+	assert.Len(t, res.ProcessResult.Receipts, 1)
+
+	// Execution succeeds without assertions
+	_, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+}
+
+func TestExecutorCoinbaseFees(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+		// Transaction whose priority fee is capped by its max fee
+		tx, err := gethtypes.SignNewTx(testKey, gethtypes.LatestSigner(testChainConfig), &gethtypes.DynamicFeeTx{
+			ChainID:   testChainConfig.ChainID,
+			Nonce:     b.TxNonce(testAddr),
+			To:        &testContractAddr,
+			Gas:       params.TxGas,
+			GasTipCap: big.NewInt(2 * params.GWei),
+			GasFeeCap: big.NewInt(2 * params.GWei),
+		})
+		require.NoError(t, err)
+		b.AddTx(tx)
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// Only the priority fees are credited to the coinbase (base fees are burnt)
+	block := proverInput.Blocks[0]
+	require.Positive(t, block.Header.BaseFee.Sign())
+	expected := new(big.Int)
+	for i, tx := range block.Transactions {
+		tip := new(big.Int).Sub(tx.GasFeeCap(), block.Header.BaseFee)
+		if tx.GasTipCap().Cmp(tip) < 0 {
+			tip = tx.GasTipCap()
+		}
+		expected.Add(expected, new(big.Int).Mul(tip, new(big.Int).SetUint64(res.ProcessResult.Receipts[i].GasUsed)))
+	}
+	assert.Equal(t, expected, res.CoinbaseFees)
+}
+
+func TestExecutorAncestorsFile(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	// Move ancestors to a separate file
+	ancestorsFile := filepath.Join(t.TempDir(), "ancestors.json")
+	b, err := json.Marshal(proverInput.Witness.Ancestors)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(ancestorsFile, b, 0o600))
+	proverInput.Witness.Ancestors = nil
+	proverInput.Witness.AncestorsFile = ancestorsFile
+
+	_, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// Missing ancestors file
+	proverInput.Witness.AncestorsFile = filepath.Join(t.TempDir(), "missing.json")
+	_, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read ancestors file")
+	assert.Contains(t, err.Error(), "missing.json")
+}
+
+func TestExecutorMaxBlockSize(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, make([]byte, 1024)))
+	})
+	size := proverInput.Blocks[0].Block().Size()
+
+	_, err := NewExecutor(WithMaxBlockSize(size-1)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block exceeds maximum size")
+
+	_, err = NewExecutor(WithMaxBlockSize(size)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+}
+
+// testPrecompile is a trivial precompile returning a constant output
+type testPrecompile struct {
+	calls int
+}
+
+func (p *testPrecompile) RequiredGas(_ []byte) uint64 { return 0 }
+
+func (p *testPrecompile) Run(_ []byte) ([]byte, error) {
+	p.calls++
+	return []byte("custom precompile"), nil
+}
+
+func TestExecutorWithPrecompiles(t *testing.T) {
+	precompileAddr := gethcommon.HexToAddress("0x0000000000000000000000000000000000000100")
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &precompileAddr, nil, 100_000, nil))
+	})
+
+	precompile := new(testPrecompile)
+	res, err := NewExecutor(
+		WithPrecompiles(map[gethcommon.Address]vm.PrecompiledContract{precompileAddr: precompile}),
+		WithCallTracing(),
+	).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, precompile.calls)
+	require.Len(t, res.Calls, 1)
+	assert.Equal(t, []byte("custom precompile"), res.Calls[0].Output)
+}
+
+func TestExecutorStateTestMode(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+		b.AddWithdrawal(&gethtypes.Withdrawal{Validator: 1, Address: testContractAddr, Amount: 1})
+	})
+	// Consensus related header fields are inconsistent with the block
+	proverInput.Blocks[0].Header.ReceiptHash = gethcommon.Hash{0x1}
+	proverInput.Blocks[0].Header.GasUsed++
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+
+	// State test mode only validates the resulting state root
+	_, err = NewExecutor(WithStateTestMode()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	expectedRoot := proverInput.Blocks[0].Header.Root
+	proverInput.Blocks[0].Header.Root = gethcommon.Hash{0x1}
+	_, err = NewExecutor(WithStateTestMode()).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "state root mismatch")
+	assert.Contains(t, err.Error(), expectedRoot.Hex())
+}
+
+func TestExecutorWithErrorHook(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(_ *core.BlockGen) {})
+	proverInput.Witness.Ancestors = nil
+
+	var (
+		hookCalls int
+		hookBlock uint64
+		hookErr   error
+	)
+	e := NewExecutor(WithErrorHook(func(_ context.Context, inputs *input.ProverInput, err error) {
+		hookCalls++
+		hookBlock = inputs.Blocks[0].Header.Number.Uint64()
+		hookErr = err
+	}))
+
+	_, err := e.Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Equal(t, 1, hookCalls)
+	assert.Equal(t, uint64(1), hookBlock)
+	assert.Equal(t, err, hookErr)
+}
+
+func TestExecutorWithBlockRewards(t *testing.T) {
+	// Pre-merge configuration (terminal total difficulty is never reached)
+	cfg := *testChainConfig
+	cfg.TerminalTotalDifficulty = new(big.Int).Lsh(big.NewInt(1), 128)
+	cfg.ShanghaiTime = nil
+	cfg.CancunTime = nil
+
+	miner := gethcommon.HexToAddress("0x000000000000000000000000000000000000b10c")
+	proverInput := generateTestProverInput(t, &cfg, nil, func(b *core.BlockGen) {
+		b.SetCoinbase(miner)
+	})
+
+	minerBalance := func(res *Result) *big.Int {
+		for _, account := range res.TouchedState.Accounts {
+			if account.Address == miner {
+				return account.Balance.ToInt()
+			}
+		}
+		return nil
+	}
+
+	// Standard reward
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2e18), minerBalance(res))
+
+	// Custom reward
+	res, err = NewExecutor(WithBlockRewards(&ethereum.BlockRewards{
+		BlockReward: uint256.NewInt(params.Ether / 2),
+	})).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(params.Ether/2), minerBalance(res))
+}
+
+func TestExecutorPreStateRootMismatch(t *testing.T) {
+	to := gethcommon.HexToAddress("0xc0ffee")
+	proverInputs := generateTestProverInputs(t, testChainConfig, nil, 2, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &to, big.NewInt(1), 21_000, nil))
+	})
+
+	// Pair the witness of the first block with the parent header of the second block
+	// (the block parent hash is updated so the ancestors still hash to the hashes claimed by their children)
+	proverInput := proverInputs[0]
+	proverInput.Witness.Ancestors = proverInputs[1].Witness.Ancestors
+	proverInput.Blocks[0].Header.ParentHash = proverInputs[1].Blocks[0].Header.ParentHash
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-state root mismatch")
+}
+
+func TestExecutorExecErrors(t *testing.T) {
+	invalidAddr := gethcommon.HexToAddress("0xbad")
+	alloc := gethtypes.GenesisAlloc{
+		// Contract looping until out of gas (JUMPDEST PUSH1 0x00 JUMP)
+		testContractAddr: {Code: []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0x00, byte(vm.JUMP)}},
+		// Contract executing an invalid opcode
+		invalidAddr: {Code: []byte{byte(vm.INVALID)}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testAddr, big.NewInt(1), 21_000, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.Len(t, res.ProcessResult.Receipts, 2)
+	assert.Equal(t, gethtypes.ReceiptStatusFailed, res.ProcessResult.Receipts[1].Status)
+
+	assert.True(t, res.OutOfGas)
+	assert.Equal(t, []int{1}, res.OutOfGasTxs)
+	assert.False(t, res.InvalidOpcode)
+	assert.Empty(t, res.InvalidOpcodeTxs)
+
+	proverInput = generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &invalidAddr, nil, 100_000, nil))
+	})
+
+	res, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.InvalidOpcode)
+	assert.Equal(t, []int{0}, res.InvalidOpcodeTxs)
+	assert.False(t, res.OutOfGas)
+}
+
+func TestExecutorWithMemorySampling(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 21_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Zero(t, res.PeakHeapAlloc)
+
+	res, err = NewExecutor(WithMemorySampling(time.Millisecond)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Positive(t, res.PeakHeapAlloc)
+}
+
+func TestExecutorWithChainConfig(t *testing.T) {
+	// Contract reading the blob base fee (BLOBBASEFEE STOP), the opcode is only valid from Cancun
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.BLOBBASEFEE), byte(vm.STOP)}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, res.InvalidOpcode)
+	assert.Empty(t, res.ChainConfigOverrides)
+
+	// Externally move Cancun activation, still before the block
+	cancunTime := uint64(1)
+	override := &params.ChainConfig{
+		ChainID:    testChainConfig.ChainID, // same as input, not reported
+		CancunTime: &cancunTime,
+	}
+	res, err = NewExecutor(WithChainConfig(override)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cancunTime"}, res.ChainConfigOverrides)
+	assert.False(t, res.InvalidOpcode)
+
+	// Externally postpone Cancun activation after the block, the block Cancun fields are then unexpected
+	cancunTime = proverInput.Blocks[0].Header.Time + 1
+	_, err = NewExecutor(WithChainConfig(override)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected blob gas used")
+}
+
+func TestExecutorCancunBoundary(t *testing.T) {
+	// Blocks are generated every 10 seconds, so block 1 is the last pre-Cancun block and block 2 the first Cancun block
+	cancunTime := uint64(20)
+	cfg := *testChainConfig
+	cfg.CancunTime = &cancunTime
+
+	to := gethcommon.HexToAddress("0xc0ffee")
+	proverInputs := generateTestProverInputs(t, &cfg, nil, 2, func(i int, b *core.BlockGen) {
+		b.AddWithdrawal(&gethtypes.Withdrawal{Index: uint64(i), Address: to, Amount: 1})
+		b.AddTx(signTestTx(t, b, &cfg, &to, big.NewInt(1), 21_000, nil))
+	})
+	shanghaiInput, cancunInput := proverInputs[0], proverInputs[1]
+	require.False(t, cfg.IsCancun(shanghaiInput.Blocks[0].Header.Number, shanghaiInput.Blocks[0].Header.Time))
+	require.True(t, cfg.IsCancun(cancunInput.Blocks[0].Header.Number, cancunInput.Blocks[0].Header.Time))
+
+	t.Run("last pre-Cancun block", func(t *testing.T) {
+		_, err := NewExecutor().Execute(context.Background(), shanghaiInput)
+		require.NoError(t, err)
+
+		// Blob gas fields are not expected before Cancun
+		header := gethtypes.CopyHeader(shanghaiInput.Blocks[0].Header)
+		header.ExcessBlobGas = new(uint64)
+		header.BlobGasUsed = new(uint64)
+		_, err = NewExecutor().Execute(context.Background(), withHeader(shanghaiInput, header))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid block fields: unexpected blob gas used")
+
+		// Withdrawals are required from Shanghai
+		header = gethtypes.CopyHeader(shanghaiInput.Blocks[0].Header)
+		header.WithdrawalsHash = nil
+		_, err = NewExecutor().Execute(context.Background(), withHeader(shanghaiInput, header))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid block fields: missing withdrawals root")
+	})
+
+	t.Run("first Cancun block", func(t *testing.T) {
+		_, err := NewExecutor().Execute(context.Background(), cancunInput)
+		require.NoError(t, err)
+
+		// Parent beacon root is required from Cancun
+		header := gethtypes.CopyHeader(cancunInput.Blocks[0].Header)
+		header.ParentBeaconRoot = nil
+		_, err = NewExecutor().Execute(context.Background(), withHeader(cancunInput, header))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid block fields: missing parent beacon root")
+
+		// Withdrawals must match the withdrawals root
+		inputs := withHeader(cancunInput, cancunInput.Blocks[0].Header)
+		inputs.Blocks[0].Withdrawals = nil
+		_, err = NewExecutor().Execute(context.Background(), inputs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid block fields: withdrawals root mismatch")
+	})
+}
+
+// withHeader returns a copy of the prover input with the given block header
+func withHeader(proverInput *input.ProverInput, header *gethtypes.Header) *input.ProverInput {
+	block := *proverInput.Blocks[0]
+	block.Header = header
+	cpy := *proverInput
+	cpy.Blocks = []*input.Block{&block}
+	return &cpy
+}
+
+// resetTxs replaces the block transactions with fresh copies, so senders cached by a previous execution are dropped
+func resetTxs(t testing.TB, proverInput *input.ProverInput) {
+	for i, tx := range proverInput.Blocks[0].Transactions {
+		b, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		fresh := new(gethtypes.Transaction)
+		require.NoError(t, fresh.UnmarshalBinary(b))
+		proverInput.Blocks[0].Transactions[i] = fresh
+	}
+}
+
+func TestExecutorWithPrewarm(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	serial, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	for _, workers := range []int{0, 1, 3} {
+		resetTxs(t, proverInput)
+		prewarmed, err := NewExecutor(WithPrewarm(workers)).Execute(context.Background(), proverInput)
+		require.NoError(t, err)
+
+		assert.Equal(t, serial.ProcessResult.GasUsed, prewarmed.ProcessResult.GasUsed)
+		assert.Equal(t, gethtypes.DeriveSha(gethtypes.Receipts(serial.ProcessResult.Receipts), trie.NewStackTrie(nil)), gethtypes.DeriveSha(gethtypes.Receipts(prewarmed.ProcessResult.Receipts), trie.NewStackTrie(nil)))
+		assert.Equal(t, serial.GasRefunded, prewarmed.GasRefunded)
+		assert.Equal(t, serial.TouchedState, prewarmed.TouchedState)
+	}
+}
+
+// BenchmarkExecutorWithPrewarm benchmarks the execution of a mainnet block with and without pre-warming transaction senders
+func BenchmarkExecutorWithPrewarm(b *testing.B) {
+	proverInput := &loadTestDataInputs(b, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	bench := func(b *testing.B, e Executor) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			resetTxs(b, proverInput)
+			b.StartTimer()
+			_, err := e.Execute(context.Background(), proverInput)
+			require.NoError(b, err)
+		}
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		bench(b, NewExecutor())
+	})
+
+	b.Run("prewarm", func(b *testing.B) {
+		bench(b, NewExecutor(WithPrewarm(0)))
+	})
+}
+
+func TestExecutorWithoutPrefetching(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	resetTxs(t, proverInput)
+	prefetched, err := NewExecutor(WithPrewarm(0)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	resetTxs(t, proverInput)
+	sequential, err := NewExecutor(WithPrewarm(0), WithoutPrefetching()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	assert.True(t, sequential.Validated)
+	assert.Equal(t, prefetched.ProcessResult.GasUsed, sequential.ProcessResult.GasUsed)
+	assert.Equal(t, gethtypes.DeriveSha(gethtypes.Receipts(prefetched.ProcessResult.Receipts), trie.NewStackTrie(nil)), gethtypes.DeriveSha(gethtypes.Receipts(sequential.ProcessResult.Receipts), trie.NewStackTrie(nil)))
+	assert.Equal(t, prefetched.GasRefunded, sequential.GasRefunded)
+	assert.Equal(t, prefetched.TouchedState, sequential.TouchedState)
+}
+
+// BenchmarkExecutorWithoutPrefetching benchmarks the execution of a mainnet block with and without state prefetching
+func BenchmarkExecutorWithoutPrefetching(b *testing.B) {
+	proverInput := &loadTestDataInputs(b, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	bench := func(b *testing.B, e Executor) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			resetTxs(b, proverInput)
+			b.StartTimer()
+			_, err := e.Execute(context.Background(), proverInput)
+			require.NoError(b, err)
+		}
+	}
+
+	b.Run("prefetch", func(b *testing.B) {
+		bench(b, NewExecutor())
+	})
+
+	b.Run("sequential", func(b *testing.B) {
+		bench(b, NewExecutor(WithoutPrefetching()))
+	})
+}
+
+func TestExecutorWithoutGlobalMetrics(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	inputs := []*input.ProverInput{
+		&loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput,
+		generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+			b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+		}),
+	}
+
+	expected := make([]*Result, len(inputs))
+	for i, proverInput := range inputs {
+		res, err := NewExecutor().Execute(context.Background(), proverInput)
+		require.NoError(t, err)
+		expected[i] = res
+	}
+
+	// Executions run concurrently must not interfere (run with -race to detect data races)
+	results := make([]*Result, len(inputs))
+	errs := make([]error, len(inputs))
+	var wg sync.WaitGroup
+	for i, proverInput := range inputs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = NewExecutor(WithoutGlobalMetrics()).Execute(context.Background(), proverInput)
+		}()
+	}
+	wg.Wait()
+
+	for i := range inputs {
+		require.NoError(t, errs[i])
+		assert.Equal(t, expected[i].ProcessResult.GasUsed, results[i].ProcessResult.GasUsed)
+		assert.Equal(t, expected[i].GasRefunded, results[i].GasRefunded)
+		assert.Equal(t, expected[i].TouchedState, results[i].TouchedState)
+
+		// The state prefetcher registering go-ethereum global meters is not started, so no witness is recorded
+		assert.NotNil(t, expected[i].witness)
+		assert.Nil(t, results[i].witness)
+	}
+}
+
+// noRewardsEngine is an ethash engine crediting no block rewards
+type noRewardsEngine struct {
+	consensus.Engine
+}
+
+func (e *noRewardsEngine) Finalize(_ consensus.ChainHeaderReader, _ *gethtypes.Header, _ vm.StateDB, _ *gethtypes.Body) {
+}
+
+func (e *noRewardsEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *gethtypes.Header, statedb *gethstate.StateDB, body *gethtypes.Body, receipts []*gethtypes.Receipt) (*gethtypes.Block, error) {
+	header.Root = statedb.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	return gethtypes.NewBlock(header, body, receipts, trie.NewStackTrie(nil)), nil
+}
+
+func TestExecutorEmptyCoinbase(t *testing.T) {
+	coinbase := gethcommon.HexToAddress("0x000000000000000000000000000000000000c0b5")
+	recipient := gethcommon.HexToAddress("0x0000000000000000000000000000000000000bee")
+
+	// Pre EIP-158 chain without block rewards, so the coinbase is only touched by the zero fee payment
+	preEIP158Cfg := &params.ChainConfig{
+		ChainID:        big.NewInt(1337),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+
+		TerminalTotalDifficulty: new(big.Int).Lsh(big.NewInt(1), 128), // Never reached, the chain stays pre-merge
+	}
+	noRewards := &ethereum.BlockRewards{BlockReward: new(uint256.Int)}
+
+	tests := []struct {
+		desc      string
+		cfg       *params.ChainConfig
+		alloc     gethtypes.GenesisAlloc
+		opts      []ExecutorOption
+		engine    consensus.Engine
+		tx        func(b *core.BlockGen) gethtypes.TxData
+		postExist bool
+	}{
+		{
+			desc:  "post-London empty coinbase touched by zero tip is deleted",
+			cfg:   testChainConfig,
+			alloc: gethtypes.GenesisAlloc{coinbase: {Balance: new(big.Int)}},
+			tx: func(b *core.BlockGen) gethtypes.TxData {
+				return &gethtypes.DynamicFeeTx{ChainID: testChainConfig.ChainID, Nonce: b.TxNonce(testAddr), To: &recipient, Value: big.NewInt(1), Gas: params.TxGas, GasTipCap: new(big.Int), GasFeeCap: b.BaseFee()}
+			},
+			postExist: false,
+		},
+		{
+			desc: "post-London missing coinbase touched by zero tip is not created",
+			cfg:  testChainConfig,
+			tx: func(b *core.BlockGen) gethtypes.TxData {
+				return &gethtypes.DynamicFeeTx{ChainID: testChainConfig.ChainID, Nonce: b.TxNonce(testAddr), To: &recipient, Value: big.NewInt(1), Gas: params.TxGas, GasTipCap: new(big.Int), GasFeeCap: b.BaseFee()}
+			},
+			postExist: false,
+		},
+		{
+			desc:   "pre-EIP-158 missing coinbase touched by zero fee is created empty",
+			cfg:    preEIP158Cfg,
+			opts:   []ExecutorOption{WithBlockRewards(noRewards)},
+			engine: &noRewardsEngine{Engine: ethash.NewFaker()},
+			tx: func(b *core.BlockGen) gethtypes.TxData {
+				return &gethtypes.LegacyTx{Nonce: b.TxNonce(testAddr), To: &recipient, Value: big.NewInt(1), Gas: params.TxGas, GasPrice: new(big.Int)}
+			},
+			postExist: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			engine := test.engine
+			if engine == nil {
+				engine = beacon.New(ethash.NewFaker())
+			}
+			proverInput := generateTestProverInputsWithEngine(t, test.cfg, engine, test.alloc, 1, func(_ int, b *core.BlockGen) {
+				b.SetCoinbase(coinbase)
+				tx, err := gethtypes.SignNewTx(testKey, gethtypes.MakeSigner(test.cfg, b.Number(), b.Timestamp()), test.tx(b))
+				require.NoError(t, err)
+				b.AddTx(tx)
+			})[0]
+
+			res, err := NewExecutor(test.opts...).Execute(context.Background(), proverInput)
+			require.NoError(t, err)
+			assert.Equal(t, proverInput.Blocks[0].Header.Root, res.Attestation().PostStateRoot)
+
+			exist := false
+			for _, account := range res.TouchedState.Accounts {
+				if account.Address == coinbase {
+					exist = true
+					assert.Zero(t, account.Balance.ToInt().Sign())
+				}
+			}
+			assert.Equal(t, test.postExist, exist)
+		})
+	}
+}
+
+func TestExecutorContractCreationCollision(t *testing.T) {
+	// Address of the contract created by the first transaction of the test account
+	created := crypto.CreateAddress(testAddr, 0)
+
+	// Init code deploying a single STOP opcode (PUSH1 0x01 PUSH1 0x00 RETURN, memory is zero initialized)
+	initCode := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.RETURN)}
+	deployedCodeHash := crypto.Keccak256Hash([]byte{byte(vm.STOP)})
+
+	// Pre-merge chain with neither EIP-158 nor Byzantium (no receipt status)
+	homesteadCfg := &params.ChainConfig{
+		ChainID:        big.NewInt(1337),
+		HomesteadBlock: big.NewInt(0),
+		EIP150Block:    big.NewInt(0),
+
+		TerminalTotalDifficulty: new(big.Int).Lsh(big.NewInt(1), 128), // Never reached, the chain stays pre-merge
+	}
+
+	// EIP-684 (existing nonce or code) and EIP-7610 (existing storage) apply retroactively, so collisions fail the creation on every fork
+	tests := []struct {
+		desc      string
+		account   gethtypes.Account
+		collision bool
+	}{
+		{desc: "existing nonce", account: gethtypes.Account{Nonce: 1, Balance: new(big.Int)}, collision: true},
+		{desc: "existing code", account: gethtypes.Account{Code: testCounterCode, Balance: new(big.Int)}, collision: true},
+		{desc: "existing storage", account: gethtypes.Account{Storage: map[gethcommon.Hash]gethcommon.Hash{{0x01}: {0x01}}, Balance: new(big.Int)}, collision: true},
+		{desc: "existing balance only", account: gethtypes.Account{Balance: big.NewInt(1)}, collision: false},
+	}
+
+	for _, cfg := range []struct {
+		name string
+		cfg  *params.ChainConfig
+	}{{"cancun", testChainConfig}, {"homestead", homesteadCfg}} {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%s/%s", cfg.name, test.desc), func(t *testing.T) {
+				const gas = 100_000
+				alloc := gethtypes.GenesisAlloc{created: test.account}
+				proverInput := generateTestProverInput(t, cfg.cfg, alloc, func(b *core.BlockGen) {
+					gasPrice := big.NewInt(params.GWei)
+					if cfg.cfg.IsLondon(b.Number()) {
+						gasPrice.Add(gasPrice, b.BaseFee())
+					}
+					tx, err := gethtypes.SignNewTx(testKey, gethtypes.MakeSigner(cfg.cfg, b.Number(), b.Timestamp()), &gethtypes.LegacyTx{
+						Nonce: b.TxNonce(testAddr), Gas: gas, GasPrice: gasPrice, Data: initCode,
+					})
+					require.NoError(t, err)
+					b.AddTx(tx)
+				})
+
+				res, err := NewExecutor().Execute(context.Background(), proverInput)
+				require.NoError(t, err)
+				assert.True(t, res.Validated)
+				require.Len(t, res.ProcessResult.Receipts, 1)
+				receipt := res.ProcessResult.Receipts[0]
+
+				var account *state.DumpAccount
+				for _, a := range res.TouchedState.Accounts {
+					if a.Address == created {
+						account = a
+					}
+				}
+				require.NotNil(t, account)
+
+				if test.collision {
+					// The creation fails consuming all gas, and the existing account is left untouched
+					assert.Equal(t, uint64(gas), receipt.GasUsed)
+					assert.Equal(t, hexutil.Uint64(test.account.Nonce), account.Nonce)
+					assert.NotEqual(t, deployedCodeHash, account.CodeHash)
+				} else {
+					// The contract is deployed over the existing account, which keeps its balance
+					assert.Less(t, receipt.GasUsed, uint64(gas))
+					assert.Equal(t, deployedCodeHash, account.CodeHash)
+					assert.Equal(t, test.account.Balance, account.Balance.ToInt())
+				}
+			})
+		}
+	}
+}
+
+func TestExecutorWithStepLimit(t *testing.T) {
+	// Contract looping until running out of gas (JUMPDEST PUSH1 0x00 JUMP)
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.JUMPDEST), byte(vm.PUSH1), 0x00, byte(vm.JUMP)}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, nil, nil, 100_000, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	_, err := NewExecutor(WithStepLimit(1_000_000)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	_, err = NewExecutor(WithStepLimit(1000)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("transaction 1 (%v) exceeded the step limit of 1000 (aborted at step 1001)", proverInput.Blocks[0].Transactions[1].Hash().Hex()))
+}
+
+func TestExecutorWithCallDepthLimit(t *testing.T) {
+	// Contract calling itself recursively with all available gas (CALL(GAS, ADDRESS, 0, 0, 0, 0, 0))
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{
+			byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+			byte(vm.ADDRESS), byte(vm.GAS), byte(vm.CALL), byte(vm.STOP),
+		}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 10_000_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, proverInput.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+
+	_, err = NewExecutor(WithCallDepthLimit(64)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("transaction 0 (%v) exceeded the call depth limit of 64 (aborted entering depth 65 at %v)", proverInput.Blocks[0].Transactions[0].Hash().Hex(), testContractAddr.Hex()))
+}
+
+func TestExecutorWithSlowThreshold(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	observed, logs := observer.New(zap.WarnLevel)
+	ctx := log.WithLogger(context.Background(), zap.New(observed))
+
+	_, err := NewExecutor(WithSlowThreshold(time.Hour)).Execute(ctx, proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.FilterMessage("Slow execution").Len())
+
+	_, err = NewExecutor(WithSlowThreshold(time.Nanosecond)).Execute(ctx, proverInput)
+	require.NoError(t, err)
+	slow := logs.FilterMessage("Slow execution").All()
+	require.Len(t, slow, 1)
+	fields := slow[0].ContextMap()
+	for _, key := range []string{"threshold", "total", "prepareContext", "preparePreState", "prepareExecParams", "execEVM"} {
+		assert.Contains(t, fields, key)
+	}
+}
+
+func TestExecutorWithCodePruning(t *testing.T) {
+	code := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: code},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+	unreferenced := []byte{byte(vm.PUSH1), 0x02, byte(vm.STOP)}
+	proverInput.Witness.Codes = append(proverInput.Witness.Codes, unreferenced)
+
+	for _, prune := range []bool{false, true} {
+		var opts []ExecutorOption
+		if prune {
+			opts = append(opts, WithCodePruning())
+		}
+		e := NewExecutor(opts...).(*executor)
+
+		execCtx, err := e.prepareContext(context.Background(), proverInput)
+		require.NoError(t, err)
+		require.NoError(t, e.preparePreState(execCtx, proverInput))
+
+		disk := execCtx.stateDB.TrieDB().Disk()
+		assert.True(t, rawdb.HasCode(disk, crypto.Keccak256Hash(code)))
+		assert.Equal(t, !prune, rawdb.HasCode(disk, crypto.Keccak256Hash(unreferenced)))
+
+		_, err = e.Execute(context.Background(), proverInput)
+		require.NoError(t, err)
+	}
+}
+
+type testReadSink struct {
+	reads []*state.StateRead
+}
+
+func (s *testReadSink) RecordRead(read *state.StateRead) error {
+	s.reads = append(s.reads, read)
+	return nil
+}
+
+func TestExecutorWithReadRecorder(t *testing.T) {
+	code := []byte{byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: code, Storage: map[gethcommon.Hash]gethcommon.Hash{{}: gethcommon.HexToHash("0x2a")}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	sink := new(testReadSink)
+	_, err := NewExecutor(WithReadRecorder(sink)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	accounts := make(map[gethcommon.Address]*gethtypes.StateAccount)
+	slots := make(map[gethcommon.Hash]gethcommon.Hash)
+	for _, read := range sink.reads {
+		assert.Equal(t, proverInput.Witness.Ancestors[0].Root, read.StateRoot)
+		if read.Slot == nil {
+			accounts[read.Address] = read.Account
+			continue
+		}
+		require.Equal(t, testContractAddr, read.Address)
+		slots[*read.Slot] = *read.Value
+	}
+
+	require.Contains(t, accounts, testAddr)
+	require.NotNil(t, accounts[testAddr])
+	require.Contains(t, accounts, testContractAddr)
+	require.NotNil(t, accounts[testContractAddr])
+	assert.Equal(t, crypto.Keccak256(code), accounts[testContractAddr].CodeHash)
+	assert.Equal(t, map[gethcommon.Hash]gethcommon.Hash{{}: gethcommon.HexToHash("0x2a")}, slots)
+}
+
+func TestExecutorWitnessSizePerGas(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	observed, logs := observer.New(zap.WarnLevel)
+	ctx := log.WithLogger(context.Background(), zap.New(observed))
+
+	res, err := NewExecutor(WithWitnessSizePerGasThreshold(10)).Execute(ctx, proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, witnessSize(proverInput.Witness), res.WitnessSize)
+	assert.Equal(t, float64(res.WitnessSize)/float64(params.TxGas), res.WitnessSizePerGas)
+	assert.Equal(t, 0, logs.FilterMessage("Witness size per gas above threshold").Len())
+
+	// Bloat the witness with unreferenced nodes
+	for i := 0; i < 16; i++ {
+		proverInput.Witness.State = append(proverInput.Witness.State, append([]byte{byte(i)}, make([]byte, 32*1024)...))
+	}
+	res, err = NewExecutor(WithWitnessSizePerGasThreshold(10)).Execute(ctx, proverInput)
+	require.NoError(t, err)
+	assert.Greater(t, res.WitnessSizePerGas, float64(10))
+	warnings := logs.FilterMessage("Witness size per gas above threshold").All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, res.WitnessSizePerGas, warnings[0].ContextMap()["ratio"])
+}
+
+func TestExecutorWithBuiltInput(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	generated := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	builder := input.NewBuilder().
+		WithBlock(generated.Blocks[0].Block()).
+		WithChainConfig(generated.ChainConfig)
+	for _, code := range generated.Witness.Codes {
+		builder.AddCode(code)
+	}
+	for _, node := range generated.Witness.State {
+		builder.AddStateNode(node)
+	}
+	for _, ancestor := range generated.Witness.Ancestors {
+		builder.AddAncestor(ancestor)
+	}
+	proverInput, err := builder.Build()
+	require.NoError(t, err)
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, generated.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+}
+
+func TestExecutorPartialResult(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	newInput := func() *input.ProverInput {
+		return generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+			b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+			b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+		})
+	}
+
+	t.Run("validation failure", func(t *testing.T) {
+		proverInput := newInput()
+		gasUsed := proverInput.Blocks[0].Header.GasUsed
+		proverInput.Blocks[0].Header.Root = gethcommon.Hash{0x01}
+
+		res, err := NewExecutor().Execute(context.Background(), proverInput)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid merkle root")
+		require.NotNil(t, res)
+		assert.Len(t, res.ProcessResult.Receipts, 2)
+		assert.Equal(t, gasUsed, res.ProcessResult.GasUsed)
+	})
+
+	t.Run("processing failure", func(t *testing.T) {
+		proverInput := newInput()
+		// Second transaction replays the first one, which fails on nonce
+		txs := proverInput.Blocks[0].Transactions
+		txs[1] = txs[0]
+
+		res, err := NewExecutor().Execute(context.Background(), proverInput)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not apply tx 1")
+		require.NotNil(t, res)
+		require.Len(t, res.ProcessResult.Receipts, 1)
+		assert.Equal(t, txs[0].Hash(), res.ProcessResult.Receipts[0].TxHash)
+		assert.Equal(t, res.ProcessResult.Receipts[0].GasUsed, res.ProcessResult.GasUsed)
+	})
+}
+
+func TestExecutorWithAncestorsSorting(t *testing.T) {
+	to := gethcommon.HexToAddress("0xc0ffee")
+	proverInputs := generateTestProverInputs(t, testChainConfig, nil, 4, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &to, big.NewInt(1), 21_000, nil))
+	})
+	proverInput := proverInputs[3]
+	ancestors := []*gethtypes.Header{proverInputs[2].Blocks[0].Header, proverInputs[1].Blocks[0].Header, proverInputs[0].Blocks[0].Header}
+	require.Equal(t, ancestors[0].Hash(), proverInput.Witness.Ancestors[0].Hash())
+
+	proverInput.Witness.Ancestors = []*gethtypes.Header{ancestors[2], ancestors[0], ancestors[1]}
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+
+	e := NewExecutor(WithAncestorsSorting()).(*executor)
+	execCtx, err := e.prepareContext(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.NoError(t, e.preparePreState(execCtx, proverInput))
+	assert.Equal(t, ancestors, execCtx.ancestors)
+
+	_, err = e.Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// Ancestors with a gap are rejected
+	proverInput.Witness.Ancestors = []*gethtypes.Header{ancestors[2], ancestors[0]}
+	_, err = e.Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ancestors are not contiguous")
+}
+
+func TestExecutorWithGasLimitOverride(t *testing.T) {
+	to := gethcommon.HexToAddress("0xc0ffee")
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &to, big.NewInt(1), 21_000, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &to, big.NewInt(1), 21_000, nil))
+	})
+
+	// Every transaction fits in the raised gas limit
+	res, err := NewExecutor(WithGasLimitOverride(2*proverInput.Blocks[0].Header.GasLimit)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Len(t, res.ProcessResult.Receipts, 2)
+
+	// Only the first transaction fits in the lowered gas limit
+	res, err = NewExecutor(WithGasLimitOverride(30_000)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not apply tx 1")
+	assert.Contains(t, err.Error(), "gas limit reached")
+	require.NotNil(t, res)
+	assert.Len(t, res.ProcessResult.Receipts, 1)
+}
+
+func TestExecutorTamperedAncestor(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	tampered := *proverInput.Witness.Ancestors[0]
+	tampered.Extra = []byte("tampered")
+	proverInput.Witness.Ancestors[0] = &tampered
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ancestors: ancestor 0 (block 0) hashes to")
+}
+
+func TestExecutorWithSilentLogging(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	// Both the context and the global loggers are ignored
+	observed, logs := observer.New(zap.DebugLevel)
+	defer zap.ReplaceGlobals(zap.New(observed))()
+	ctx := log.WithLogger(context.Background(), zap.New(observed))
+
+	_, err := NewExecutor().Execute(ctx, proverInput)
+	require.NoError(t, err)
+	require.Positive(t, logs.Len())
+
+	logs.TakeAll()
+	_, err = NewExecutor(WithSilentLogging(), WithSlowThreshold(time.Nanosecond)).Execute(ctx, proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.Len())
+
+	// Failures are not logged either
+	_, err = NewExecutor(WithSilentLogging()).Execute(ctx, &input.ProverInput{})
+	require.Error(t, err)
+	assert.Equal(t, 0, logs.Len())
+}
+
+func TestExecutorValidated(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+	assert.Equal(t, []string{"feeCaps", "blobGas", "effectiveGasPrices", "requests", "gasUsed", "bloom", "receiptsRoot", "stateRoot"}, res.ValidationChecks)
+
+	res, err = NewExecutor(WithStateTestMode()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+	assert.Equal(t, []string{"stateRoot"}, res.ValidationChecks)
+
+	// Dry run executions are not validated, even against an inconsistent block
+	proverInput.Blocks[0].Header.GasUsed++
+	res, err = NewExecutor(WithDryRun()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, res.Validated)
+	assert.Empty(t, res.ValidationChecks)
+}
+
+func TestExecutorFeeRecipient(t *testing.T) {
+	opConfig := *testChainConfig
+	opConfig.ChainID = big.NewInt(10)
+	generate := func(coinbase gethcommon.Address) *input.ProverInput {
+		return generateTestProverInput(t, &opConfig, nil, func(b *core.BlockGen) {
+			b.SetCoinbase(coinbase)
+			b.AddTx(signTestTx(t, b, &opConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+		})
+	}
+
+	// OP Stack blocks credit fees to the sequencer fee vault
+	res, err := NewExecutor().Execute(context.Background(), generate(OPSequencerFeeVault))
+	require.NoError(t, err)
+	assert.Contains(t, res.ValidationChecks, "feeRecipient")
+	require.Positive(t, res.CoinbaseFees.Sign())
+	var vaultBalance *big.Int
+	for _, account := range res.TouchedState.Accounts {
+		if account.Address == OPSequencerFeeVault {
+			vaultBalance = account.Balance.ToInt()
+		}
+	}
+	assert.Equal(t, res.CoinbaseFees, vaultBalance)
+
+	proverInput := generate(gethcommon.Address{0x1})
+	_, err = NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid fee recipient")
+
+	// An explicit fee recipient overrides the chain fee recipient
+	_, err = NewExecutor(WithFeeRecipient(gethcommon.Address{0x1})).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+}
+
+func TestExecutorWithGasTarget(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		for i := 0; i < 3; i++ {
+			b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+		}
+	})
+	full, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, full.GasTargetReached)
+
+	// The target is reached during the second transaction
+	target := full.ProcessResult.Receipts[0].CumulativeGasUsed + 1
+	res, err := NewExecutor(WithGasTarget(target)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, res.Validated)
+	require.NotNil(t, res.GasTargetReached)
+	assert.Equal(t, 1, res.GasTargetReached.TxIndex)
+	assert.Equal(t, proverInput.Blocks[0].Transactions[1].Hash(), res.GasTargetReached.TxHash)
+	assert.Equal(t, full.ProcessResult.Receipts[1].CumulativeGasUsed, res.GasTargetReached.CumulativeGasUsed)
+	assert.Len(t, res.ProcessResult.Receipts, 2)
+	assert.Equal(t, res.GasTargetReached.CumulativeGasUsed, res.ProcessResult.GasUsed)
+
+	// The intermediate state holds the effects of the first two transactions only
+	var counter gethcommon.Hash
+	for _, slot := range res.TouchedState.Storage {
+		if slot.Address == testContractAddr && slot.Key == (gethcommon.Hash{}) {
+			counter = slot.Value
+		}
+	}
+	assert.Equal(t, gethcommon.BigToHash(big.NewInt(2)), counter)
+
+	// A target above the block gas used executes the whole block
+	res, err = NewExecutor(WithGasTarget(full.ProcessResult.GasUsed+1)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, res.GasTargetReached)
+	assert.Len(t, res.ProcessResult.Receipts, 3)
+}
+
+// flakyDebugAPI serves trie nodes with the debug_dbGet RPC method, failing the first calls
+type flakyDebugAPI struct {
+	nodes    map[gethcommon.Hash][]byte
+	failures int
+	calls    int
+}
+
+func (api *flakyDebugAPI) DbGet(key string) (hexutil.Bytes, error) {
+	api.calls++
+	if api.calls <= api.failures {
+		return nil, fmt.Errorf("transient failure")
+	}
+	blob, ok := api.nodes[gethcommon.HexToHash(key)]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return blob, nil
+}
+
+func TestExecutorWithNodeFallback(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	// Drop a node other than the pre-state root from the witness
+	preRoot := proverInput.Witness.Ancestors[0].Root
+	var dropped hexutil.Bytes
+	for i, node := range proverInput.Witness.State {
+		if crypto.Keccak256Hash(node) != preRoot {
+			dropped = node
+			proverInput.Witness.State = append(proverInput.Witness.State[:i:i], proverInput.Witness.State[i+1:]...)
+			break
+		}
+	}
+	require.NotNil(t, dropped)
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+
+	newFetcher := func(api *flakyDebugAPI) rpcdb.NodeFetcher {
+		server := gethrpc.NewServer()
+		require.NoError(t, server.RegisterName("debug", api))
+		t.Cleanup(server.Stop)
+		return rpcdb.NewDebugDBNodeFetcher(gethrpc.DialInProc(server))
+	}
+	nodes := map[gethcommon.Hash][]byte{crypto.Keccak256Hash(dropped): dropped}
+
+	// Retries are bounded
+	api := &flakyDebugAPI{nodes: nodes, failures: 1000}
+	_, err = NewExecutor(WithNodeFallback(newFetcher(api), 2)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	// Every lookup of the node is attempted 3 times
+	require.Positive(t, api.calls)
+	assert.Zero(t, api.calls%3)
+
+	// The missing node is fetched on retry
+	api = &flakyDebugAPI{nodes: nodes, failures: 1}
+	_, err = NewExecutor(WithNodeFallback(newFetcher(api), 2)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.calls)
+}
+
+func TestExecutorWithNodeFallbackPreStateRoot(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	// Drop the pre-state root node from the witness
+	preRoot := proverInput.Witness.Ancestors[0].Root
+	var dropped hexutil.Bytes
+	for i, node := range proverInput.Witness.State {
+		if crypto.Keccak256Hash(node) == preRoot {
+			dropped = node
+			proverInput.Witness.State = append(proverInput.Witness.State[:i:i], proverInput.Witness.State[i+1:]...)
+			break
+		}
+	}
+	require.NotNil(t, dropped)
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pre-state root mismatch")
+
+	// The root node is fetched rather than failing the pre-state root check
+	server := gethrpc.NewServer()
+	require.NoError(t, server.RegisterName("debug", &flakyDebugAPI{nodes: map[gethcommon.Hash][]byte{preRoot: dropped}}))
+	defer server.Stop()
+	res, err := NewExecutor(WithNodeFallback(rpcdb.NewDebugDBNodeFetcher(gethrpc.DialInProc(server)), 0)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+}
+
+func TestExecutorWithGasBreakdown(t *testing.T) {
+	// The storage contract writes 10 fresh storage slots, it is called by a contract forwarding all its gas
+	storageAddr := gethcommon.HexToAddress("0x00000000000000000000000000000000000c0de1")
+	storageCode := make([]byte, 0)
+	for i := byte(1); i <= 10; i++ {
+		storageCode = append(storageCode, byte(vm.PUSH1), i, byte(vm.PUSH1), i, byte(vm.SSTORE))
+	}
+	storageCode = append(storageCode, byte(vm.STOP))
+	callerCode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH20),
+	}
+	callerCode = append(callerCode, storageAddr.Bytes()...)
+	callerCode = append(callerCode, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: callerCode}, storageAddr: {Code: storageCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 500_000, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, res.GasByCategory)
+
+	res, err = NewExecutor(WithGasBreakdown()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GasByCategory)
+
+	// Storage dominates the breakdown
+	storage := res.GasByCategory[evm.OpcodeCategoryStorage]
+	assert.Equal(t, uint64(10*(params.SstoreSetGasEIP2200+params.ColdSloadCostEIP2929)), storage)
+	for category, gas := range res.GasByCategory {
+		if category != evm.OpcodeCategoryStorage {
+			assert.Less(t, gas, storage, category)
+		}
+	}
+
+	// The gas forwarded to the storage contract is not attributed to the call, so opcodes account for the gas used besides the intrinsic gas
+	assert.Less(t, res.GasByCategory[evm.OpcodeCategoryCall], uint64(10_000))
+	var total uint64
+	for _, gas := range res.GasByCategory {
+		total += gas
+	}
+	assert.Equal(t, res.ProcessResult.GasUsed-params.TxGas, total)
+}
+
+func TestExecutorRejectsFutureVersion(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 21000, nil))
+	})
+
+	// Inputs generated before versioning are migrated
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	future := *proverInput
+	future.Version = fmt.Sprint(input.SchemaVersion + 1)
+	_, err = NewExecutor().Execute(context.Background(), &future)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported future version")
+}
+
+func TestExecutorWithDeploymentOverride(t *testing.T) {
+	// The factory deploys an empty contract with CREATE2 (salt 0) and stores the deployed address in slot 0
+	factoryCode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.CREATE2),
+		byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: factoryCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 200_000, nil))
+	})
+
+	deployed := func(res *Result) gethcommon.Address {
+		for _, slot := range res.TouchedState.Storage {
+			if slot.Address == testContractAddr && slot.Key == (gethcommon.Hash{}) {
+				return gethcommon.BytesToAddress(slot.Value.Bytes())
+			}
+		}
+		return gethcommon.Address{}
+	}
+	initCodeHash := crypto.Keccak256(nil)
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.CreateAddress2(testContractAddr, gethcommon.Hash{}, initCodeHash), deployed(res))
+
+	salt := gethcommon.HexToHash("0x2a")
+	var deployments []evm.Deployment
+	res, err = NewExecutor(WithDeploymentOverride(func(d *evm.Deployment) {
+		deployments = append(deployments, *d)
+		d.Salt = &salt
+	})).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, res.Validated)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, vm.CREATE2, deployments[0].Op)
+	assert.Equal(t, testContractAddr, deployments[0].Caller)
+	assert.Equal(t, gethcommon.Hash{}, *deployments[0].Salt)
+	assert.Equal(t, crypto.CreateAddress2(testContractAddr, salt, initCodeHash), deployed(res))
+}
+
+func TestExecutorBeaconRoot(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{params.BeaconRootsAddress: {Nonce: 1, Code: params.BeaconRootsCode}}
+	beaconRoot := gethcommon.HexToHash("0xbeac")
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.SetParentBeaconRoot(beaconRoot)
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	header := proverInput.Blocks[0].Header
+	require.Equal(t, beaconRoot, *header.ParentBeaconRoot)
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Contains(t, res.ValidationChecks, "beaconRoot")
+
+	// Cancun blocks must carry a parent beacon root
+	missing := gethtypes.CopyHeader(header)
+	missing.ParentBeaconRoot = nil
+	_, err = NewExecutor().Execute(context.Background(), withHeader(proverInput, missing))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid block fields: missing parent beacon root")
+
+	// The beacon roots contract storage must reflect the parent beacon root
+	statedb, err := gethstate.New(gethtypes.EmptyRootHash, gethstate.NewDatabaseForTesting())
+	require.NoError(t, err)
+	statedb.SetState(params.BeaconRootsAddress, gethcommon.BigToHash(new(big.Int).SetUint64(header.Time%beaconRootsHistoryLength)), gethcommon.BigToHash(new(big.Int).SetUint64(header.Time)))
+	err = validateBeaconRoot(statedb, header)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid beacon root: beacon roots contract root")
+}
+
+func TestExecutorWithStepCapture(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode, Storage: map[gethcommon.Hash]gethcommon.Hash{{}: gethcommon.BigToHash(big.NewInt(41))}}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testAddr, big.NewInt(1), params.TxGas, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	// Step 5 of the counter is SSTORE, with the incremented counter and slot 0 on the stack
+	res, err := NewExecutor(WithStepCapture(1, 5)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	capture := res.StepCapture
+	require.NotNil(t, capture)
+	assert.Equal(t, 1, capture.TxIndex)
+	assert.Equal(t, "SSTORE", capture.Op)
+	assert.Equal(t, uint64(8), capture.PC)
+	assert.Equal(t, 1, capture.Depth)
+	assert.Equal(t, testContractAddr, capture.Address)
+	assert.Equal(t, []uint256.Int{*uint256.NewInt(42), *uint256.NewInt(0)}, capture.Stack)
+	assert.Empty(t, capture.Memory)
+	// Slot 0 has been read by SLOAD but not written yet
+	assert.Equal(t, map[gethcommon.Hash]gethcommon.Hash{{}: gethcommon.BigToHash(big.NewInt(41))}, capture.Storage)
+
+	// The counter executes 7 steps
+	_, err = NewExecutor(WithStepCapture(1, 7)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step capture failed: step 7 beyond the execution of transaction 1 (7 step(s) executed)")
+
+	_, err = NewExecutor(WithStepCapture(2, 0)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "step capture failed: transaction 2 not executed (2 transaction(s) executed)")
+}
+
+func TestExecutorWithValidationChecks(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	proverInput.Blocks[0].Header.ReceiptHash = gethcommon.Hash{0x01}
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid receipt root hash")
+
+	// The receipts root corruption is not flagged when only validating the state root
+	res, err := NewExecutor(WithValidationChecks(evm.CheckStateRoot)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+	assert.Equal(t, []string{"stateRoot"}, res.ValidationChecks)
+
+	checks, err := evm.ParseValidationChecks("gasUsed, stateRoot")
+	require.NoError(t, err)
+	res, err = NewExecutor(WithValidationChecks(checks)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gasUsed", "stateRoot"}, res.ValidationChecks)
+
+	_, err = evm.ParseValidationChecks("stateRoot,logs")
+	require.Error(t, err)
+	assert.Equal(t, "unknown validation check \"logs\"", err.Error())
+}
+
+func TestExecutorWithExtraEIPs(t *testing.T) {
+	// London chain, before PUSH0 (EIP-3855) is activated by Shanghai
+	cfg := *testChainConfig
+	cfg.ShanghaiTime = nil
+	cfg.CancunTime = nil
+
+	// PUSH1 0x01 PUSH0 SSTORE STOP stores 1 in slot 0
+	code := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH0), byte(vm.SSTORE), byte(vm.STOP)}
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: code}}
+	proverInput := generateTestProverInput(t, &cfg, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, &cfg, &testContractAddr, big.NewInt(0), 100_000, nil))
+	})
+	slot := func(res *Result) gethcommon.Hash {
+		for _, s := range res.TouchedState.Storage {
+			if s.Address == testContractAddr && s.Key == (gethcommon.Hash{}) {
+				return s.Value
+			}
+		}
+		return gethcommon.Hash{}
+	}
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.InvalidOpcode)
+	assert.Equal(t, gethcommon.Hash{}, slot(res))
+
+	res, err = NewExecutor(WithExtraEIPs(3855)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.False(t, res.Validated)
+	assert.False(t, res.InvalidOpcode)
+	assert.Equal(t, gethcommon.BigToHash(big.NewInt(1)), slot(res))
+
+	// EOF is not executable by the EVM interpreter
+	_, err = NewExecutor(WithExtraEIPs(3540)).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported EIP 3540")
+}
+
+func TestExecutorWithExpectedWitnessHash(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	hash, err := input.WitnessHash(proverInput.Witness)
+	require.NoError(t, err)
+
+	res, err := NewExecutor(WithExpectedWitnessHash(hash)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+
+	// Tamper with the witness by appending a code
+	tampered := *proverInput
+	witness := *proverInput.Witness
+	witness.Codes = append(append([]hexutil.Bytes(nil), witness.Codes...), []byte{byte(vm.STOP)})
+	tampered.Witness = &witness
+
+	_, err = NewExecutor(WithExpectedWitnessHash(hash)).Execute(context.Background(), &tampered)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "witness hash mismatch")
+}
+
+func TestExecutorWithHashedAddresses(t *testing.T) {
+	recipient := gethcommon.HexToAddress("0x1234")
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &recipient, big.NewInt(1), params.TxGas, nil))
+	})
+
+	res, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Nil(t, res.HashedAddresses)
+
+	res, err = NewExecutor(WithHashedAddresses()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	for _, addr := range []gethcommon.Address{testAddr, recipient} {
+		require.Contains(t, res.HashedAddresses, addr)
+		assert.Equal(t, crypto.Keccak256Hash(addr.Bytes()), res.HashedAddresses[addr])
+	}
+}
+
+func TestExecutorWithUnreachableNodesCheck(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	res, err := NewExecutor(WithUnreachableNodesCheck()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+
+	// A leaf node of an unrelated account is not reachable from the pre-state root
+	leaf, err := rlp.EncodeToBytes([]any{[]byte{0x20, 0x01}, bytes.Repeat([]byte{0x01}, 40)})
+	require.NoError(t, err)
+	padded := *proverInput
+	witness := *proverInput.Witness
+	witness.State = append(append([]hexutil.Bytes(nil), witness.State...), leaf)
+	padded.Witness = &witness
+
+	_, err = NewExecutor().Execute(context.Background(), &padded)
+	require.NoError(t, err)
+
+	_, err = NewExecutor(WithUnreachableNodesCheck()).Execute(context.Background(), &padded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "witness has 1 node(s) unreachable from the pre-state root")
+	assert.Contains(t, err.Error(), crypto.Keccak256Hash(leaf).Hex())
+}
+
+func TestExecutorWithGasPoolTracing(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 100_000, nil))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	res, err := NewExecutor(WithGasPoolTracing()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.NotNil(t, res.GasPool)
+
+	gasLimit := proverInput.Blocks[0].Header.GasLimit
+	assert.Equal(t, gasLimit, res.GasPool.GasLimit)
+	assert.Equal(t, gasLimit-res.ProcessResult.GasUsed, res.GasPool.GasPool)
+	assert.Empty(t, res.GasPool.Inconsistencies)
+	require.Len(t, res.GasPool.Txs, 2)
+	assert.Equal(t, gasLimit-res.GasPool.Txs[0].GasUsed, res.GasPool.Txs[0].GasPool)
+	assert.Equal(t, res.GasPool.GasPool, res.GasPool.Txs[1].GasPool)
+}
+
+func TestExecutorWithStateRootOracle(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	header := proverInput.Blocks[0].Header
+
+	oracle := func(root gethcommon.Hash) StateRootOracle {
+		return func(_ context.Context, blockNumber uint64) (gethcommon.Hash, error) {
+			assert.Equal(t, header.Number.Uint64(), blockNumber)
+			return root, nil
+		}
+	}
+
+	_, err := NewExecutor(WithStateRootOracle(oracle(header.Root))).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	wrong := gethcommon.HexToHash("0x01")
+	_, err = NewExecutor(WithStateRootOracle(oracle(wrong))).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("expected %v, computed %v", wrong.Hex(), header.Root.Hex()))
+}
+
+func TestExecutorWithStorageOrderTracing(t *testing.T) {
+	// Accesses slots 5 (SLOAD), 2 (SSTORE), 9 (SLOAD), then 2 again (SLOAD)
+	code := []byte{
+		byte(vm.PUSH1), 0x05, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x09, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.PUSH1), 0x02, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.STOP),
+	}
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: code}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 100_000, nil))
+	})
+
+	res, err := NewExecutor(WithStorageOrderTracing()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+
+	var slots []gethcommon.Hash
+	for _, account := range res.StorageAccessOrder {
+		if account.Address == testContractAddr {
+			slots = account.Slots
+		}
+	}
+	assert.Equal(t, []gethcommon.Hash{gethcommon.BigToHash(big.NewInt(5)), gethcommon.BigToHash(big.NewInt(2)), gethcommon.BigToHash(big.NewInt(9))}, slots)
+}
+
+func TestExecutorTooManyOmmers(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(_ *core.BlockGen) {})
+
+	// Include three ommers, consistently with the block uncle hash
+	var ommers []*gethtypes.Header
+	for i := int64(0); i < 3; i++ {
+		ommers = append(ommers, &gethtypes.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), Extra: []byte{byte(i)}})
+	}
+	proverInput.Blocks[0].Header.UncleHash = gethtypes.CalcUncleHash(ommers)
+	proverInput.Blocks[0].Uncles = ommers
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many ommers: block includes 3 ommers, at most 2 allowed")
+}
+
+func TestValidateOmmers(t *testing.T) {
+	parent := &gethtypes.Header{Number: big.NewInt(9), Difficulty: big.NewInt(1)}
+	block := func(ommers ...*gethtypes.Header) *input.Block {
+		return &input.Block{Header: &gethtypes.Header{Number: big.NewInt(10), ParentHash: parent.Hash()}, Uncles: ommers}
+	}
+	ommer := func(number int64) *gethtypes.Header {
+		return &gethtypes.Header{Number: big.NewInt(number), Difficulty: big.NewInt(2)}
+	}
+
+	assert.NoError(t, validateOmmers(block(), []*gethtypes.Header{parent}))
+	assert.NoError(t, validateOmmers(block(ommer(9), ommer(3)), []*gethtypes.Header{parent}))
+
+	tests := []struct {
+		desc   string
+		ommers []*gethtypes.Header
+		err    string
+	}{
+		{"too deep", []*gethtypes.Header{ommer(2)}, "inclusion depth 8 exceeds 7"},
+		{"not below", []*gethtypes.Header{ommer(10)}, "height 10 is not below block height 10"},
+		{"duplicate", []*gethtypes.Header{ommer(8), ommer(8)}, "invalid ommer 1 (" + ommer(8).Hash().Hex() + "): duplicate ommer"},
+		{"ancestor", []*gethtypes.Header{parent}, "ommer is an ancestor of the block"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			err := validateOmmers(block(test.ommers...), []*gethtypes.Header{parent})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestExecutorGasLimitAdjustment(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(_ *core.BlockGen) {})
+
+	// The parent gas limit is 30M, so the gas limit can change by at most 30M/1024-1 = 29295
+	proverInput.Blocks[0].Header.GasLimit = 30_000_000 + 29_296
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid gas limit adjustment: gas limit 30029296 changes by 29296 from parent gas limit 30000000, at most ±29295 allowed")
+
+	// Validation is disabled when overriding the gas limit
+	_, err = NewExecutor(WithGasLimitOverride(30_000_000)).Execute(context.Background(), proverInput)
+	assert.NotContains(t, fmt.Sprint(err), "invalid gas limit adjustment")
+}
+
+func TestExecutorWithCodeValidation(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	newInput := func(cfg *params.ChainConfig, extraCode []byte) *input.ProverInput {
+		proverInput := generateTestProverInput(t, cfg, alloc, func(b *core.BlockGen) {
+			b.AddTx(signTestTx(t, b, cfg, &testContractAddr, big.NewInt(1), 100_000, nil))
+		})
+		proverInput.Witness.Codes = append(proverInput.Witness.Codes, extraCode)
+		return proverInput
+	}
+
+	// EOF container whose code section header declares a zero size
+	malformedEOF := hexutil.MustDecode("0xef00010100040200010000040000000080000000")
+
+	// EOF is not enforced without EOF validation
+	_, err := NewExecutor(WithCodeValidation()).Execute(context.Background(), newInput(testChainConfig, malformedEOF))
+	require.NoError(t, err)
+
+	// Prague does not activate EOF, 0xEF00-prefixed codes are legacy codes
+	pragueTime := uint64(0)
+	pragueCfg := *testChainConfig
+	pragueCfg.PragueTime = &pragueTime
+	_, err = NewExecutor(WithCodeValidation()).Execute(context.Background(), newInput(&pragueCfg, malformedEOF))
+	require.NoError(t, err)
+
+	_, err = NewExecutor(WithEOFValidation()).Execute(context.Background(), newInput(testChainConfig, malformedEOF))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("invalid code %v: malformed EOF container", crypto.Keccak256Hash(malformedEOF).Hex()))
+
+	_, err = NewExecutor(WithCodeValidation()).Execute(context.Background(), newInput(testChainConfig, []byte{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "empty code")
+}
+
+func TestValidateCode(t *testing.T) {
+	// Minimal valid EOF container with a single STOP code section
+	validEOF := hexutil.MustDecode("0xef000101000402000100010400000000800000" + "00")
+	assert.NoError(t, validateCode(validEOF, true))
+	assert.NoError(t, validateCode(testCounterCode, true))
+
+	// EIP-7702 delegation designators are not EOF-tagged
+	assert.NoError(t, validateCode(append([]byte{0xef, 0x01, 0x00}, testAddr.Bytes()...), true))
+
+	// Code section not terminating (single PUSH1 missing its immediate), only rejected with EOF validation
+	invalidEOF := hexutil.MustDecode("0xef000101000402000100010400000000800001" + "60")
+	assert.Error(t, validateCode(invalidEOF, true))
+	assert.NoError(t, validateCode(invalidEOF, false))
+
+	assert.Error(t, validateCode(nil, false))
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+type fingerprintLog struct {
+	Address gethcommon.Address
+	Topics  []gethcommon.Hash
+	Data    []byte
+}
+
+type fingerprint struct {
+	PostStateRoot gethcommon.Hash
+	ReceiptsRoot  gethcommon.Hash
+	GasUsed       uint64
+	Logs          []fingerprintLog
+	TxStatuses    []uint64
+}
+
+// Fingerprint returns a deterministic hash of the observable output of the execution, meant for golden tests detecting behavioral drift
+// It covers the post-state root, the receipts root, the gas used, the logs sorted by transaction and log index, and the status of every transaction.
+func (res *Result) Fingerprint() gethcommon.Hash {
+	logs := append([]*gethtypes.Log(nil), res.ProcessResult.Logs...)
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].TxIndex != logs[j].TxIndex {
+			return logs[i].TxIndex < logs[j].TxIndex
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	fp := fingerprint{
+		PostStateRoot: res.attestation.PostStateRoot,
+		ReceiptsRoot:  res.attestation.ReceiptsRoot,
+		GasUsed:       res.ProcessResult.GasUsed,
+		Logs:          make([]fingerprintLog, 0, len(logs)),
+		TxStatuses:    make([]uint64, 0, len(res.ProcessResult.Receipts)),
+	}
+	for _, log := range logs {
+		fp.Logs = append(fp.Logs, fingerprintLog{Address: log.Address, Topics: log.Topics, Data: log.Data})
+	}
+	for _, receipt := range res.ProcessResult.Receipts {
+		fp.TxStatuses = append(fp.TxStatuses, receipt.Status)
+	}
+
+	// Encoding only fails on unsupported types, which the fingerprint does not hold
+	b, _ := rlp.EncodeToBytes(&fp)
+	return crypto.Keccak256Hash(b)
+}
@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultFingerprint(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	a, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	b, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// Stable across runs
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+	assert.Equal(t, a.Fingerprint(), a.Fingerprint())
+
+	b.ProcessResult.GasUsed++
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+}
@@ -0,0 +1,165 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// BlockchainFixture is a blockchain test of the execution-spec-tests fixture format
+// Only the fields needed to convert the test into a prover input are decoded.
+type BlockchainFixture struct {
+	Network       string                 `json:"network"`
+	GenesisRLP    hexutil.Bytes          `json:"genesisRLP"`
+	Pre           gethtypes.GenesisAlloc `json:"pre"`
+	Blocks        []*FixtureBlock        `json:"blocks"`
+	LastBlockHash gethcommon.Hash        `json:"lastblockhash"`
+}
+
+// FixtureBlock is a block of a blockchain fixture
+type FixtureBlock struct {
+	RLP             hexutil.Bytes `json:"rlp"`
+	ExpectException string        `json:"expectException,omitempty"`
+}
+
+// LoadBlockchainFixtures decodes a fixture file, mapping test names to blockchain fixtures
+func LoadBlockchainFixtures(r io.Reader) (map[string]*BlockchainFixture, error) {
+	fixtures := make(map[string]*BlockchainFixture)
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("failed to decode blockchain fixtures: %v", err)
+	}
+	return fixtures, nil
+}
+
+// ConvertBlockchainFixture converts a single block blockchain fixture into a prover input
+// The witness consists of the full pre-allocation state trie and codes, with the genesis block as only ancestor.
+func ConvertBlockchainFixture(fixture *BlockchainFixture) (*input.ProverInput, error) {
+	chainCfg, eips, err := tests.GetChainConfig(fixture.Network)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported fixture network: %v", err)
+	}
+	if len(eips) > 0 {
+		return nil, fmt.Errorf("unsupported fixture network %v: additional EIPs are not supported", fixture.Network)
+	}
+	if len(fixture.Blocks) != 1 {
+		return nil, fmt.Errorf("unsupported fixture: expected a single block, got %d", len(fixture.Blocks))
+	}
+	if fixture.Blocks[0].ExpectException != "" {
+		return nil, fmt.Errorf("unsupported fixture: invalid block (expected exception %v)", fixture.Blocks[0].ExpectException)
+	}
+
+	var genesis gethtypes.Block
+	if err := rlp.DecodeBytes(fixture.GenesisRLP, &genesis); err != nil {
+		return nil, fmt.Errorf("failed to decode genesis block: %v", err)
+	}
+	var block gethtypes.Block
+	if err := rlp.DecodeBytes(fixture.Blocks[0].RLP, &block); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %v", err)
+	}
+
+	nodes, err := allocStateNodes(fixture.Pre, genesis.Root())
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]hexutil.Bytes, 0)
+	for _, account := range fixture.Pre {
+		if len(account.Code) > 0 {
+			codes = append(codes, account.Code)
+		}
+	}
+
+	return &input.ProverInput{
+		Blocks: []*input.Block{
+			{
+				Header:       block.Header(),
+				Transactions: block.Transactions(),
+				Uncles:       block.Uncles(),
+				Withdrawals:  block.Withdrawals(),
+			},
+		},
+		Witness: &input.Witness{
+			State:     nodes,
+			Ancestors: []*gethtypes.Header{genesis.Header()},
+			Codes:     codes,
+		},
+		ChainConfig: chainCfg,
+	}, nil
+}
+
+// allocStateNodes returns every node of the state trie of the allocation, which must match the given state root
+func allocStateNodes(alloc gethtypes.GenesisAlloc, root gethcommon.Hash) ([]hexutil.Bytes, error) {
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, triedb.HashDefaults)
+	state, err := gethstate.New(gethtypes.EmptyRootHash, gethstate.NewDatabase(trieDB, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-state: %v", err)
+	}
+	for addr, account := range alloc {
+		if account.Balance != nil {
+			state.SetBalance(addr, uint256.MustFromBig(account.Balance), tracing.BalanceIncreaseGenesisBalance)
+		}
+		state.SetNonce(addr, account.Nonce)
+		state.SetCode(addr, account.Code)
+		for key, value := range account.Storage {
+			state.SetState(addr, key, value)
+		}
+	}
+	computed, err := state.Commit(0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit pre-state: %v", err)
+	}
+	if computed != root {
+		return nil, fmt.Errorf("pre-state root mismatch (genesis %v, computed %v)", root.Hex(), computed.Hex())
+	}
+	if err := trieDB.Commit(computed, false); err != nil {
+		return nil, fmt.Errorf("failed to commit pre-state trie: %v", err)
+	}
+
+	// Trie nodes are stored by hash in hash based databases
+	nodes := make([]hexutil.Bytes, 0)
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == gethcommon.HashLength && bytes.Equal(crypto.Keccak256(it.Value()), it.Key()) {
+			nodes = append(nodes, bytes.Clone(it.Value()))
+		}
+	}
+	return nodes, it.Error()
+}
+
+// RunBlockchainFixture converts the blockchain fixture into a prover input, executes it and asserts the expected post-state root and block hash are reached
+func RunBlockchainFixture(ctx context.Context, executor Executor, fixture *BlockchainFixture) (*Result, error) {
+	pi, err := ConvertBlockchainFixture(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert fixture: %v", err)
+	}
+
+	res, err := executor.Execute(ctx, pi)
+	if err != nil {
+		return res, err
+	}
+
+	header := pi.Blocks[0].Header
+	if root := res.attestation.PostStateRoot; root != header.Root {
+		return res, fmt.Errorf("post-state root mismatch (expected %v, got %v)", header.Root.Hex(), root.Hex())
+	}
+	if fixture.LastBlockHash != (gethcommon.Hash{}) && header.Hash() != fixture.LastBlockHash {
+		return res, fmt.Errorf("block hash mismatch (expected %v, got %v)", fixture.LastBlockHash.Hex(), header.Hash().Hex())
+	}
+	return res, nil
+}
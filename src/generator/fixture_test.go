@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBlockchainFixture(t *testing.T) {
+	f, err := os.Open("testdata/fixtures/blockchain_sstore.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	fixtures, err := LoadBlockchainFixtures(f)
+	require.NoError(t, err)
+	require.Contains(t, fixtures, "sstore_blockchain_test")
+	fixture := fixtures["sstore_blockchain_test"]
+
+	pi, err := ConvertBlockchainFixture(fixture)
+	require.NoError(t, err)
+	assert.Equal(t, fixture.LastBlockHash, pi.Blocks[0].Header.Hash())
+	assert.Len(t, pi.Witness.Codes, 1)
+	assert.NotEmpty(t, pi.Witness.State)
+	require.Len(t, pi.Witness.Ancestors, 1)
+
+	res, err := RunBlockchainFixture(context.Background(), NewExecutor(), fixture)
+	require.NoError(t, err)
+	assert.Equal(t, pi.Blocks[0].Header.Root, res.attestation.PostStateRoot)
+
+	// Fixtures expecting another block hash fail
+	fixture.LastBlockHash[0] ^= 0xff
+	_, err = RunBlockchainFixture(context.Background(), NewExecutor(), fixture)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "block hash mismatch")
+}
@@ -0,0 +1,24 @@
+package generator
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// VerifyHeaderDB verifies the header hash matches the canonical hash recorded for its number in a header database
+// The database holds known-good headers indexed by number (see rawdb.WriteCanonicalHash), e.g. maintained from a trusted node.
+func VerifyHeaderDB(db ethdb.Reader, header *gethtypes.Header) error {
+	number := header.Number.Uint64()
+	known := rawdb.ReadCanonicalHash(db, number)
+	if known == (gethcommon.Hash{}) {
+		return fmt.Errorf("block %d not found in header database", number)
+	}
+	if hash := header.Hash(); hash != known {
+		return fmt.Errorf("block %d hash %v does not match header database hash %v", number, hash.Hex(), known.Hex())
+	}
+	return nil
+}
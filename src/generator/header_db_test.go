@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithHeaderDB(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	header := proverInput.Blocks[0].Header
+
+	t.Run("matching", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+
+		res, err := NewExecutor(WithHeaderDB(db)).Execute(context.Background(), proverInput)
+		require.NoError(t, err)
+		assert.True(t, res.Validated)
+	})
+
+	t.Run("mismatching", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		rawdb.WriteCanonicalHash(db, gethcommon.HexToHash("0x01"), header.Number.Uint64())
+
+		_, err := NewExecutor(WithHeaderDB(db)).Execute(context.Background(), proverInput)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match header database hash")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := NewExecutor(WithHeaderDB(rawdb.NewMemoryDatabase())).Execute(context.Background(), proverInput)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found in header database")
+	})
+}
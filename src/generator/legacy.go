@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kkrt-labs/go-utils/log"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// compareLegacyPath re-executes the block on a complete in-memory state built from the witness with the classic (non-stateless) validation path
+// and compares its result against the result of the stateless self-validation path
+func (e *executor) compareLegacyPath(ctx context.Context, inputs *input.ProverInput, res *Result) error {
+	log.LoggerFromContext(ctx).Info("Compare with legacy validation path...")
+
+	legacy := *e
+	legacy.legacyPath = true
+	legacy.compareLegacy = false
+	legacy.cache = nil // The legacy state is built from scratch
+	legacy.events = nil
+	legacy.readSink = nil
+	legacy.zkTraces = nil
+	legacy.slowThreshold = 0
+
+	legacyRes, err := legacy.execute(ctx, inputs)
+	if err != nil {
+		return fmt.Errorf("legacy validation path failed: %v", err)
+	}
+
+	if err := compareResults(res, legacyRes); err != nil {
+		return fmt.Errorf("stateless and legacy validation paths disagree: %v", err)
+	}
+	return nil
+}
+
+// compareResults returns an error combining every difference between the stateless and legacy results
+func compareResults(stateless, legacy *Result) error {
+	var errs []error
+	if s, l := stateless.attestation.PostStateRoot, legacy.attestation.PostStateRoot; s != l {
+		errs = append(errs, fmt.Errorf("post-state root %v (legacy %v)", s.Hex(), l.Hex()))
+	}
+	if s, l := stateless.attestation.ReceiptsRoot, legacy.attestation.ReceiptsRoot; s != l {
+		errs = append(errs, fmt.Errorf("receipts root %v (legacy %v)", s.Hex(), l.Hex()))
+	}
+	if s, l := stateless.ProcessResult.GasUsed, legacy.ProcessResult.GasUsed; s != l {
+		errs = append(errs, fmt.Errorf("gas used %d (legacy %d)", s, l))
+	}
+	if s, l := stateless.GasRefunded, legacy.GasRefunded; s != l {
+		errs = append(errs, fmt.Errorf("gas refunded %d (legacy %d)", s, l))
+	}
+	switch s, l := stateless.RequestsHash, legacy.RequestsHash; {
+	case (s == nil) != (l == nil):
+		errs = append(errs, fmt.Errorf("requests hash %v (legacy %v)", s, l))
+	case s != nil && *s != *l:
+		errs = append(errs, fmt.Errorf("requests hash %v (legacy %v)", s.Hex(), l.Hex()))
+	}
+	return errors.Join(errs...)
+}
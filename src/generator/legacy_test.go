@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithLegacyComparison(t *testing.T) {
+	proverInput := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	res, err := NewExecutor(WithLegacyComparison()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, proverInput.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+}
+
+func TestCompareResults(t *testing.T) {
+	stateless := &Result{ProcessResult: &core.ProcessResult{GasUsed: 21000}, attestation: Attestation{PostStateRoot: gethcommon.Hash{0x01}}}
+	legacy := &Result{ProcessResult: &core.ProcessResult{GasUsed: 21000}, attestation: Attestation{PostStateRoot: gethcommon.Hash{0x01}}}
+	require.NoError(t, compareResults(stateless, legacy))
+
+	legacy.attestation.PostStateRoot = gethcommon.Hash{0x02}
+	legacy.ProcessResult.GasUsed = 42000
+	err := compareResults(stateless, legacy)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post-state root")
+	assert.Contains(t, err.Error(), "gas used 21000 (legacy 42000)")
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/kkrt-labs/go-utils/log"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// ManifestEntry is a line of an NDJSON manifest, referencing a JSON prover input and its expected metadata
+// Omitted metadata fields are not verified.
+type ManifestEntry struct {
+	Path        string           `json:"path"`                  // Path of the JSON prover input
+	ChainID     *uint64          `json:"chainId,omitempty"`     // Expected chain ID
+	BlockNumber *uint64          `json:"blockNumber,omitempty"` // Expected block number
+	BlockHash   *gethcommon.Hash `json:"blockHash,omitempty"`   // Expected block hash
+}
+
+// ManifestResult is the outcome of a manifest entry
+type ManifestResult struct {
+	Line   int            // Line of the entry in the manifest (1-based)
+	Entry  *ManifestEntry // Entry (nil if the line could not be decoded)
+	Result *Result        // Execution result (nil if the input could not be loaded or executed)
+	Err    error          // Error decoding the line, loading the input, verifying its metadata or executing it
+
+	BlockNumber  uint64        // Number of the input block (zero if the input could not be loaded)
+	TxCount      int           // Number of transactions of the input block
+	LoadDuration time.Duration // Duration of loading and verifying the input
+	ExecDuration time.Duration // Duration of the execution
+}
+
+// maxManifestLineSize is the maximum size of a manifest line
+const maxManifestLineSize = 1 << 20
+
+// RunManifest consumes an NDJSON manifest line by line, loading, verifying and executing the prover input referenced by each entry
+// Entries are processed as they are read and failing entries do not stop the run, their error is reported in their result.
+// Empty lines are skipped. An error is only returned if the manifest can not be read.
+func RunManifest(ctx context.Context, executor Executor, manifest io.Reader) ([]*ManifestResult, error) {
+	results := make([]*ManifestResult, 0)
+
+	scanner := bufio.NewScanner(manifest)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxManifestLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		res := &ManifestResult{Line: line}
+		var entry ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			res.Err = fmt.Errorf("invalid manifest entry: %v", err)
+		} else {
+			res.Entry = &entry
+			res.Err = runManifestEntry(ctx, executor, res)
+		}
+
+		if res.Err != nil {
+			log.LoggerFromContext(ctx).Error("Manifest entry failed", zap.Int("line", line), zap.Error(res.Err))
+		}
+		results = append(results, res)
+	}
+	if err := scanner.Err(); err != nil {
+		return results, fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	return results, nil
+}
+
+func runManifestEntry(ctx context.Context, executor Executor, res *ManifestResult) error {
+	start := time.Now()
+	pi, err := loadManifestInput(res.Entry.Path)
+	if err != nil {
+		return err
+	}
+	err = res.Entry.verify(pi)
+	res.LoadDuration = time.Since(start)
+	if err != nil {
+		return err
+	}
+	res.BlockNumber = pi.Blocks[0].Header.Number.Uint64()
+	res.TxCount = len(pi.Blocks[0].Transactions)
+
+	start = time.Now()
+	res.Result, err = executor.Execute(ctx, pi)
+	res.ExecDuration = time.Since(start)
+	return err
+}
+
+func loadManifestInput(path string) (*input.ProverInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prover input: %v", err)
+	}
+	defer f.Close()
+
+	var pi input.ProverInput
+	if err := json.NewDecoder(f).Decode(&pi); err != nil {
+		return nil, fmt.Errorf("failed to decode prover input %v: %v", path, err)
+	}
+	pi.Witness.ResolveAncestorsFile(path)
+	// Inputs without checksum are accepted
+	if pi.Checksum != nil {
+		if err := input.VerifyChecksum(&pi); err != nil {
+			return nil, fmt.Errorf("invalid prover input %v: %v", path, err)
+		}
+	}
+	return &pi, nil
+}
+
+// verify verifies the prover input matches the expected metadata of the entry
+func (entry *ManifestEntry) verify(pi *input.ProverInput) error {
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil || pi.ChainConfig == nil {
+		return fmt.Errorf("invalid prover input %v: missing block or chain config", entry.Path)
+	}
+	header := pi.Blocks[0].Header
+
+	if entry.ChainID != nil && pi.ChainConfig.ChainID.Uint64() != *entry.ChainID {
+		return fmt.Errorf("chain ID mismatch (expected %d, got %v)", *entry.ChainID, pi.ChainConfig.ChainID)
+	}
+	if entry.BlockNumber != nil && header.Number.Uint64() != *entry.BlockNumber {
+		return fmt.Errorf("block number mismatch (expected %d, got %v)", *entry.BlockNumber, header.Number)
+	}
+	if entry.BlockHash != nil && header.Hash() != *entry.BlockHash {
+		return fmt.Errorf("block hash mismatch (expected %v, got %v)", entry.BlockHash.Hex(), header.Hash().Hex())
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunManifest(t *testing.T) {
+	proverInputs := generateTestProverInputs(t, testChainConfig, nil, 2, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	dir := t.TempDir()
+	var manifest strings.Builder
+	for i, pi := range proverInputs {
+		path := filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		b, err := json.Marshal(pi)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b, 0o600))
+
+		blockHash := pi.Blocks[0].Header.Hash()
+		entry, err := json.Marshal(&ManifestEntry{Path: path, BlockHash: &blockHash})
+		require.NoError(t, err)
+		manifest.Write(entry)
+		manifest.WriteString("\n")
+	}
+
+	results, err := RunManifest(context.Background(), NewExecutor(), strings.NewReader(manifest.String()))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for i, res := range results {
+		assert.Equal(t, i+1, res.Line)
+		require.NoError(t, res.Err)
+		assert.Equal(t, proverInputs[i].Blocks[0].Header.Hash(), *res.Entry.BlockHash)
+		assert.Equal(t, proverInputs[i].Blocks[0].Header.Number.Uint64(), res.BlockNumber)
+		assert.Equal(t, 1, res.TxCount)
+		require.NotNil(t, res.Result)
+		assert.Equal(t, proverInputs[i].Blocks[0].Header.GasUsed, res.Result.ProcessResult.GasUsed)
+	}
+
+	// An entry whose metadata does not match its input is reported without stopping the run
+	mismatch := fmt.Sprintf(`{"path":%q,"blockHash":%q}`+"\n"+`{"path":%q}`, filepath.Join(dir, "0.json"), gethcommon.Hash{0x01}.Hex(), filepath.Join(dir, "1.json"))
+	results, err = RunManifest(context.Background(), NewExecutor(), strings.NewReader(mismatch))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Error(t, results[0].Err)
+	assert.Contains(t, results[0].Err.Error(), "block hash mismatch")
+	assert.NoError(t, results[1].Err)
+}
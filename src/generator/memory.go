@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultMemorySamplingInterval is the default interval between heap usage samples
+const DefaultMemorySamplingInterval = 10 * time.Millisecond
+
+// memorySampler periodically samples the heap usage to track its peak
+// As samples are taken at intervals, the reported peak is approximate
+type memorySampler struct {
+	interval time.Duration
+
+	peak uint64
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func newMemorySampler(interval time.Duration) *memorySampler {
+	if interval <= 0 {
+		interval = DefaultMemorySamplingInterval
+	}
+	return &memorySampler{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// start starts sampling the heap usage in the background
+func (s *memorySampler) start() {
+	s.sample()
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.sample()
+			}
+		}
+	}()
+}
+
+// stopAndPeak stops sampling and returns the peak heap usage in bytes
+func (s *memorySampler) stopAndPeak() uint64 {
+	close(s.stop)
+	s.done.Wait()
+	s.sample()
+	return s.peak
+}
+
+func (s *memorySampler) sample() {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > s.peak {
+		s.peak = stats.HeapAlloc
+	}
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+)
+
+// StorageChanges are the storage slots of an account whose value changed during execution
+type StorageChanges struct {
+	Address gethcommon.Address `json:"address"`
+	Slots   []*SlotChange      `json:"slots"` // Sorted by slot key hash
+}
+
+// SlotChange is a storage slot whose value changed during execution
+type SlotChange struct {
+	Key     *gethcommon.Hash `json:"key,omitempty"` // Slot key (nil if the slot was not accessed during execution, e.g. cleared by a self-destruct)
+	KeyHash gethcommon.Hash  `json:"keyHash"`       // Hash of the slot key, as stored in the storage trie
+	Pre     gethcommon.Hash  `json:"pre"`           // Pre-state value (zero if the slot did not exist)
+	Post    gethcommon.Hash  `json:"post"`          // Post-state value (zero if the slot was deleted)
+}
+
+// modifiedNodes returns the trie nodes modified by the execution
+func modifiedNodes(ctx *executorContext, execParams *evm.ExecParams) ([]*trie.ModifiedNode, error) {
+	postRoot, nodes, err := committedNodes(ctx, execParams)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := trie.DiffNodes(ctx.preStateRoot, postRoot, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff pre-state and post-state tries: %v", err)
+	}
+	return diff, nil
+}
+
+// storageChanges returns the storage slots modified by the execution, derived from the pre-state and post-state storage tries
+// Slot keys and account addresses are recovered from the state accessed during execution.
+func storageChanges(ctx *executorContext, execParams *evm.ExecParams) ([]*StorageChanges, error) {
+	postRoot, nodes, err := committedNodes(ctx, execParams)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := trie.DiffStorage(ctx.preStateRoot, postRoot, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff pre-state and post-state storage tries: %v", err)
+	}
+
+	tracker := ctx.trackers.GetAccessTracker(ctx.preStateRoot)
+	addresses := make(map[gethcommon.Hash]gethcommon.Address, len(tracker.Accounts))
+	for addr := range tracker.Accounts {
+		addresses[crypto.Keccak256Hash(addr.Bytes())] = addr
+	}
+
+	byAccount := make(map[gethcommon.Address]*StorageChanges)
+	for _, change := range changes {
+		addr, ok := addresses[change.Owner]
+		if !ok {
+			return nil, fmt.Errorf("storage of account %v modified but account not accessed during execution", change.Owner.Hex())
+		}
+		account, ok := byAccount[addr]
+		if !ok {
+			account = &StorageChanges{Address: addr, Slots: make([]*SlotChange, 0)}
+			byAccount[addr] = account
+		}
+
+		slot := &SlotChange{KeyHash: change.KeyHash, Pre: change.Pre, Post: change.Post}
+		for key := range tracker.Storage[addr] {
+			if crypto.Keccak256Hash(key.Bytes()) == change.KeyHash {
+				key := key
+				slot.Key = &key
+				break
+			}
+		}
+		// Changes are sorted by key hash within an account
+		account.Slots = append(account.Slots, slot)
+	}
+
+	result := make([]*StorageChanges, 0, len(byAccount))
+	for _, account := range byAccount {
+		result = append(result, account)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return bytes.Compare(result[i].Address.Bytes(), result[j].Address.Bytes()) < 0
+	})
+	return result, nil
+}
+
+// committedNodes commits the post-state into the execution database and returns the post-state root and the database trie nodes indexed by hash
+// The post-state is committed once, later calls return the nodes of the first commit.
+func committedNodes(ctx *executorContext, execParams *evm.ExecParams) (gethcommon.Hash, map[gethcommon.Hash][]byte, error) {
+	if ctx.committedNodes != nil {
+		return ctx.postStateRoot, ctx.committedNodes, nil
+	}
+
+	number := execParams.Block.Number()
+	postRoot, err := execParams.State.Commit(number.Uint64(), ctx.hc.Config().IsEIP158(number))
+	if err != nil {
+		return gethcommon.Hash{}, nil, fmt.Errorf("failed to commit post-state: %v", err)
+	}
+	if err := ctx.stateDB.TrieDB().Commit(postRoot, false); err != nil {
+		return gethcommon.Hash{}, nil, fmt.Errorf("failed to flush post-state: %v", err)
+	}
+
+	// The database holds the pre-state nodes of the witness and the post-state nodes, both indexed by hash
+	nodes := make(map[gethcommon.Hash][]byte)
+	it := ctx.stateDB.TrieDB().Disk().NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == gethcommon.HashLength && crypto.Keccak256Hash(it.Value()) == gethcommon.BytesToHash(it.Key()) {
+			nodes[gethcommon.BytesToHash(it.Key())] = gethcommon.CopyBytes(it.Value())
+		}
+	}
+	if err := it.Error(); err != nil {
+		return gethcommon.Hash{}, nil, fmt.Errorf("failed to read trie nodes: %v", err)
+	}
+
+	ctx.postStateRoot, ctx.committedNodes = postRoot, nodes
+	return postRoot, nodes, nil
+}
@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nodeKey struct {
+	owner gethcommon.Hash
+	path  string
+}
+
+// pathStore indexes trie nodes by owner and path
+func pathStore(entries []*trie.ModifiedNode) map[nodeKey][]byte {
+	store := make(map[nodeKey][]byte)
+	for _, entry := range entries {
+		store[nodeKey{entry.Owner, string(entry.Path)}] = entry.Blob
+	}
+	return store
+}
+
+func TestExecutorWithModifiedNodes(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+	preRoot := proverInput.Witness.Ancestors[0].Root
+	postRoot := proverInput.Blocks[0].Header.Root
+
+	res, err := NewExecutor(WithModifiedNodes()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.ModifiedNodes)
+
+	// Path based pre-state database, with every node of the witness
+	preEntries, err := trie.DiffNodes(gethcommon.Hash{}, preRoot, trie.NodesByHash(proverInput.Witness.State...))
+	require.NoError(t, err)
+	store := pathStore(preEntries)
+
+	// Apply the modified nodes
+	for _, entry := range res.ModifiedNodes {
+		key := nodeKey{entry.Owner, string(entry.Path)}
+		if len(entry.Blob) == 0 {
+			require.Contains(t, store, key, "deleted node must exist in the pre-state")
+			delete(store, key)
+		} else {
+			store[key] = entry.Blob
+		}
+	}
+
+	// The root node is the one of the post-state
+	assert.Equal(t, postRoot, crypto.Keccak256Hash(store[nodeKey{trie.AccountTrieOwner(), ""}]))
+
+	// Every node of the database belongs to the post-state tries at its path, and reciprocally
+	blobs := make([][]byte, 0, len(store))
+	for _, blob := range store {
+		blobs = append(blobs, blob)
+	}
+	postEntries, err := trie.DiffNodes(gethcommon.Hash{}, postRoot, trie.NodesByHash(blobs...))
+	require.NoError(t, err)
+	assert.Equal(t, store, pathStore(postEntries))
+}
+
+func TestEncodeModifiedNodes(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	var encoded [][]byte
+	for i := 0; i < 2; i++ {
+		res, err := NewExecutor(WithModifiedNodes()).Execute(context.Background(), proverInput)
+		require.NoError(t, err)
+
+		// Serialization does not depend on the order of the nodes
+		nodes := res.ModifiedNodes
+		if i == 1 {
+			rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+		}
+		b, err := trie.EncodeModifiedNodes(nodes)
+		require.NoError(t, err)
+		encoded = append(encoded, b)
+	}
+	assert.Equal(t, encoded[0], encoded[1])
+
+	decoded, err := trie.DecodeModifiedNodes(encoded[0])
+	require.NoError(t, err)
+	b, err := trie.EncodeModifiedNodes(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, encoded[0], b)
+}
+
+func TestExecutorWithStorageChanges(t *testing.T) {
+	// Contract writing a fresh slot 1 and incrementing its existing slot 0 (SSTORE(1, 5) SSTORE(0, ADD(SLOAD(0), 1)))
+	code := []byte{
+		byte(vm.PUSH1), 0x05, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+	}
+	slot0, slot1 := gethcommon.Hash{}, gethcommon.BigToHash(big.NewInt(1))
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: code, Storage: map[gethcommon.Hash]gethcommon.Hash{slot0: gethcommon.BigToHash(big.NewInt(7))}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	res, err := NewExecutor(WithStorageChanges(), WithModifiedNodes()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	require.NotEmpty(t, res.ModifiedNodes)
+	require.Len(t, res.StorageChanges, 1)
+	assert.Equal(t, testContractAddr, res.StorageChanges[0].Address)
+
+	changes := make(map[gethcommon.Hash]*SlotChange)
+	for _, slot := range res.StorageChanges[0].Slots {
+		require.NotNil(t, slot.Key)
+		assert.Equal(t, crypto.Keccak256Hash(slot.Key.Bytes()), slot.KeyHash)
+		changes[*slot.Key] = slot
+	}
+	require.Len(t, changes, 2)
+	assert.Equal(t, gethcommon.BigToHash(big.NewInt(7)), changes[slot0].Pre)
+	assert.Equal(t, gethcommon.BigToHash(big.NewInt(8)), changes[slot0].Post)
+	assert.Equal(t, gethcommon.Hash{}, changes[slot1].Pre)
+	assert.Equal(t, gethcommon.BigToHash(big.NewInt(5)), changes[slot1].Post)
+}
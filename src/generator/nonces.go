@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ValidateNonces validates the nonces of the block transactions are sequential per sender
+// i.e. the first transaction of each sender has the sender pre-state nonce and each following one the next nonce.
+// It returns an error reporting the first violation, in block order.
+func ValidateNonces(cfg *params.ChainConfig, block *gethtypes.Block, preState vm.StateDB) error {
+	signer := gethtypes.MakeSigner(cfg, block.Number(), block.Time())
+	next := make(map[gethcommon.Address]uint64)
+	for i, tx := range block.Transactions() {
+		sender, err := gethtypes.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("invalid transaction %d: failed to recover sender: %v", i, err)
+		}
+
+		expected, ok := next[sender]
+		if !ok {
+			expected = preState.GetNonce(sender)
+		}
+		if tx.Nonce() != expected {
+			return fmt.Errorf("invalid nonce: transaction %d (%v) of sender %v has nonce %d, expected %d", i, tx.Hash().Hex(), sender.Hex(), tx.Nonce(), expected)
+		}
+		next[sender] = expected + 1
+	}
+	return nil
+}
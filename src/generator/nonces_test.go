@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithNonceValidation(t *testing.T) {
+	otherKey, _ := crypto.GenerateKey()
+	otherAddr := crypto.PubkeyToAddress(otherKey.PublicKey)
+	alloc := gethtypes.GenesisAlloc{otherAddr: {Balance: big.NewInt(params.Ether)}}
+
+	signTx := func(b *core.BlockGen, nonce uint64) *gethtypes.Transaction {
+		tx, err := gethtypes.SignNewTx(otherKey, gethtypes.LatestSigner(testChainConfig), &gethtypes.DynamicFeeTx{
+			ChainID:   testChainConfig.ChainID,
+			Nonce:     nonce,
+			To:        &testContractAddr,
+			Gas:       params.TxGas,
+			GasTipCap: big.NewInt(params.GWei),
+			GasFeeCap: new(big.Int).Add(b.BaseFee(), big.NewInt(params.GWei)),
+		})
+		require.NoError(t, err)
+		return tx
+	}
+
+	var gapped *gethtypes.Transaction
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+		b.AddTx(signTx(b, 0))
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+		b.AddTx(signTx(b, 1))
+		gapped = signTx(b, 2)
+	})
+
+	_, err := NewExecutor(WithNonceValidation()).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	// The second transaction of the other sender skips nonce 1
+	proverInput.Blocks[0].Transactions[3] = gapped
+	_, err = NewExecutor(WithNonceValidation()).Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("invalid nonce: transaction 3 (%v) of sender %v has nonce 2, expected 1", gapped.Hash().Hex(), otherAddr.Hex()))
+}
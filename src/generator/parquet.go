@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Status of a block run in exported results
+const (
+	BlockStatusSucceeded = "succeeded"
+	BlockStatusFailed    = "failed"
+)
+
+// BlockResultRow is the flat per-block record of a manifest run, as exported for analytics
+type BlockResultRow struct {
+	Line              int64   `parquet:"line"`
+	BlockNumber       uint64  `parquet:"block_number"`
+	GasUsed           uint64  `parquet:"gas_used"`
+	TxCount           int64   `parquet:"tx_count"`
+	LoadDurationMs    float64 `parquet:"load_duration_ms"`
+	ExecDurationMs    float64 `parquet:"exec_duration_ms"`
+	WitnessSize       uint64  `parquet:"witness_size"`
+	WitnessSizePerGas float64 `parquet:"witness_size_per_gas"`
+	Status            string  `parquet:"status,dict"`
+	Error             string  `parquet:"error,optional"`
+}
+
+// NewBlockResultRow flattens a manifest result into a row
+// Execution metrics are taken from the partial result of failed executions when available.
+func NewBlockResultRow(res *ManifestResult) *BlockResultRow {
+	row := &BlockResultRow{
+		Line:           int64(res.Line),
+		BlockNumber:    res.BlockNumber,
+		TxCount:        int64(res.TxCount),
+		LoadDurationMs: float64(res.LoadDuration.Microseconds()) / 1000,
+		ExecDurationMs: float64(res.ExecDuration.Microseconds()) / 1000,
+		Status:         BlockStatusSucceeded,
+	}
+	if res.Result != nil {
+		if res.Result.ProcessResult != nil {
+			row.GasUsed = res.Result.ProcessResult.GasUsed
+		}
+		row.WitnessSize = res.Result.WitnessSize
+		row.WitnessSizePerGas = res.Result.WitnessSizePerGas
+	}
+	if res.Err != nil {
+		row.Status = BlockStatusFailed
+		row.Error = res.Err.Error()
+	}
+	return row
+}
+
+// WriteParquet writes the manifest results as a Parquet file with one BlockResultRow per result
+func WriteParquet(w io.Writer, results []*ManifestResult) error {
+	rows := make([]BlockResultRow, 0, len(results))
+	for _, res := range results {
+		rows = append(rows, *NewBlockResultRow(res))
+	}
+
+	writer := parquet.NewGenericWriter[BlockResultRow](w)
+	if _, err := writer.Write(rows); err != nil {
+		return fmt.Errorf("failed to write parquet rows: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close parquet writer: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteParquet(t *testing.T) {
+	results := []*ManifestResult{
+		{
+			Line:         1,
+			BlockNumber:  21465322,
+			TxCount:      3,
+			LoadDuration: 1500 * time.Microsecond,
+			ExecDuration: 20 * time.Millisecond,
+			Result: &Result{
+				ProcessResult:     &core.ProcessResult{GasUsed: 63_000},
+				WitnessSize:       12_600,
+				WitnessSizePerGas: 0.2,
+			},
+		},
+		{
+			Line:         2,
+			BlockNumber:  21465323,
+			LoadDuration: time.Millisecond,
+			Err:          fmt.Errorf("block hash mismatch"),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteParquet(&buf, results))
+
+	rows, err := parquet.Read[BlockResultRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, []BlockResultRow{
+		{
+			Line:              1,
+			BlockNumber:       21465322,
+			GasUsed:           63_000,
+			TxCount:           3,
+			LoadDurationMs:    1.5,
+			ExecDurationMs:    20,
+			WitnessSize:       12_600,
+			WitnessSizePerGas: 0.2,
+			Status:            BlockStatusSucceeded,
+		},
+		{
+			Line:           2,
+			BlockNumber:    21465323,
+			LoadDurationMs: 1,
+			Status:         BlockStatusFailed,
+			Error:          "block hash mismatch",
+		},
+	}, rows)
+}
@@ -30,12 +30,13 @@ import (
 // It contains the partial state & chain data necessary for processing the block and validating the final state.
 // The format is convenient but sub-optimal as it contains duplicated data, it is an intermediate object necessary to generate the final ProverInput.
 type PreflightData struct {
-	Block           *ethrpc.Block        `json:"block"`           // Block to execute
-	Ancestors       []*gethtypes.Header  `json:"ancestors"`       // Ancestors of the block that are accessed during the block execution
-	ChainConfig     *params.ChainConfig  `json:"chainConfig"`     // Chain configuration
-	Codes           []hexutil.Bytes      `json:"codes"`           // Contract bytecodes used during the block execution
-	PreStateProofs  []*trie.AccountProof `json:"preStateProofs"`  // Proofs of every accessed account and storage slot accessed during the block processing
-	PostStateProofs []*trie.AccountProof `json:"postStateProofs"` // Proofs of every account and storage slot deleted during the block processing
+	Block           *ethrpc.Block        `json:"block"`                // Block to execute
+	Ancestors       []*gethtypes.Header  `json:"ancestors"`            // Ancestors of the block that are accessed during the block execution
+	ChainConfig     *params.ChainConfig  `json:"chainConfig"`          // Chain configuration
+	Codes           []hexutil.Bytes      `json:"codes"`                // Contract bytecodes used during the block execution
+	PreStateProofs  []*trie.AccountProof `json:"preStateProofs"`       // Proofs of every accessed account and storage slot accessed during the block processing
+	PostStateProofs []*trie.AccountProof `json:"postStateProofs"`      // Proofs of every account and storage slot deleted during the block processing
+	TargetFork      *params.Rules        `json:"targetFork,omitempty"` // Rules of the future fork whose state accesses are also covered by the data (nil if none)
 }
 
 // Preflight is the interface for the preflight block execution which consists of processing an EVM block without final state validation.
@@ -48,14 +49,30 @@ type Preflight interface {
 
 // preflight is the implementation of the Preflight interface using an RPC remote to fetch the state datas.
 type preflight struct {
-	remote ethrpc.Client
+	remote     ethrpc.Client
+	targetFork *params.Rules
+}
+
+// PreflightOption is an option for the preflight
+type PreflightOption func(*preflight)
+
+// WithTargetFork also executes the block under the rules of a future fork, so the collected data covers the state the future fork accesses
+// (e.g. new system contracts storage). It enables to generate prover inputs that can be executed under both the current and the future fork.
+func WithTargetFork(rules *params.Rules) PreflightOption {
+	return func(pf *preflight) {
+		pf.targetFork = rules
+	}
 }
 
 // NewPreflight creates a new RPC Preflight instance using the provided RPC client.
-func NewPreflight(remote ethrpc.Client) Preflight {
-	return &preflight{
+func NewPreflight(remote ethrpc.Client, opts ...PreflightOption) Preflight {
+	pf := &preflight{
 		remote: remote,
 	}
+	for _, opt := range opts {
+		opt(pf)
+	}
+	return pf
 }
 
 // Preflight executes a preflight block execution, that collect and returns the intermediary preflight data input.
@@ -135,6 +152,10 @@ func (pf *preflight) preflight(ctx context.Context, chainCfg *params.ChainConfig
 		return nil, err
 	}
 
+	if err := pf.executeTargetFork(genCtx, execParams); err != nil {
+		return nil, err
+	}
+
 	preStateProofs, deletionsPostStateProofs, err := pf.fetchStateProofs(genCtx, execParams)
 	if err != nil {
 		return nil, err
@@ -145,6 +166,7 @@ func (pf *preflight) preflight(ctx context.Context, chainCfg *params.ChainConfig
 		Block:           new(ethrpc.Block).FromBlock(block, chainCfg),
 		PreStateProofs:  preStateProofs,
 		PostStateProofs: deletionsPostStateProofs,
+		TargetFork:      pf.targetFork,
 	}
 
 	witness := execParams.State.Witness()
@@ -224,6 +246,30 @@ func (pf *preflight) execute(ctx *preflightContext, execParams *evm.ExecParams)
 	return nil
 }
 
+// executeTargetFork executes the block under the target fork (if any), so the state it accesses is tracked and its codes and ancestors are added to the witness
+func (pf *preflight) executeTargetFork(ctx *preflightContext, execParams *evm.ExecParams) error {
+	if pf.targetFork == nil {
+		return nil
+	}
+
+	targetCfg, err := ethereum.ChainConfigWithRules(ctx.hc.Config(), pf.targetFork)
+	if err != nil {
+		return fmt.Errorf("invalid target fork: %v", err)
+	}
+	hc, err := ethereum.NewChain(targetCfg, ctx.stateDB)
+	if err != nil {
+		return fmt.Errorf("failed to create target fork chain: %v", err)
+	}
+
+	witness, err := executeTargetFork(ctx.ctx, hc, ctx.stateDB, execParams.Block, ctx.parentHeader.Root)
+	if err != nil {
+		return err
+	}
+	mergeWitness(execParams.State.Witness(), witness)
+
+	return nil
+}
+
 // fetchStateProofs for all accounts and storage slots that were accessed during the block execution
 // It fetches the state proofs both at the initial state (parent state) and at the final state
 func (pf *preflight) fetchStateProofs(ctx *preflightContext, execParams *evm.ExecParams) (preStateProofs, postStateProofs []*trie.AccountProof, err error) {
@@ -15,7 +15,7 @@ type TestDataInputs struct {
 	ProverInput   input.ProverInput `json:"proverInput"`
 }
 
-func loadTestDataInputs(t *testing.T, path string) *TestDataInputs {
+func loadTestDataInputs(t testing.TB, path string) *TestDataInputs {
 	f, err := os.Open(path)
 	require.NoError(t, err)
 	defer f.Close()
@@ -28,11 +28,28 @@ type Preparer interface {
 	Prepare(ctx context.Context, inputs *PreflightData) (*input.ProverInput, error)
 }
 
-type preparer struct{}
+type preparer struct {
+	storageProofDepth int
+}
+
+// PreparerOption is an option for the preparer
+type PreparerOption func(*preparer)
+
+// WithStorageProofDepth limits storage proofs to their first depth nodes when loading the pre-state (zero means full proofs)
+// Preparation fails if the block accesses storage slots whose proofs are deeper than the limit
+func WithStorageProofDepth(depth int) PreparerOption {
+	return func(p *preparer) {
+		p.storageProofDepth = depth
+	}
+}
 
 // NewPreparer creates a new Preparer.
-func NewPreparer() Preparer {
-	return &preparer{}
+func NewPreparer(opts ...PreparerOption) Preparer {
+	p := &preparer{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // Prepare prepares the ProvableBlockInputs data for the EVM prover engine.
@@ -83,6 +100,10 @@ func (p *preparer) prepare(ctx context.Context, inputs *PreflightData) (*input.P
 		return nil, fmt.Errorf("validation execution failed: %v", err)
 	}
 
+	if err := p.executeTargetFork(valCtx, inputs, execParams); err != nil {
+		return nil, err
+	}
+
 	return p.prepareProverInput(valCtx, execParams), nil
 }
 
@@ -118,7 +139,9 @@ func (p *preparer) preparePreState(ctx *preparerContext, inputs *PreflightData)
 	parentHeader := inputs.Ancestors[0]
 	genesisHeader := ctx.hc.GetHeaderByNumber(0)
 
-	nodeSet, err := trie.NodeSetFromStateTransitionProofs(parentHeader.Root, inputs.Block.Root, inputs.PreStateProofs, inputs.PostStateProofs)
+	preStateProofs := truncateStorageProofs(inputs.PreStateProofs, p.storageProofDepth)
+	postStateProofs := truncateStorageProofs(inputs.PostStateProofs, p.storageProofDepth)
+	nodeSet, err := trie.NodeSetFromStateTransitionProofs(parentHeader.Root, inputs.Block.Root, preStateProofs, postStateProofs)
 	if err != nil {
 		return fmt.Errorf("failed to create state nodes: %v", err)
 	}
@@ -138,6 +161,28 @@ func (p *preparer) preparePreState(ctx *preparerContext, inputs *PreflightData)
 	return nil
 }
 
+// truncateStorageProofs returns a copy of the account proofs with storage proofs limited to their first depth nodes
+func truncateStorageProofs(proofs []*trie.AccountProof, depth int) []*trie.AccountProof {
+	if depth <= 0 {
+		return proofs
+	}
+
+	truncated := make([]*trie.AccountProof, 0, len(proofs))
+	for _, proof := range proofs {
+		accProof := *proof
+		accProof.Storage = make([]*trie.StorageProof, 0, len(proof.Storage))
+		for _, storageProof := range proof.Storage {
+			slotProof := *storageProof
+			if len(slotProof.Proof) > depth {
+				slotProof.Proof = slotProof.Proof[:depth]
+			}
+			accProof.Storage = append(accProof.Storage, &slotProof)
+		}
+		truncated = append(truncated, &accProof)
+	}
+	return truncated
+}
+
 func (p *preparer) prepareExecParams(ctx *preparerContext, inputs *PreflightData) (*evm.ExecParams, error) {
 	log.LoggerFromContext(ctx.ctx).Debug("Prepare execution parameters...")
 
@@ -168,6 +213,30 @@ func (p *preparer) execute(ctx *preparerContext, execParams *evm.ExecParams) err
 	return nil
 }
 
+// executeTargetFork executes the block under the preflight target fork (if any) and adds the state it accesses to the witness
+func (p *preparer) executeTargetFork(ctx *preparerContext, inputs *PreflightData, execParams *evm.ExecParams) error {
+	if inputs.TargetFork == nil {
+		return nil
+	}
+
+	targetCfg, err := ethereum.ChainConfigWithRules(inputs.ChainConfig, inputs.TargetFork)
+	if err != nil {
+		return fmt.Errorf("invalid target fork: %v", err)
+	}
+	hc, err := ethereum.NewChain(targetCfg, ctx.stateDB)
+	if err != nil {
+		return fmt.Errorf("failed to create target fork chain: %v", err)
+	}
+
+	witness, err := executeTargetFork(ctx.ctx, hc, ctx.stateDB, execParams.Block, inputs.Ancestors[0].Root)
+	if err != nil {
+		return err
+	}
+	mergeWitness(execParams.State.Witness(), witness)
+
+	return nil
+}
+
 func (p *preparer) prepareProverInput(_ *preparerContext, execParams *evm.ExecParams) *input.ProverInput {
 	proverInput := &input.ProverInput{
 		ChainConfig: execParams.Chain.Config(),
@@ -29,3 +29,19 @@ func TestPreparer(t *testing.T) {
 func testDataInputsPath(filename string) string {
 	return "testdata/" + filename
 }
+
+func TestPreparerStorageProofDepth(t *testing.T) {
+	testDataInputs := loadTestDataInputs(t, testDataInputsPath(testcases[0]))
+
+	t.Run("full proofs", func(t *testing.T) {
+		result, err := NewPreparer(WithStorageProofDepth(64)).Prepare(context.Background(), &testDataInputs.PreflightData)
+		require.NoError(t, err)
+		require.True(t, input.CompareProverInput(&testDataInputs.ProverInput, result))
+	})
+
+	t.Run("shallow proofs", func(t *testing.T) {
+		_, err := NewPreparer(WithStorageProofDepth(1)).Prepare(context.Background(), &testDataInputs.PreflightData)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing")
+	})
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// profiler captures a CPU profile and optionally a heap profile scoped to a single execution
+type profiler struct {
+	cpuPath, heapPath string
+
+	cpuFile *os.File
+}
+
+// start starts CPU profiling
+// Only one CPU profile can be captured at a time per process, so it fails if another execution is being profiled
+func (p *profiler) start() error {
+	f, err := os.Create(p.cpuPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CPU profile: %v", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to start CPU profile: %v", err)
+	}
+	p.cpuFile = f
+	return nil
+}
+
+// stop stops CPU profiling and writes the heap profile if enabled
+func (p *profiler) stop() error {
+	pprof.StopCPUProfile()
+	if err := p.cpuFile.Close(); err != nil {
+		return fmt.Errorf("failed to close CPU profile: %v", err)
+	}
+
+	if p.heapPath == "" {
+		return nil
+	}
+
+	f, err := os.Create(p.heapPath)
+	if err != nil {
+		return fmt.Errorf("failed to create heap profile: %v", err)
+	}
+	defer f.Close()
+
+	runtime.GC() // Get up-to-date heap statistics
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write heap profile: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorWithProfiling(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {Code: []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, nil, 100_000, nil))
+	})
+
+	dir := t.TempDir()
+	cpuProfile, heapProfile := filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "heap.pprof")
+	_, err := NewExecutor(WithProfiling(cpuProfile, heapProfile)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	for _, path := range []string{cpuProfile, heapProfile} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.NotZero(t, info.Size(), path)
+	}
+}
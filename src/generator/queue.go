@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// ErrQueueFull is returned when submitting to an execution queue whose buffer is full
+var ErrQueueFull = errors.New("execution queue is full")
+
+// QueueResult is the outcome of the execution of a submitted prover input
+type QueueResult struct {
+	Input  *input.ProverInput
+	Result *Result
+	Err    error
+}
+
+// QueueMetrics is a snapshot of the state of an execution queue
+type QueueMetrics struct {
+	Depth     int    // Inputs waiting for a worker
+	InFlight  int    // Inputs being executed
+	Completed uint64 // Inputs executed successfully
+	Failed    uint64 // Inputs whose execution failed
+}
+
+type queueJob struct {
+	ctx    context.Context
+	input  *input.ProverInput
+	result chan *QueueResult
+}
+
+// ExecutionQueue executes submitted prover inputs with an executor, with bounded concurrency
+// Submitted inputs are buffered up to the queue capacity, applying backpressure on submitters once the buffer is full.
+type ExecutionQueue struct {
+	executor Executor
+	jobs     chan *queueJob
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+
+	inFlight  atomic.Int64
+	completed atomic.Uint64
+	failed    atomic.Uint64
+}
+
+// NewExecutionQueue creates a new execution queue running the given number of concurrent workers (at least one)
+// capacity is the number of inputs buffered while every worker is busy.
+func NewExecutionQueue(executor Executor, concurrency, capacity int) *ExecutionQueue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	q := &ExecutionQueue{
+		executor: executor,
+		jobs:     make(chan *queueJob, capacity),
+	}
+	for i := 0; i < concurrency; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Submit enqueues a prover input for execution, blocking while the queue is full
+// The returned channel receives the execution result once the input is executed with the given context.
+func (q *ExecutionQueue) Submit(ctx context.Context, pi *input.ProverInput) (<-chan *QueueResult, error) {
+	return q.submit(ctx, pi, true)
+}
+
+// TrySubmit enqueues a prover input for execution, returning ErrQueueFull rather than blocking if the queue is full
+func (q *ExecutionQueue) TrySubmit(ctx context.Context, pi *input.ProverInput) (<-chan *QueueResult, error) {
+	return q.submit(ctx, pi, false)
+}
+
+func (q *ExecutionQueue) submit(ctx context.Context, pi *input.ProverInput, block bool) (<-chan *QueueResult, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return nil, fmt.Errorf("execution queue closed")
+	}
+
+	job := &queueJob{ctx: ctx, input: pi, result: make(chan *QueueResult, 1)}
+	if !block {
+		select {
+		case q.jobs <- job:
+			return job.result, nil
+		default:
+			return nil, ErrQueueFull
+		}
+	}
+
+	select {
+	case q.jobs <- job:
+		return job.result, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to submit prover input: %v", ctx.Err())
+	}
+}
+
+func (q *ExecutionQueue) work() {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		q.inFlight.Add(1)
+		res, err := q.executor.Execute(job.ctx, job.input)
+		q.inFlight.Add(-1)
+		if err != nil {
+			q.failed.Add(1)
+		} else {
+			q.completed.Add(1)
+		}
+		job.result <- &QueueResult{Input: job.input, Result: res, Err: err}
+	}
+}
+
+// Metrics returns a snapshot of the queue metrics
+func (q *ExecutionQueue) Metrics() QueueMetrics {
+	return QueueMetrics{
+		Depth:     len(q.jobs),
+		InFlight:  int(q.inFlight.Load()),
+		Completed: q.completed.Load(),
+		Failed:    q.failed.Load(),
+	}
+}
+
+// Close stops accepting inputs and waits for the submitted inputs to be executed
+func (q *ExecutionQueue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
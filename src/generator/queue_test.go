@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionQueue(t *testing.T) {
+	inputs := generateTestProverInputs(t, testChainConfig, nil, 6, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), params.TxGas, nil))
+	})
+	inputs = append(inputs, &input.ProverInput{}) // Fails with no blocks
+
+	q := NewExecutionQueue(NewExecutor(), 2, 2)
+	results := make([]<-chan *QueueResult, 0, len(inputs))
+	for _, pi := range inputs {
+		ch, err := q.Submit(context.Background(), pi)
+		require.NoError(t, err)
+		results = append(results, ch)
+	}
+
+	for i, ch := range results {
+		res := <-ch
+		assert.Equal(t, inputs[i], res.Input)
+		if i < 6 {
+			require.NoError(t, res.Err)
+			assert.True(t, res.Result.Validated)
+		} else {
+			assert.Error(t, res.Err)
+		}
+	}
+	q.Close()
+
+	assert.Equal(t, QueueMetrics{Depth: 0, InFlight: 0, Completed: 6, Failed: 1}, q.Metrics())
+
+	_, err := q.Submit(context.Background(), inputs[0])
+	assert.Error(t, err)
+}
+
+// blockingExecutor is an executor blocking until released
+type blockingExecutor struct {
+	release chan struct{}
+}
+
+func (e *blockingExecutor) Execute(context.Context, *input.ProverInput) (*Result, error) {
+	<-e.release
+	return &Result{}, nil
+}
+
+func TestExecutionQueueBackpressure(t *testing.T) {
+	executor := &blockingExecutor{release: make(chan struct{})}
+	q := NewExecutionQueue(executor, 1, 1)
+
+	first, err := q.TrySubmit(context.Background(), &input.ProverInput{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return q.Metrics().InFlight == 1 }, time.Second, time.Millisecond)
+
+	second, err := q.TrySubmit(context.Background(), &input.ProverInput{})
+	require.NoError(t, err)
+	assert.Equal(t, QueueMetrics{Depth: 1, InFlight: 1}, q.Metrics())
+
+	_, err = q.TrySubmit(context.Background(), &input.ProverInput{})
+	assert.ErrorIs(t, err, ErrQueueFull)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = q.Submit(ctx, &input.ProverInput{})
+	assert.Error(t, err)
+
+	close(executor.release)
+	<-first
+	<-second
+	q.Close()
+	assert.Equal(t, QueueMetrics{Completed: 2}, q.Metrics())
+}
@@ -0,0 +1,30 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/ethdb/overlaydb"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// ValidateReadOnly executes and validates the prover input on top of the given database without ever writing to it
+// Every write of the execution (ancestors, codes, witness nodes and committed post-state) goes to an ephemeral in-memory overlay discarded
+// once the execution completes, so the validation has no persistent side effect on the database (e.g. for untrusted prover inputs).
+// It errors if the execution result could not be validated against the block (e.g. with custom rules or in dry run mode).
+func ValidateReadOnly(ctx context.Context, db ethdb.Database, inputs *input.ProverInput, opts ...ExecutorOption) (*Result, error) {
+	e := NewExecutor(opts...).(*executor)
+	overlay := overlaydb.New(db)
+	defer overlay.Close()
+	e.db = overlay
+
+	res, err := e.Execute(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Validated {
+		return nil, fmt.Errorf("read-only validation failed: execution result not validated")
+	}
+	return res, nil
+}
@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpDB returns every key-value entry of the database
+func dumpDB(t *testing.T, db ethdb.Database) map[string][]byte {
+	entries := make(map[string][]byte)
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		entries[string(it.Key())] = append([]byte{}, it.Value()...)
+	}
+	require.NoError(t, it.Error())
+	return entries
+}
+
+func TestValidateReadOnly(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	// The database already holds part of the pre-state
+	db := rawdb.NewMemoryDatabase()
+	for _, node := range proverInput.Witness.State[:len(proverInput.Witness.State)/2] {
+		require.NoError(t, db.Put(crypto.Keccak256(node), node))
+	}
+	before := dumpDB(t, db)
+
+	// The post-state is committed when exporting modified nodes
+	res, err := ValidateReadOnly(context.Background(), db, proverInput, WithModifiedNodes())
+	require.NoError(t, err)
+	assert.True(t, res.Validated)
+	assert.NotEmpty(t, res.ModifiedNodes)
+
+	assert.Equal(t, before, dumpDB(t, db))
+
+	_, err = ValidateReadOnly(context.Background(), db, proverInput, WithDryRun())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "execution result not validated")
+	assert.Equal(t, before, dumpDB(t, db))
+}
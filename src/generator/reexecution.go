@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// CheckReexecution executes the prover input the given number of times against a single reused database and returns the post-state root of every run
+// It is a test-mode operation catching state leaking from prior runs into the database (e.g. for pooled databases), it errors if any run
+// fails or reaches a post-state or receipts root different from the first run.
+func CheckReexecution(ctx context.Context, inputs *input.ProverInput, runs int, opts ...ExecutorOption) ([]gethcommon.Hash, error) {
+	e := NewExecutor(opts...).(*executor)
+	e.db = rawdb.NewMemoryDatabase()
+	defer e.db.Close()
+
+	roots := make([]gethcommon.Hash, 0, runs)
+	var first *Attestation
+	for i := 0; i < runs; i++ {
+		res, err := e.Execute(ctx, inputs)
+		if err != nil {
+			return roots, fmt.Errorf("run %d failed: %v", i, err)
+		}
+		roots = append(roots, res.attestation.PostStateRoot)
+
+		if first == nil {
+			first = &res.attestation
+			continue
+		}
+		if res.attestation.PostStateRoot != first.PostStateRoot {
+			return roots, fmt.Errorf("run %d post-state root %v differs from first run %v", i, res.attestation.PostStateRoot.Hex(), first.PostStateRoot.Hex())
+		}
+		if res.attestation.ReceiptsRoot != first.ReceiptsRoot {
+			return roots, fmt.Errorf("run %d receipts root %v differs from first run %v", i, res.attestation.ReceiptsRoot.Hex(), first.ReceiptsRoot.Hex())
+		}
+	}
+	return roots, nil
+}
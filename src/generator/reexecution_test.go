@@ -0,0 +1,34 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReexecution(t *testing.T) {
+	// Contract incrementing its slot 0 (SSTORE(0, ADD(SLOAD(0), 1))), so state leaking across runs would change the post-state root
+	alloc := gethtypes.GenesisAlloc{
+		testContractAddr: {
+			Code: []byte{byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)},
+		},
+	}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	roots, err := CheckReexecution(context.Background(), proverInput, 5)
+	require.NoError(t, err)
+	require.Len(t, roots, 5)
+	for _, root := range roots {
+		assert.Equal(t, proverInput.Blocks[0].Header.Root, root)
+	}
+	assert.NotEqual(t, gethcommon.Hash{}, roots[0])
+}
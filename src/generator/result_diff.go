@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"bytes"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// HashDiff is a hash that differs between two results
+type HashDiff struct {
+	A gethcommon.Hash `json:"a"`
+	B gethcommon.Hash `json:"b"`
+}
+
+// Uint64Diff is a number that differs between two results
+type Uint64Diff struct {
+	A uint64 `json:"a"`
+	B uint64 `json:"b"`
+}
+
+// TxResultDiff is the difference between the receipts of a transaction in two results
+type TxResultDiff struct {
+	Index   int             `json:"index"`
+	TxHash  gethcommon.Hash `json:"txHash"`
+	Status  *Uint64Diff     `json:"status,omitempty"`
+	GasUsed *Uint64Diff     `json:"gasUsed,omitempty"`
+
+	// Indexes within the transaction of the logs that differ (missing from either result, or with a different address, topics or data)
+	Logs []int `json:"logs,omitempty"`
+}
+
+// ResultDiff is the difference between two execution results of the same prover input (e.g. under two go-ethereum versions)
+// Only the fields that differ are set.
+type ResultDiff struct {
+	PostStateRoot *HashDiff       `json:"postStateRoot,omitempty"`
+	ReceiptsRoot  *HashDiff       `json:"receiptsRoot,omitempty"`
+	GasUsed       *Uint64Diff     `json:"gasUsed,omitempty"`
+	LogCount      *Uint64Diff     `json:"logCount,omitempty"`
+	TxCount       *Uint64Diff     `json:"txCount,omitempty"`
+	Txs           []*TxResultDiff `json:"txs,omitempty"` // Transactions present in both results whose receipts differ
+}
+
+// Empty returns whether both results are identical
+func (d *ResultDiff) Empty() bool {
+	return d.PostStateRoot == nil && d.ReceiptsRoot == nil && d.GasUsed == nil && d.LogCount == nil && d.TxCount == nil && len(d.Txs) == 0
+}
+
+// CompareResults compares two execution results, field by field and transaction by transaction
+func CompareResults(a, b *Result) *ResultDiff {
+	diff := &ResultDiff{
+		PostStateRoot: diffHash(a.attestation.PostStateRoot, b.attestation.PostStateRoot),
+		ReceiptsRoot:  diffHash(a.attestation.ReceiptsRoot, b.attestation.ReceiptsRoot),
+		GasUsed:       diffUint64(a.ProcessResult.GasUsed, b.ProcessResult.GasUsed),
+		LogCount:      diffUint64(uint64(len(a.ProcessResult.Logs)), uint64(len(b.ProcessResult.Logs))),
+	}
+
+	receiptsA, receiptsB := a.ProcessResult.Receipts, b.ProcessResult.Receipts
+	diff.TxCount = diffUint64(uint64(len(receiptsA)), uint64(len(receiptsB)))
+	for i := 0; i < len(receiptsA) && i < len(receiptsB); i++ {
+		txDiff := &TxResultDiff{
+			Index:   i,
+			TxHash:  receiptsA[i].TxHash,
+			Status:  diffUint64(receiptsA[i].Status, receiptsB[i].Status),
+			GasUsed: diffUint64(receiptsA[i].GasUsed, receiptsB[i].GasUsed),
+			Logs:    diffLogs(receiptsA[i].Logs, receiptsB[i].Logs),
+		}
+		if txDiff.Status != nil || txDiff.GasUsed != nil || len(txDiff.Logs) > 0 {
+			diff.Txs = append(diff.Txs, txDiff)
+		}
+	}
+	return diff
+}
+
+func diffHash(a, b gethcommon.Hash) *HashDiff {
+	if a == b {
+		return nil
+	}
+	return &HashDiff{A: a, B: b}
+}
+
+func diffUint64(a, b uint64) *Uint64Diff {
+	if a == b {
+		return nil
+	}
+	return &Uint64Diff{A: a, B: b}
+}
+
+func diffLogs(a, b []*gethtypes.Log) []int {
+	var indexes []int
+	for i := 0; i < len(a) || i < len(b); i++ {
+		if i >= len(a) || i >= len(b) || !equalLogs(a[i], b[i]) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func equalLogs(a, b *gethtypes.Log) bool {
+	if a.Address != b.Address || len(a.Topics) != len(b.Topics) || !bytes.Equal(a.Data, b.Data) {
+		return false
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+	return true
+}
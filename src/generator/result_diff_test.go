@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareResultsDiff(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInput := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+
+	a, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	b, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+
+	diff := CompareResults(a, b)
+	assert.True(t, diff.Empty())
+
+	// Simulate a build charging more gas for the transaction
+	b.ProcessResult.GasUsed += 100
+	b.ProcessResult.Receipts[0].GasUsed += 100
+
+	diff = CompareResults(a, b)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, &Uint64Diff{A: a.ProcessResult.GasUsed, B: a.ProcessResult.GasUsed + 100}, diff.GasUsed)
+	require.Len(t, diff.Txs, 1)
+	assert.Equal(t, 0, diff.Txs[0].Index)
+	assert.Equal(t, &Uint64Diff{A: a.ProcessResult.Receipts[0].GasUsed, B: a.ProcessResult.Receipts[0].GasUsed + 100}, diff.Txs[0].GasUsed)
+	assert.Nil(t, diff.Txs[0].Status)
+	assert.Nil(t, diff.PostStateRoot)
+	assert.Nil(t, diff.TxCount)
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/go-utils/tag"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// RethGenerator generates prover inputs from the execution witness computed by a Reth node (debug_executionWitness RPC method)
+// The node executes the block and returns the state nodes, codes and ancestors it accessed, so no state proofs need to be fetched.
+type RethGenerator struct {
+	client      *gethrpc.Client
+	chainConfig *params.ChainConfig
+}
+
+// RethOption is an option for the Reth generator
+type RethOption func(*RethGenerator)
+
+// WithRethChainConfig sets the chain configuration of generated prover inputs (by default, it is derived from the chain ID of the node)
+func WithRethChainConfig(cfg *params.ChainConfig) RethOption {
+	return func(g *RethGenerator) {
+		g.chainConfig = cfg
+	}
+}
+
+// NewRethGenerator creates a new Reth generator using the given RPC client
+func NewRethGenerator(client *gethrpc.Client, opts ...RethOption) *RethGenerator {
+	g := &RethGenerator{client: client}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// rethExecutionWitness is the execution witness returned by the debug_executionWitness RPC method of Reth
+type rethExecutionWitness struct {
+	State   []hexutil.Bytes `json:"state"`   // Trie nodes accessed during execution
+	Codes   []hexutil.Bytes `json:"codes"`   // Contract bytecodes accessed during execution
+	Keys    []hexutil.Bytes `json:"keys"`    // Preimages of the hashed account addresses and storage slots (unused)
+	Headers []hexutil.Bytes `json:"headers"` // RLP encoded ancestor headers accessed during execution
+}
+
+// Generate generates the prover input of the block with the given number
+func (g *RethGenerator) Generate(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error) {
+	ctx = tag.WithComponent(ctx, "reth")
+	ctx = tag.WithTags(ctx, tag.Key("block.number").Int64(blockNumber.Int64()))
+
+	proverInput, err := g.generate(ctx, blockNumber)
+	if err != nil {
+		log.LoggerFromContext(ctx).Error("Prover input generation from Reth execution witness failed", zap.Error(err))
+		return nil, err
+	}
+	log.LoggerFromContext(ctx).Info("Prover input generation from Reth execution witness succeeded")
+
+	return proverInput, nil
+}
+
+func (g *RethGenerator) generate(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error) {
+	if blockNumber.Sign() == 0 {
+		return nil, fmt.Errorf("can not generate prover input for genesis block")
+	}
+
+	chainCfg := g.chainConfig
+	if chainCfg == nil {
+		var chainID hexutil.Big
+		if err := g.client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+			return nil, fmt.Errorf("failed to fetch chain ID: %v", err)
+		}
+		cfg, err := getChainConfig(chainID.ToInt())
+		if err != nil {
+			return nil, err
+		}
+		chainCfg = cfg
+	}
+
+	var rawBlock hexutil.Bytes
+	if err := g.client.CallContext(ctx, &rawBlock, "debug_getRawBlock", hexutil.EncodeBig(blockNumber)); err != nil {
+		return nil, fmt.Errorf("failed to fetch block: %v", err)
+	}
+	block := new(gethtypes.Block)
+	if err := rlp.DecodeBytes(rawBlock, block); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %v", err)
+	}
+
+	var witness rethExecutionWitness
+	if err := g.client.CallContext(ctx, &witness, "debug_executionWitness", hexutil.EncodeBig(blockNumber)); err != nil {
+		return nil, fmt.Errorf("failed to fetch execution witness: %v", err)
+	}
+
+	ancestors, err := g.ancestors(ctx, block, witness.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &input.ProverInput{
+		ChainConfig: chainCfg,
+		Blocks: []*input.Block{
+			{
+				Header:       block.Header(),
+				Transactions: block.Transactions(),
+				Uncles:       block.Uncles(),
+				Withdrawals:  block.Withdrawals(),
+			},
+		},
+		Witness: &input.Witness{
+			State:     witness.State,
+			Ancestors: ancestors,
+			Codes:     witness.Codes,
+		},
+	}, nil
+}
+
+// ancestors decodes the witness headers and orders them from the block parent backwards
+// Nodes may not return the parent header when the block does not access ancestors (e.g. older Reth versions), it is then fetched.
+func (g *RethGenerator) ancestors(ctx context.Context, block *gethtypes.Block, rawHeaders []hexutil.Bytes) ([]*gethtypes.Header, error) {
+	ancestors := make([]*gethtypes.Header, 0, len(rawHeaders))
+	for i, raw := range rawHeaders {
+		header := new(gethtypes.Header)
+		if err := rlp.DecodeBytes(raw, header); err != nil {
+			return nil, fmt.Errorf("failed to decode witness header %d: %v", i, err)
+		}
+		ancestors = append(ancestors, header)
+	}
+	sort.Slice(ancestors, func(i, j int) bool {
+		return ancestors[i].Number.Cmp(ancestors[j].Number) > 0
+	})
+
+	if len(ancestors) == 0 || ancestors[0].Hash() != block.ParentHash() {
+		var rawParent hexutil.Bytes
+		if err := g.client.CallContext(ctx, &rawParent, "debug_getRawHeader", hexutil.EncodeBig(new(big.Int).Sub(block.Number(), big.NewInt(1)))); err != nil {
+			return nil, fmt.Errorf("failed to fetch parent header: %v", err)
+		}
+		parent := new(gethtypes.Header)
+		if err := rlp.DecodeBytes(rawParent, parent); err != nil {
+			return nil, fmt.Errorf("failed to decode parent header: %v", err)
+		}
+		if parent.Hash() != block.ParentHash() {
+			return nil, fmt.Errorf("parent header %v does not match block parent hash %v", parent.Hash().Hex(), block.ParentHash().Hex())
+		}
+		ancestors = append([]*gethtypes.Header{parent}, ancestors...)
+	}
+
+	return ancestors, nil
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rethDebugAPI serves the debug RPC methods of a Reth node used by the Reth generator
+type rethDebugAPI struct {
+	block   hexutil.Bytes
+	parent  hexutil.Bytes
+	witness map[string][]string // Wire format of the Reth execution witness
+}
+
+func (api *rethDebugAPI) GetRawBlock(_ hexutil.Big) (hexutil.Bytes, error) {
+	return api.block, nil
+}
+
+func (api *rethDebugAPI) GetRawHeader(_ hexutil.Big) (hexutil.Bytes, error) {
+	return api.parent, nil
+}
+
+func (api *rethDebugAPI) ExecutionWitness(_ hexutil.Big) (map[string][]string, error) {
+	return api.witness, nil
+}
+
+// newRethFixture returns the debug RPC API of a Reth node serving the block of the prover input, with the witness in the Reth wire format
+func newRethFixture(t *testing.T, pi *input.ProverInput, withHeaders bool) *rethDebugAPI {
+	block, err := rlp.EncodeToBytes(pi.Blocks[0].Block())
+	require.NoError(t, err)
+	parent, err := rlp.EncodeToBytes(pi.Witness.Ancestors[0])
+	require.NoError(t, err)
+
+	witness := map[string][]string{"state": {}, "codes": {}, "keys": {}, "headers": {}}
+	for _, node := range pi.Witness.State {
+		witness["state"] = append(witness["state"], node.String())
+	}
+	for _, code := range pi.Witness.Codes {
+		witness["codes"] = append(witness["codes"], code.String())
+	}
+	if withHeaders {
+		// Reth returns headers by ascending block number
+		for i := len(pi.Witness.Ancestors) - 1; i >= 0; i-- {
+			header, err := rlp.EncodeToBytes(pi.Witness.Ancestors[i])
+			require.NoError(t, err)
+			witness["headers"] = append(witness["headers"], hexutil.Encode(header))
+		}
+	}
+
+	return &rethDebugAPI{block: block, parent: parent, witness: witness}
+}
+
+func TestRethGenerator(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	proverInputs := generateTestProverInputs(t, testChainConfig, alloc, 2, func(_ int, b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(1), 100_000, nil))
+	})
+	proverInput := proverInputs[1]
+
+	for _, withHeaders := range []bool{true, false} {
+		t.Run(fmt.Sprintf("headers=%v", withHeaders), func(t *testing.T) {
+			server := gethrpc.NewServer()
+			defer server.Stop()
+			require.NoError(t, server.RegisterName("debug", newRethFixture(t, proverInput, withHeaders)))
+			client := gethrpc.DialInProc(server)
+			defer client.Close()
+
+			generated, err := NewRethGenerator(client, WithRethChainConfig(testChainConfig)).Generate(context.Background(), big.NewInt(2))
+			require.NoError(t, err)
+			require.Len(t, generated.Blocks, 1)
+			assert.Equal(t, proverInput.Blocks[0].Header.Hash(), generated.Blocks[0].Header.Hash())
+			// Blocks decoded from RLP have empty rather than nil uncles, the witness is compared on its own
+			witnessOnly := *generated
+			witnessOnly.Blocks = proverInput.Blocks
+			equal, diff := input.CompareProverInputWithDiff(proverInput, &witnessOnly)
+			require.True(t, equal, diff)
+
+			res, err := NewExecutor().Execute(context.Background(), generated)
+			require.NoError(t, err)
+			assert.Equal(t, proverInput.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+		})
+	}
+}
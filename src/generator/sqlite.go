@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // Registers the sqlite database/sql driver
+)
+
+// sqliteSchema is the schema of the SQLite results database, with one BlockResultRow per row
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS block_results (
+	line                 INTEGER NOT NULL,
+	block_number         INTEGER NOT NULL,
+	gas_used             INTEGER NOT NULL,
+	tx_count             INTEGER NOT NULL,
+	load_duration_ms     REAL NOT NULL,
+	exec_duration_ms     REAL NOT NULL,
+	witness_size         INTEGER NOT NULL,
+	witness_size_per_gas REAL NOT NULL,
+	status               TEXT NOT NULL,
+	error                TEXT
+);
+CREATE INDEX IF NOT EXISTS block_results_block_number ON block_results (block_number);`
+
+// SQLiteSink writes manifest results into the block_results table of an SQLite database, for ad-hoc queries across large runs
+// Results are appended, so the results of several runs can be gathered in the same database.
+type SQLiteSink struct {
+	db *sql.DB
+}
+
+// NewSQLiteSink opens (or creates) the SQLite database at the given path and creates the results schema if needed
+func NewSQLiteSink(path string) (*SQLiteSink, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %v", err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sqlite schema: %v", err)
+	}
+	return &SQLiteSink{db: db}, nil
+}
+
+// Write inserts the manifest results in a single transaction
+func (s *SQLiteSink) Write(results ...*ManifestResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin sqlite transaction: %v", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // No-op once committed
+
+	stmt, err := tx.Prepare(`INSERT INTO block_results (line, block_number, gas_used, tx_count, load_duration_ms, exec_duration_ms, witness_size, witness_size_per_gas, status, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare sqlite insert: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, res := range results {
+		row := NewBlockResultRow(res)
+		var errMsg sql.NullString
+		if row.Error != "" {
+			errMsg = sql.NullString{String: row.Error, Valid: true}
+		}
+		if _, err := stmt.Exec(row.Line, row.BlockNumber, row.GasUsed, row.TxCount, row.LoadDurationMs, row.ExecDurationMs, row.WitnessSize, row.WitnessSizePerGas, row.Status, errMsg); err != nil {
+			return fmt.Errorf("failed to insert result of line %d: %v", row.Line, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit sqlite transaction: %v", err)
+	}
+	return nil
+}
+
+// DB returns the underlying database, e.g. to query the results
+func (s *SQLiteSink) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database
+func (s *SQLiteSink) Close() error {
+	return s.db.Close()
+}
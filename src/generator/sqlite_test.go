@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.db")
+	sink, err := NewSQLiteSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(
+		&ManifestResult{
+			Line:         1,
+			BlockNumber:  21465322,
+			TxCount:      3,
+			LoadDuration: 1500 * time.Microsecond,
+			ExecDuration: 20 * time.Millisecond,
+			Result: &Result{
+				ProcessResult:     &core.ProcessResult{GasUsed: 63_000},
+				WitnessSize:       12_600,
+				WitnessSizePerGas: 0.2,
+			},
+		},
+		&ManifestResult{
+			Line:         2,
+			BlockNumber:  21465323,
+			LoadDuration: time.Millisecond,
+			Err:          fmt.Errorf("block hash mismatch"),
+		},
+	))
+	require.NoError(t, sink.Close())
+
+	// Results are persisted and can be queried from a new connection
+	sink, err = NewSQLiteSink(path)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	rows, err := sink.DB().Query(`SELECT line, block_number, gas_used, tx_count, load_duration_ms, exec_duration_ms, witness_size, witness_size_per_gas, status, error FROM block_results ORDER BY line`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var got []BlockResultRow
+	for rows.Next() {
+		var (
+			row    BlockResultRow
+			errMsg sql.NullString
+		)
+		require.NoError(t, rows.Scan(&row.Line, &row.BlockNumber, &row.GasUsed, &row.TxCount, &row.LoadDurationMs, &row.ExecDurationMs, &row.WitnessSize, &row.WitnessSizePerGas, &row.Status, &errMsg))
+		row.Error = errMsg.String
+		got = append(got, row)
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, []BlockResultRow{
+		{
+			Line:              1,
+			BlockNumber:       21465322,
+			GasUsed:           63_000,
+			TxCount:           3,
+			LoadDurationMs:    1.5,
+			ExecDurationMs:    20,
+			WitnessSize:       12_600,
+			WitnessSizePerGas: 0.2,
+			Status:            BlockStatusSucceeded,
+		},
+		{
+			Line:           2,
+			BlockNumber:    21465323,
+			LoadDurationMs: 1,
+			Status:         BlockStatusFailed,
+			Error:          "block hash mismatch",
+		},
+	}, got)
+
+	var failed int
+	require.NoError(t, sink.DB().QueryRow(`SELECT COUNT(*) FROM block_results WHERE status = ?`, BlockStatusFailed).Scan(&failed))
+	assert.Equal(t, 1, failed)
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// WitnessStrategy generates the prover input of a block with a given witness generation strategy (e.g. the Generate method of a DatadirGenerator)
+type WitnessStrategy func(ctx context.Context, blockNumber *big.Int) (*input.ProverInput, error)
+
+// StrategyReport reports the prover input generated by a witness generation strategy
+type StrategyReport struct {
+	Size       input.SizeReport // Serialized size of the generated input (zero if generation failed)
+	Executable bool             // Whether the generated input executes and validates
+	Err        error            // Generation or execution error
+}
+
+// StrategyComparison compares the prover inputs generated by two witness generation strategies for the same block
+type StrategyComparison struct {
+	A, B *StrategyReport
+
+	// SizeDelta is the serialized size of B's input minus the size of A's input (negative if B's input is smaller)
+	SizeDelta int
+}
+
+// CompareStrategies generates the prover input of the block with both strategies, executes each input and compares their sizes
+// Generation and execution failures are reported per strategy rather than returned, so a failing strategy can still be compared.
+func CompareStrategies(ctx context.Context, blockNumber *big.Int, a, b WitnessStrategy, opts ...ExecutorOption) *StrategyComparison {
+	executor := NewExecutor(opts...)
+	run := func(strategy WitnessStrategy) *StrategyReport {
+		pi, err := strategy(ctx, new(big.Int).Set(blockNumber))
+		if err != nil {
+			return &StrategyReport{Err: err}
+		}
+		report := &StrategyReport{Size: input.SizeBreakdown(pi)}
+		res, err := executor.Execute(ctx, pi)
+		report.Executable = err == nil && res.Validated
+		report.Err = err
+		return report
+	}
+
+	comparison := &StrategyComparison{A: run(a), B: run(b)}
+	comparison.SizeDelta = comparison.B.Size.Total - comparison.A.Size.Total
+	return comparison
+}
@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareStrategies(t *testing.T) {
+	alloc := gethtypes.GenesisAlloc{testContractAddr: {Code: testCounterCode}}
+	minimal := generateTestProverInput(t, testChainConfig, alloc, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testContractAddr, big.NewInt(0), 100_000, nil))
+	})
+	other := generateTestProverInput(t, testChainConfig, nil, func(b *core.BlockGen) {
+		b.AddTx(signTestTx(t, b, testChainConfig, &testAddr, big.NewInt(1), params.TxGas, nil))
+	})
+
+	// The naive strategy includes state nodes and codes that the block does not access
+	withWitness := func(state, codes []hexutil.Bytes) WitnessStrategy {
+		return func(_ context.Context, blockNumber *big.Int) (*input.ProverInput, error) {
+			require.Equal(t, minimal.Blocks[0].Header.Number, blockNumber)
+			pi := *minimal
+			witness := *minimal.Witness
+			witness.State, witness.Codes = state, codes
+			pi.Witness = &witness
+			return &pi, nil
+		}
+	}
+	naive := withWitness(
+		append(append([]hexutil.Bytes(nil), other.Witness.State...), minimal.Witness.State...),
+		append(append([]hexutil.Bytes(nil), minimal.Witness.Codes...), []byte{0x00}),
+	)
+
+	comparison := CompareStrategies(context.Background(), minimal.Blocks[0].Header.Number, naive, withWitness(minimal.Witness.State, minimal.Witness.Codes))
+	require.NoError(t, comparison.A.Err)
+	require.NoError(t, comparison.B.Err)
+	assert.True(t, comparison.A.Executable)
+	assert.True(t, comparison.B.Executable)
+	assert.Less(t, comparison.B.Size.Total, comparison.A.Size.Total)
+	assert.Equal(t, comparison.B.Size.Total-comparison.A.Size.Total, comparison.SizeDelta)
+
+	// Strategies producing incomplete witnesses or failing are reported as not executable
+	failing := func(context.Context, *big.Int) (*input.ProverInput, error) {
+		return nil, fmt.Errorf("generation failed")
+	}
+	comparison = CompareStrategies(context.Background(), minimal.Blocks[0].Header.Number, withWitness(nil, minimal.Witness.Codes), failing)
+	assert.False(t, comparison.A.Executable)
+	assert.Error(t, comparison.A.Err)
+	assert.False(t, comparison.B.Executable)
+	assert.EqualError(t, comparison.B.Err, "generation failed")
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/kkrt-labs/go-utils/log"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+)
+
+// executeTargetFork executes the block on a fresh pre-state with the chain of the target fork and returns the witness of the execution
+// The execution is not validated, as the block header commits to the execution under the fork active at the block.
+func executeTargetFork(ctx context.Context, hc *core.HeaderChain, stateDB gethstate.Database, block *gethtypes.Block, parentRoot gethcommon.Hash) (*stateless.Witness, error) {
+	log.LoggerFromContext(ctx).Info("Execute EVM under target fork...")
+
+	preState, err := gethstate.New(parentRoot, stateDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-state from parent root %v: %v", parentRoot, err)
+	}
+
+	execParams := &evm.ExecParams{
+		VMConfig: &vm.Config{
+			StatelessSelfValidation: true,
+		},
+		Block:    block,
+		Validate: false,
+		Chain:    hc,
+		State:    preState,
+	}
+	if _, err := evm.ExecutorWithTags("evm")(evm.ExecutorWithLog()(evm.NewExecutor())).Execute(ctx, execParams); err != nil {
+		return nil, fmt.Errorf("failed to execute block under target fork: %v", err)
+	}
+
+	return preState.Witness(), nil
+}
+
+// mergeWitness adds the state nodes, codes and headers of src into dst
+func mergeWitness(dst, src *stateless.Witness) {
+	for code := range src.Codes {
+		dst.Codes[code] = struct{}{}
+	}
+	for node := range src.State {
+		dst.State[node] = struct{}{}
+	}
+	// Headers are ordered from the parent backwards, so the longest list contains the other
+	if len(src.Headers) > len(dst.Headers) {
+		dst.Headers = src.Headers
+	}
+}
@@ -0,0 +1,173 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethstate "github.com/ethereum/go-ethereum/core/state"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/hashdb"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/evm"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr   = crypto.PubkeyToAddress(testKey.PublicKey)
+
+	// testContractAddr is the address at which test contracts are deployed
+	testContractAddr = gethcommon.HexToAddress("0x00000000000000000000000000000000000c0de0")
+
+	// testCounterCode is the code of a contract incrementing its storage slot 0 (PUSH1 0x00 SLOAD PUSH1 0x01 ADD PUSH1 0x00 SSTORE STOP)
+	testCounterCode = []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP),
+	}
+)
+
+// testChainConfig is a post-merge chain configuration with every fork up to Cancun activated at genesis
+var testChainConfig = func() *params.ChainConfig {
+	cfg := *params.MergedTestChainConfig
+	cfg.PragueTime = nil
+	cfg.VerkleTime = nil
+	return &cfg
+}()
+
+// testGenesis returns a genesis with the given allocation and the test account funded
+func testGenesis(cfg *params.ChainConfig, alloc gethtypes.GenesisAlloc) *core.Genesis {
+	genesisAlloc := gethtypes.GenesisAlloc{
+		testAddr: {Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(100))},
+	}
+	for addr, account := range alloc {
+		genesisAlloc[addr] = account
+	}
+
+	return &core.Genesis{
+		Config:     cfg,
+		Alloc:      genesisAlloc,
+		GasLimit:   30_000_000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+	}
+}
+
+// generateTestProverInput generates a single block on top of a genesis with the given allocation
+// and returns the prover input necessary to execute it
+func generateTestProverInput(t testing.TB, cfg *params.ChainConfig, alloc gethtypes.GenesisAlloc, gen func(*core.BlockGen)) *input.ProverInput {
+	inputs := generateTestProverInputs(t, cfg, alloc, 1, func(_ int, b *core.BlockGen) { gen(b) })
+	return inputs[0]
+}
+
+// generateTestProverInputs generates a chain of n blocks on top of a genesis with the given allocation
+// and returns the prover inputs necessary to execute each block
+func generateTestProverInputs(t testing.TB, cfg *params.ChainConfig, alloc gethtypes.GenesisAlloc, n int, gen func(int, *core.BlockGen)) []*input.ProverInput {
+	return generateTestProverInputsWithEngine(t, cfg, beacon.New(ethash.NewFaker()), alloc, n, gen)
+}
+
+// generateTestProverInputsWithEngine is generateTestProverInputs with blocks sealed and finalized by the given consensus engine
+func generateTestProverInputsWithEngine(t testing.TB, cfg *params.ChainConfig, engine consensus.Engine, alloc gethtypes.GenesisAlloc, n int, gen func(int, *core.BlockGen)) []*input.ProverInput {
+	db := rawdb.NewMemoryDatabase()
+	trieDB := triedb.NewDatabase(db, &triedb.Config{HashDB: &hashdb.Config{}})
+	genesisBlock := testGenesis(cfg, alloc).MustCommit(db, trieDB)
+
+	blocks, _ := core.GenerateChain(cfg, genesisBlock, engine, db, n, func(i int, b *core.BlockGen) {
+		// Chains with a zero terminal total difficulty are post-merge from genesis
+		if cfg.TerminalTotalDifficulty != nil && cfg.TerminalTotalDifficulty.Sign() == 0 {
+			b.SetPoS()
+		}
+		gen(i, b)
+	})
+
+	hc, err := core.NewHeaderChain(db, cfg, engine, nil)
+	require.NoError(t, err)
+	for _, block := range blocks {
+		rawdb.WriteHeader(db, block.Header())
+	}
+
+	inputs := make([]*input.ProverInput, 0, len(blocks))
+	parent := genesisBlock.Header()
+	for _, block := range blocks {
+		preState, err := gethstate.New(parent.Root, gethstate.NewDatabase(trieDB, nil))
+		require.NoError(t, err)
+
+		execParams := &evm.ExecParams{
+			VMConfig: &vm.Config{
+				StatelessSelfValidation: true,
+			},
+			Block:    block,
+			Validate: true,
+			Chain:    hc,
+			State:    preState,
+		}
+		_, err = evm.NewExecutor().Execute(context.Background(), execParams)
+		require.NoError(t, err)
+
+		if execParams.State.Witness() == nil {
+			// No witness is recorded before Byzantium, so the whole database state is provided
+			inputs = append(inputs, fullStateProverInput(t, db, cfg, parent, block))
+		} else {
+			inputs = append(inputs, new(preparer).prepareProverInput(nil, execParams))
+		}
+		parent = block.Header()
+	}
+
+	return inputs
+}
+
+// fullStateProverInput returns the prover input of the block with every trie node and code of the database as witness
+func fullStateProverInput(t testing.TB, db ethdb.Database, cfg *params.ChainConfig, parent *gethtypes.Header, block *gethtypes.Block) *input.ProverInput {
+	witness := &input.Witness{Ancestors: []*gethtypes.Header{parent}}
+
+	it := db.NewIterator(nil, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) == gethcommon.HashLength && crypto.Keccak256Hash(it.Value()) == gethcommon.BytesToHash(it.Key()) {
+			witness.State = append(witness.State, gethcommon.CopyBytes(it.Value()))
+		}
+		if ok, hash := rawdb.IsCodeKey(it.Key()); ok && crypto.Keccak256Hash(it.Value()) == gethcommon.BytesToHash(hash) {
+			witness.Codes = append(witness.Codes, gethcommon.CopyBytes(it.Value()))
+		}
+	}
+	require.NoError(t, it.Error())
+
+	return &input.ProverInput{
+		ChainConfig: cfg,
+		Blocks: []*input.Block{
+			{
+				Header:       block.Header(),
+				Transactions: block.Transactions(),
+				Uncles:       block.Uncles(),
+				Withdrawals:  block.Withdrawals(),
+			},
+		},
+		Witness: witness,
+	}
+}
+
+// signTestTx signs a dynamic fee transaction from the test account
+func signTestTx(t testing.TB, b *core.BlockGen, cfg *params.ChainConfig, to *gethcommon.Address, value *big.Int, gas uint64, data []byte) *gethtypes.Transaction {
+	tx, err := gethtypes.SignNewTx(testKey, gethtypes.LatestSigner(cfg), &gethtypes.DynamicFeeTx{
+		ChainID:   cfg.ChainID,
+		Nonce:     b.TxNonce(testAddr),
+		To:        to,
+		Value:     value,
+		Gas:       gas,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(b.BaseFee(), big.NewInt(params.GWei)),
+		Data:      data,
+	})
+	require.NoError(t, err)
+	return tx
+}
@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"fmt"
+	"maps"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kkrt-labs/zk-pig/src/ethereum"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// WitnessBackend loads the pre-state witness of provable inputs into the execution database
+// It allows supporting witness formats specific to the state tree of the chain (e.g. MPT or verkle)
+type WitnessBackend interface {
+	// LoadPreState preloads the pre-state witness into the database
+	// It errors if the witness does not match the given pre-state root
+	LoadPreState(db ethdb.Database, root gethcommon.Hash, witness *input.Witness) error
+}
+
+// selectWitnessBackend returns the witness backend for executing the block with the given chain configuration
+// MPT is the default backend, nodeBatchSize is the number of MPT nodes written per database batch (zero for per-node writes)
+func selectWitnessBackend(cfg *params.ChainConfig, header *gethtypes.Header, nodeBatchSize int) WitnessBackend {
+	if cfg.IsVerkle(header.Number, header.Time) {
+		return new(verkleWitnessBackend)
+	}
+	return &mptWitnessBackend{batchSize: nodeBatchSize}
+}
+
+// mptWitnessBackend loads a witness consisting of MPT nodes
+type mptWitnessBackend struct {
+	batchSize int
+}
+
+func (b *mptWitnessBackend) LoadPreState(db ethdb.Database, root gethcommon.Hash, witness *input.Witness) error {
+	nodes := make([][]byte, 0)
+	for _, node := range witness.State {
+		nodes = append(nodes, node)
+	}
+
+	if b.batchSize > 0 {
+		if err := ethereum.WriteNodesToHashDBBatched(db, b.batchSize, nodes...); err != nil {
+			return err
+		}
+	} else {
+		ethereum.WriteNodesToHashDB(db, nodes...)
+	}
+
+	return verifyPreStateRoot(db, root, trie.NodesByHash(nodes...))
+}
+
+// verifyPreStateRoot resolves the pre-state trie and asserts the root of the reconstructed trie equals the parent state root
+// The root node is read from the database, so it is fetched when the database falls back to a remote node (see rpcdb.NodeFallback).
+// Below the root, the trie is resolved to the extent the witness allows: every reachable node, including storage tries, must decode
+// (nodes are indexed by hash, so a resolved node always hashes to the reference of its parent).
+func verifyPreStateRoot(db ethdb.KeyValueReader, root gethcommon.Hash, nodes map[gethcommon.Hash][]byte) error {
+	if root == gethtypes.EmptyRootHash {
+		return nil
+	}
+
+	blob, err := db.Get(root.Bytes())
+	if err != nil || len(blob) == 0 {
+		return fmt.Errorf("pre-state root mismatch: root node of parent state root %v is not available (not in witness): %v", root.Hex(), err)
+	}
+	if hash := crypto.Keccak256Hash(blob); hash != root {
+		return fmt.Errorf("pre-state root mismatch: reconstructed root %v does not match parent state root %v", hash.Hex(), root.Hex())
+	}
+
+	resolved := make(map[gethcommon.Hash][]byte, len(nodes)+1)
+	maps.Copy(resolved, nodes)
+	resolved[root] = blob
+
+	var storageRoots []gethcommon.Hash
+	err = trie.Walk(root, resolved, &trie.Visitor{
+		OnLeaf: func(_, value []byte) {
+			var account gethtypes.StateAccount
+			if err := rlp.DecodeBytes(value, &account); err == nil {
+				storageRoots = append(storageRoots, account.Root)
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid pre-state trie: %v", err)
+	}
+	for _, storageRoot := range storageRoots {
+		if err := trie.Walk(storageRoot, resolved, &trie.Visitor{}); err != nil {
+			return fmt.Errorf("invalid pre-state storage trie %v: %v", storageRoot.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// verkleWitnessBackend is a placeholder for verkle witnesses
+type verkleWitnessBackend struct{}
+
+func (b *verkleWitnessBackend) LoadPreState(_ ethdb.Database, _ gethcommon.Hash, _ *input.Witness) error {
+	return fmt.Errorf("verkle witness not yet supported for this fork")
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kkrt-labs/zk-pig/src/ethereum"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectWitnessBackend(t *testing.T) {
+	verkleTime := uint64(100)
+	verkleCfg := *testChainConfig
+	verkleCfg.PragueTime = &verkleTime
+	verkleCfg.VerkleTime = &verkleTime
+
+	header := func(time uint64) *gethtypes.Header {
+		return &gethtypes.Header{Number: big.NewInt(1), Time: time}
+	}
+
+	assert.IsType(t, new(mptWitnessBackend), selectWitnessBackend(testChainConfig, header(200), 0))
+	assert.IsType(t, new(mptWitnessBackend), selectWitnessBackend(&verkleCfg, header(99), 0))
+	assert.IsType(t, new(verkleWitnessBackend), selectWitnessBackend(&verkleCfg, header(100), 0))
+}
+
+func TestExecutorVerkleWitness(t *testing.T) {
+	verkleTime := uint64(0)
+	cfg := *params.MergedTestChainConfig
+	cfg.VerkleTime = &verkleTime
+	parent := &gethtypes.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0)}
+	proverInput := &input.ProverInput{
+		Blocks: []*input.Block{{Header: &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), ParentHash: parent.Hash()}}},
+		Witness: &input.Witness{
+			Ancestors: []*gethtypes.Header{parent},
+		},
+		ChainConfig: &cfg,
+	}
+
+	_, err := NewExecutor().Execute(context.Background(), proverInput)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "verkle witness not yet supported for this fork")
+}
+
+func TestExecutorWithNodeWriteBatchSize(t *testing.T) {
+	proverInput := &loadTestDataInputs(t, testDataInputsPath("Ethereum_Mainnet_21465322.json")).ProverInput
+
+	res, err := NewExecutor(WithNodeWriteBatchSize(100)).Execute(context.Background(), proverInput)
+	require.NoError(t, err)
+	assert.Equal(t, proverInput.Blocks[0].Header.GasUsed, res.ProcessResult.GasUsed)
+}
+
+func TestVerifyPreStateRoot(t *testing.T) {
+	// A branch root referencing a child node by hash
+	child := []byte{0xc2, 0x31, 0x01} // leaf node completing the key with nibble 1
+	newRoot := func(childHash gethcommon.Hash) []byte {
+		children := make([]any, 17)
+		for i := range children {
+			children[i] = []byte{}
+		}
+		children[0], children[1] = childHash.Bytes(), childHash.Bytes()
+		blob, err := rlp.EncodeToBytes(children)
+		require.NoError(t, err)
+		return blob
+	}
+	rootBlob := newRoot(crypto.Keccak256Hash(child))
+	root := crypto.Keccak256Hash(rootBlob)
+
+	t.Run("valid", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ethereum.WriteNodesToHashDB(db, rootBlob, child)
+		assert.NoError(t, verifyPreStateRoot(db, root, trie.NodesByHash(rootBlob, child)))
+	})
+
+	t.Run("partial witness", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ethereum.WriteNodesToHashDB(db, rootBlob)
+		assert.NoError(t, verifyPreStateRoot(db, root, trie.NodesByHash(rootBlob)))
+	})
+
+	t.Run("missing root", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		ethereum.WriteNodesToHashDB(db, child)
+		err := verifyPreStateRoot(db, root, trie.NodesByHash(child))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-state root mismatch")
+	})
+
+	t.Run("wrong root node", func(t *testing.T) {
+		db := rawdb.NewMemoryDatabase()
+		rawdb.WriteLegacyTrieNode(db, root, newRoot(gethcommon.Hash{0x1}))
+		err := verifyPreStateRoot(db, root, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reconstructed root")
+	})
+
+	t.Run("undecodable inner node", func(t *testing.T) {
+		garbage := []byte{0xff, 0x01}
+		rootBlob := newRoot(crypto.Keccak256Hash(garbage))
+		db := rawdb.NewMemoryDatabase()
+		ethereum.WriteNodesToHashDB(db, rootBlob, garbage)
+		err := verifyPreStateRoot(db, crypto.Keccak256Hash(rootBlob), trie.NodesByHash(rootBlob, garbage))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid pre-state trie")
+	})
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+)
+
+// WitnessDiff is the difference between a supplied witness and the minimal witness re-derived from a full state
+// Missing entries are required by the execution but absent from the supplied witness, extra entries are supplied but not required.
+// State nodes and codes are identified by their hash.
+type WitnessDiff struct {
+	MissingState     []gethcommon.Hash `json:"missingState,omitempty"`
+	ExtraState       []gethcommon.Hash `json:"extraState,omitempty"`
+	MissingCodes     []gethcommon.Hash `json:"missingCodes,omitempty"`
+	ExtraCodes       []gethcommon.Hash `json:"extraCodes,omitempty"`
+	MissingAncestors []gethcommon.Hash `json:"missingAncestors,omitempty"`
+	ExtraAncestors   []gethcommon.Hash `json:"extraAncestors,omitempty"`
+}
+
+// Empty returns whether the supplied witness matches the re-derived witness
+func (d *WitnessDiff) Empty() bool {
+	return len(d.MissingState) == 0 && len(d.ExtraState) == 0 &&
+		len(d.MissingCodes) == 0 && len(d.ExtraCodes) == 0 &&
+		len(d.MissingAncestors) == 0 && len(d.ExtraAncestors) == 0
+}
+
+// DiffWitness compares the supplied witness against the derived one
+func DiffWitness(derived, supplied *input.Witness) (*WitnessDiff, error) {
+	derivedAncestors, err := derived.LoadAncestors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load derived ancestors: %v", err)
+	}
+	suppliedAncestors, err := supplied.LoadAncestors()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load supplied ancestors: %v", err)
+	}
+
+	derivedAncestorHashes := make([]gethcommon.Hash, 0, len(derivedAncestors))
+	for _, header := range derivedAncestors {
+		derivedAncestorHashes = append(derivedAncestorHashes, header.Hash())
+	}
+	suppliedAncestorHashes := make([]gethcommon.Hash, 0, len(suppliedAncestors))
+	for _, header := range suppliedAncestors {
+		suppliedAncestorHashes = append(suppliedAncestorHashes, header.Hash())
+	}
+
+	diff := new(WitnessDiff)
+	diff.MissingState, diff.ExtraState = diffHashes(blobHashes(derived.State), blobHashes(supplied.State))
+	diff.MissingCodes, diff.ExtraCodes = diffHashes(blobHashes(derived.Codes), blobHashes(supplied.Codes))
+	diff.MissingAncestors, diff.ExtraAncestors = diffHashes(derivedAncestorHashes, suppliedAncestorHashes)
+	return diff, nil
+}
+
+func blobHashes(blobs []hexutil.Bytes) []gethcommon.Hash {
+	hashes := make([]gethcommon.Hash, 0, len(blobs))
+	for _, blob := range blobs {
+		hashes = append(hashes, crypto.Keccak256Hash(blob))
+	}
+	return hashes
+}
+
+// diffHashes returns the sorted hashes of expected missing from actual, and of actual missing from expected
+func diffHashes(expected, actual []gethcommon.Hash) (missing, extra []gethcommon.Hash) {
+	expectedSet := make(map[gethcommon.Hash]struct{}, len(expected))
+	for _, hash := range expected {
+		expectedSet[hash] = struct{}{}
+	}
+	actualSet := make(map[gethcommon.Hash]struct{}, len(actual))
+	for _, hash := range actual {
+		actualSet[hash] = struct{}{}
+	}
+
+	for hash := range expectedSet {
+		if _, ok := actualSet[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+	for hash := range actualSet {
+		if _, ok := expectedSet[hash]; !ok {
+			extra = append(extra, hash)
+		}
+	}
+
+	sortHashes(missing)
+	sortHashes(extra)
+	return missing, extra
+}
+
+func sortHashes(hashes []gethcommon.Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i].Bytes(), hashes[j].Bytes()) < 0
+	})
+}
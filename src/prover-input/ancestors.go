@@ -0,0 +1,75 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// LoadAncestors loads the ancestors headers from a JSON file (as referenced by Witness.AncestorsFile)
+func LoadAncestors(path string) ([]*gethtypes.Header, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ancestors file: %v", err)
+	}
+
+	var ancestors []*gethtypes.Header
+	if err := json.Unmarshal(b, &ancestors); err != nil {
+		return nil, fmt.Errorf("failed to decode ancestors file %v: %v", path, err)
+	}
+
+	return ancestors, nil
+}
+
+// LoadAncestors returns the witness ancestors, loading them from the ancestors file if one is referenced
+func (w *Witness) LoadAncestors() ([]*gethtypes.Header, error) {
+	if w.AncestorsFile == "" {
+		return w.Ancestors, nil
+	}
+	return LoadAncestors(w.AncestorsPath())
+}
+
+// ResolveAncestorsFile records the directory of the prover input file the witness is read from, against which a relative ancestors file is resolved
+// It is to be called when reading a prover input file, so the ancestors file does not depend on the working directory.
+// AncestorsFile is left as read, so the input serialization (and its checksum) is unchanged.
+func (w *Witness) ResolveAncestorsFile(inputPath string) {
+	if w == nil {
+		return
+	}
+	w.ancestorsDir = filepath.Dir(inputPath)
+}
+
+// AncestorsPath returns the path of the ancestors file, resolved against the prover input directory when relative (see ResolveAncestorsFile)
+func (w *Witness) AncestorsPath() string {
+	if w.AncestorsFile == "" || w.ancestorsDir == "" || filepath.IsAbs(w.AncestorsFile) {
+		return w.AncestorsFile
+	}
+	return filepath.Join(w.ancestorsDir, w.AncestorsFile)
+}
+
+// SortAncestors returns the ancestors sorted by descending block number (i.e. from the block parent backwards)
+// It errors if the sorted ancestors do not form a contiguous chain.
+func SortAncestors(ancestors []*gethtypes.Header) ([]*gethtypes.Header, error) {
+	sorted := make([]*gethtypes.Header, len(ancestors))
+	copy(sorted, ancestors)
+	for _, header := range sorted {
+		if header == nil {
+			return nil, fmt.Errorf("nil ancestor")
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Number.Cmp(sorted[j].Number) > 0
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].ParentHash != sorted[i].Hash() {
+			return nil, fmt.Errorf("ancestors are not contiguous: block %v parent hash %v does not match block %v hash %v", sorted[i-1].Number, sorted[i-1].ParentHash.Hex(), sorted[i].Number, sorted[i].Hash().Hex())
+		}
+	}
+
+	return sorted, nil
+}
@@ -0,0 +1,138 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Builder constructs a prover input programmatically
+// Codes and state nodes are deduplicated by hash, consistency is validated when building the input.
+type Builder struct {
+	block       *gethtypes.Block
+	chainConfig *params.ChainConfig
+	ancestors   []*gethtypes.Header
+
+	codes      []hexutil.Bytes
+	nodes      []hexutil.Bytes
+	codeHashes map[gethcommon.Hash]struct{}
+	nodeHashes map[gethcommon.Hash]struct{}
+
+	errs []error
+}
+
+// NewBuilder creates a new prover input builder
+func NewBuilder() *Builder {
+	return &Builder{
+		codeHashes: make(map[gethcommon.Hash]struct{}),
+		nodeHashes: make(map[gethcommon.Hash]struct{}),
+	}
+}
+
+// WithBlock sets the block to execute
+func (b *Builder) WithBlock(block *gethtypes.Block) *Builder {
+	b.block = block
+	return b
+}
+
+// WithChainConfig sets the chain configuration
+func (b *Builder) WithChainConfig(cfg *params.ChainConfig) *Builder {
+	b.chainConfig = cfg
+	return b
+}
+
+// AddCode adds a contract bytecode to the witness
+func (b *Builder) AddCode(code []byte) *Builder {
+	if len(code) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("empty code"))
+		return b
+	}
+	if hash := crypto.Keccak256Hash(code); !addHash(b.codeHashes, hash) {
+		return b
+	}
+	b.codes = append(b.codes, gethcommon.CopyBytes(code))
+	return b
+}
+
+// AddStateNode adds an RLP encoded MPT node to the witness pre-state
+func (b *Builder) AddStateNode(node []byte) *Builder {
+	if len(node) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("empty state node"))
+		return b
+	}
+	if hash := crypto.Keccak256Hash(node); !addHash(b.nodeHashes, hash) {
+		return b
+	}
+	b.nodes = append(b.nodes, gethcommon.CopyBytes(node))
+	return b
+}
+
+// AddAncestor adds an ancestor header, ancestors must be added from the block parent backwards
+func (b *Builder) AddAncestor(header *gethtypes.Header) *Builder {
+	if header == nil {
+		b.errs = append(b.errs, fmt.Errorf("nil ancestor"))
+		return b
+	}
+	b.ancestors = append(b.ancestors, gethtypes.CopyHeader(header))
+	return b
+}
+
+// Build validates the added data and returns the prover input
+// It errors if the block or chain configuration is missing, if ancestors are not ordered from the block parent backwards
+// or if the block and the witness do not refer to the same chain (see AssertSameChain).
+func (b *Builder) Build() (*ProverInput, error) {
+	errs := append([]error{}, b.errs...)
+	if b.block == nil {
+		errs = append(errs, fmt.Errorf("missing block"))
+	}
+	if b.chainConfig == nil {
+		errs = append(errs, fmt.Errorf("missing chain configuration"))
+	}
+	if len(b.ancestors) == 0 {
+		errs = append(errs, fmt.Errorf("missing ancestors"))
+	}
+	for i := 1; i < len(b.ancestors); i++ {
+		if b.ancestors[i-1].ParentHash != b.ancestors[i].Hash() {
+			errs = append(errs, fmt.Errorf("ancestor %d (block %v) is not the parent of ancestor %d (block %v)", i, b.ancestors[i].Number, i-1, b.ancestors[i-1].Number))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	pi := &ProverInput{
+		Blocks: []*Block{
+			{
+				Header:       b.block.Header(),
+				Transactions: b.block.Transactions(),
+				Uncles:       b.block.Uncles(),
+				Withdrawals:  b.block.Withdrawals(),
+			},
+		},
+		Witness: &Witness{
+			State:     b.nodes,
+			Ancestors: b.ancestors,
+			Codes:     b.codes,
+		},
+		ChainConfig: b.chainConfig,
+	}
+	if err := AssertSameChain(pi); err != nil {
+		return nil, err
+	}
+
+	return pi, nil
+}
+
+// addHash adds the hash to the set and returns whether it was absent
+func addHash(set map[gethcommon.Hash]struct{}, hash gethcommon.Hash) bool {
+	if _, ok := set[hash]; ok {
+		return false
+	}
+	set[hash] = struct{}{}
+	return true
+}
@@ -0,0 +1,59 @@
+package input
+
+import (
+	"math/big"
+	"testing"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	grandParent := &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)}
+	parent := &gethtypes.Header{Number: big.NewInt(2), Difficulty: big.NewInt(0), ParentHash: grandParent.Hash()}
+	block := gethtypes.NewBlockWithHeader(&gethtypes.Header{Number: big.NewInt(3), Difficulty: big.NewInt(0), ParentHash: parent.Hash()})
+	cfg := &params.ChainConfig{ChainID: big.NewInt(1)}
+
+	t.Run("valid", func(t *testing.T) {
+		pi, err := NewBuilder().
+			WithBlock(block).
+			WithChainConfig(cfg).
+			AddCode([]byte{0x60, 0x00}).
+			AddCode([]byte{0x60, 0x00}).
+			AddStateNode([]byte{0xc0}).
+			AddAncestor(parent).
+			AddAncestor(grandParent).
+			Build()
+		require.NoError(t, err)
+
+		require.Len(t, pi.Blocks, 1)
+		assert.Equal(t, block.Hash(), pi.Blocks[0].Header.Hash())
+		assert.Equal(t, cfg, pi.ChainConfig)
+		assert.Len(t, pi.Witness.Codes, 1, "codes are deduplicated")
+		assert.Len(t, pi.Witness.State, 1)
+		require.Len(t, pi.Witness.Ancestors, 2)
+		assert.Equal(t, parent.Hash(), pi.Witness.Ancestors[0].Hash())
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		_, err := NewBuilder().AddCode(nil).Build()
+		require.Error(t, err)
+		for _, msg := range []string{"empty code", "missing block", "missing chain configuration", "missing ancestors"} {
+			assert.Contains(t, err.Error(), msg)
+		}
+	})
+
+	t.Run("unordered ancestors", func(t *testing.T) {
+		_, err := NewBuilder().WithBlock(block).WithChainConfig(cfg).AddAncestor(grandParent).AddAncestor(parent).Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ancestor 1 (block 2) is not the parent of ancestor 0 (block 1)")
+	})
+
+	t.Run("parent mismatch", func(t *testing.T) {
+		_, err := NewBuilder().WithBlock(block).WithChainConfig(cfg).AddAncestor(grandParent).Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match first ancestor hash")
+	})
+}
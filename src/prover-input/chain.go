@@ -0,0 +1,186 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+)
+
+// AssertContiguous asserts the given prover inputs cover contiguous blocks
+// i.e. each block number follows the previous one and each block parent hash matches the previous block hash
+// It returns an error reporting the first gap or hash mismatch found
+func AssertContiguous(inputs []*ProverInput) error {
+	var prev *Block
+	for i, pi := range inputs {
+		if pi == nil || len(pi.Blocks) == 0 {
+			return fmt.Errorf("input %d: no blocks provided", i)
+		}
+
+		for _, block := range pi.Blocks {
+			if prev != nil {
+				if expected := new(big.Int).Add(prev.Header.Number, big.NewInt(1)); block.Header.Number.Cmp(expected) != 0 {
+					return fmt.Errorf("input %d: gap after block %v (got block %v, expected %v)", i, prev.Header.Number, block.Header.Number, expected)
+				}
+				if block.Header.ParentHash != prev.Header.Hash() {
+					return fmt.Errorf("input %d: block %v parent hash %v does not match block %v hash %v", i, block.Header.Number, block.Header.ParentHash.Hex(), prev.Header.Number, prev.Header.Hash().Hex())
+				}
+			}
+			prev = block
+		}
+	}
+	return nil
+}
+
+// AssertSameChain asserts the block and the witness of the prover input refer to the same chain
+// i.e. the block parent hash is the hash of the first ancestor and the chain ID of the chain configuration matches the chain ID of the replay protected transactions
+// It returns an error combining every mismatch found
+func AssertSameChain(pi *ProverInput) error {
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil {
+		return fmt.Errorf("no blocks provided")
+	}
+	if pi.ChainConfig == nil || pi.ChainConfig.ChainID == nil {
+		return fmt.Errorf("missing chain configuration")
+	}
+	if pi.Witness == nil {
+		return fmt.Errorf("missing witness")
+	}
+	block := pi.Blocks[0]
+
+	var errs []error
+	ancestors, err := pi.Witness.LoadAncestors()
+	switch {
+	case err != nil:
+		errs = append(errs, fmt.Errorf("failed to load ancestors: %v", err))
+	case len(ancestors) == 0:
+		errs = append(errs, fmt.Errorf("no ancestors provided"))
+	case ancestors[0].Hash() != block.Header.ParentHash:
+		errs = append(errs, fmt.Errorf("block parent hash %v does not match first ancestor hash %v", block.Header.ParentHash.Hex(), ancestors[0].Hash().Hex()))
+	}
+
+	for i, tx := range block.Transactions {
+		if !tx.Protected() {
+			continue
+		}
+		if tx.ChainId().Cmp(pi.ChainConfig.ChainID) != 0 {
+			errs = append(errs, fmt.Errorf("transaction %d chain ID %v does not match chain configuration chain ID %v", i, tx.ChainId(), pi.ChainConfig.ChainID))
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// AssertCheckpoint asserts the prover input block is linked to the trusted checkpoint block hash (e.g. a finalized block)
+// i.e. the checkpoint is the block itself or one of its ancestors, following parent hashes through the witness ancestors.
+// As the witness only holds ancestors of the block, the checkpoint must not be more recent than the block.
+func AssertCheckpoint(pi *ProverInput, checkpoint gethcommon.Hash) error {
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil {
+		return fmt.Errorf("no blocks provided")
+	}
+	if pi.Witness == nil {
+		return fmt.Errorf("missing witness")
+	}
+	ancestors, err := pi.Witness.LoadAncestors()
+	if err != nil {
+		return fmt.Errorf("failed to load ancestors: %v", err)
+	}
+
+	current := pi.Blocks[0].Header
+	if current.Hash() == checkpoint {
+		return nil
+	}
+	for _, ancestor := range ancestors {
+		if ancestor == nil || ancestor.Hash() != current.ParentHash {
+			return fmt.Errorf("checkpoint %v not found: ancestor chain broken after block %v", checkpoint.Hex(), current.Number)
+		}
+		if ancestor.Hash() == checkpoint {
+			return nil
+		}
+		current = ancestor
+	}
+
+	return fmt.Errorf("checkpoint %v not found in the ancestor chain (down to block %v)", checkpoint.Hex(), current.Number)
+}
+
+// VerifyAncestorHashes verifies every ancestor header hashes to the hash claimed by its child
+// i.e. the first ancestor hashes to the block parent hash and each following ancestor to the parent hash of the previous one.
+// Ancestors must be ordered from the block parent backwards, the error identifies the first tampered ancestor.
+func VerifyAncestorHashes(header *gethtypes.Header, ancestors []*gethtypes.Header) error {
+	child := header
+	for i, ancestor := range ancestors {
+		if ancestor == nil {
+			return fmt.Errorf("ancestor %d is nil", i)
+		}
+		if hash := ancestor.Hash(); hash != child.ParentHash {
+			return fmt.Errorf("ancestor %d (block %v) hashes to %v but block %v claims parent hash %v", i, ancestor.Number, hash.Hex(), child.Number, child.ParentHash.Hex())
+		}
+		child = ancestor
+	}
+	return nil
+}
+
+// ValidateAncestors validates the witness ancestors of the prover input independently of the state witness
+// i.e. ancestors are ordered block numbers from the block parent backwards, each hashing to the parent hash of its child (see VerifyAncestorHashes),
+// and at least requiredDepth ancestors are provided (e.g. 256 for blocks using BLOCKHASH), down to the genesis block for early blocks.
+func ValidateAncestors(pi *ProverInput, requiredDepth int) error {
+	if requiredDepth < 0 {
+		return fmt.Errorf("invalid required ancestors depth %d: must not be negative", requiredDepth)
+	}
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil {
+		return fmt.Errorf("no blocks provided")
+	}
+	if pi.Witness == nil {
+		return fmt.Errorf("missing witness")
+	}
+	ancestors, err := pi.Witness.LoadAncestors()
+	if err != nil {
+		return fmt.Errorf("failed to load ancestors: %v", err)
+	}
+
+	header := pi.Blocks[0].Header
+	child := header
+	for i, ancestor := range ancestors {
+		if ancestor == nil {
+			return fmt.Errorf("ancestor %d is nil", i)
+		}
+		if expected := new(big.Int).Sub(child.Number, big.NewInt(1)); ancestor.Number.Cmp(expected) != 0 {
+			return fmt.Errorf("ancestor %d is block %v but block %v expects parent %v", i, ancestor.Number, child.Number, expected)
+		}
+		child = ancestor
+	}
+	if err := VerifyAncestorHashes(header, ancestors); err != nil {
+		return err
+	}
+
+	// Blocks have at most as many ancestors as their number
+	depth := uint64(requiredDepth)
+	if header.Number.IsUint64() && header.Number.Uint64() < depth {
+		depth = header.Number.Uint64()
+	}
+	if uint64(len(ancestors)) < depth {
+		return fmt.Errorf("insufficient ancestors depth: %d ancestors provided, %d required", len(ancestors), depth)
+	}
+
+	return nil
+}
+
+// ValidateWithdrawalsRoot validates the withdrawals root of the block header against the root of the trie of the block withdrawals
+// It allows checking the block withdrawals independently of the block execution.
+func ValidateWithdrawalsRoot(block *Block) error {
+	if block == nil || block.Header == nil {
+		return fmt.Errorf("no block provided")
+	}
+	if block.Header.WithdrawalsHash == nil {
+		return fmt.Errorf("missing withdrawals root")
+	}
+
+	// An empty list of withdrawals may have been decoded as nil, both hash to the empty withdrawals root
+	if hash := gethtypes.DeriveSha(gethtypes.Withdrawals(block.Withdrawals), gethtrie.NewStackTrie(nil)); hash != *block.Header.WithdrawalsHash {
+		return fmt.Errorf("withdrawals root mismatch (header %v, computed %v)", block.Header.WithdrawalsHash.Hex(), hash.Hex())
+	}
+	return nil
+}
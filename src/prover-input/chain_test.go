@@ -0,0 +1,208 @@
+package input
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChainInputs returns prover inputs for a chain of n blocks (one block per input) starting at block 1
+func newTestChainInputs(n int) []*ProverInput {
+	inputs := make([]*ProverInput, 0, n)
+	parentHash := gethcommon.Hash{}
+	for i := 1; i <= n; i++ {
+		header := &gethtypes.Header{
+			Number:     big.NewInt(int64(i)),
+			ParentHash: parentHash,
+			Difficulty: big.NewInt(0),
+		}
+		inputs = append(inputs, &ProverInput{Blocks: []*Block{{Header: header}}})
+		parentHash = header.Hash()
+	}
+	return inputs
+}
+
+func TestAssertContiguous(t *testing.T) {
+	t.Run("contiguous", func(t *testing.T) {
+		require.NoError(t, AssertContiguous(newTestChainInputs(5)))
+	})
+
+	t.Run("gap", func(t *testing.T) {
+		inputs := newTestChainInputs(5)
+		inputs = append(inputs[:2], inputs[3:]...)
+		err := AssertContiguous(inputs)
+		require.Error(t, err)
+		assert.Equal(t, "input 2: gap after block 2 (got block 4, expected 3)", err.Error())
+	})
+
+	t.Run("hash mismatch", func(t *testing.T) {
+		inputs := newTestChainInputs(5)
+		inputs[3].Blocks[0].Header.ParentHash = gethcommon.HexToHash("0x1")
+		err := AssertContiguous(inputs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "input 3: block 4 parent hash 0x0000000000000000000000000000000000000000000000000000000000000001 does not match block 3 hash")
+	})
+}
+
+func TestAssertSameChain(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	newInput := func(chainID int64) *ProverInput {
+		parent := &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), Extra: big.NewInt(chainID).Bytes()}
+		tx, err := gethtypes.SignNewTx(key, gethtypes.LatestSignerForChainID(big.NewInt(chainID)), &gethtypes.DynamicFeeTx{ChainID: big.NewInt(chainID), Gas: 21000})
+		require.NoError(t, err)
+		return &ProverInput{
+			ChainConfig: &params.ChainConfig{ChainID: big.NewInt(chainID)},
+			Blocks: []*Block{{
+				Header:       &gethtypes.Header{Number: big.NewInt(2), ParentHash: parent.Hash(), Difficulty: big.NewInt(0)},
+				Transactions: []*gethtypes.Transaction{tx},
+			}},
+			Witness: &Witness{Ancestors: []*gethtypes.Header{parent}},
+		}
+	}
+
+	require.NoError(t, AssertSameChain(newInput(1)))
+
+	// Block of chain 1 paired with the witness and chain configuration of chain 10
+	mixed := newInput(10)
+	mixed.Blocks = newInput(1).Blocks
+	err := AssertSameChain(mixed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not match first ancestor hash")
+	assert.Contains(t, err.Error(), "transaction 0 chain ID 1 does not match chain configuration chain ID 10")
+}
+
+func TestAssertCheckpoint(t *testing.T) {
+	headers := make([]*gethtypes.Header, 0, 4)
+	parentHash := gethcommon.Hash{}
+	for i := 1; i <= 4; i++ {
+		header := &gethtypes.Header{Number: big.NewInt(int64(i)), ParentHash: parentHash, Difficulty: big.NewInt(0)}
+		headers = append(headers, header)
+		parentHash = header.Hash()
+	}
+	pi := &ProverInput{
+		Blocks:  []*Block{{Header: headers[3]}},
+		Witness: &Witness{Ancestors: []*gethtypes.Header{headers[2], headers[1]}},
+	}
+
+	t.Run("linked", func(t *testing.T) {
+		for _, header := range headers[1:] {
+			assert.NoError(t, AssertCheckpoint(pi, header.Hash()), "block %v", header.Number)
+		}
+	})
+
+	t.Run("not linked", func(t *testing.T) {
+		// Checkpoint older than the witness ancestors
+		err := AssertCheckpoint(pi, headers[0].Hash())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found in the ancestor chain (down to block 2)")
+
+		// Checkpoint of another chain
+		err = AssertCheckpoint(pi, gethcommon.HexToHash("0x1"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("broken ancestor chain", func(t *testing.T) {
+		broken := &ProverInput{
+			Blocks:  pi.Blocks,
+			Witness: &Witness{Ancestors: []*gethtypes.Header{headers[1], headers[2]}},
+		}
+		err := AssertCheckpoint(broken, headers[2].Hash())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ancestor chain broken after block 4")
+	})
+}
+
+func TestVerifyAncestorHashes(t *testing.T) {
+	headers := make([]*gethtypes.Header, 0, 4)
+	parentHash := gethcommon.Hash{}
+	for i := 1; i <= 4; i++ {
+		header := &gethtypes.Header{Number: big.NewInt(int64(i)), ParentHash: parentHash, Difficulty: big.NewInt(0)}
+		headers = append(headers, header)
+		parentHash = header.Hash()
+	}
+	ancestors := []*gethtypes.Header{headers[2], headers[1], headers[0]}
+
+	require.NoError(t, VerifyAncestorHashes(headers[3], ancestors))
+
+	// Tampering with an ancestor field changes its hash
+	tampered := *headers[1]
+	tampered.GasLimit = 1
+	ancestors[1] = &tampered
+
+	err := VerifyAncestorHashes(headers[3], ancestors)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("ancestor 1 (block 2) hashes to %v but block 3 claims parent hash %v", tampered.Hash().Hex(), headers[1].Hash().Hex()))
+}
+
+func TestValidateAncestors(t *testing.T) {
+	// Chain from genesis to block 5, with ancestors ordered from block 4 down to genesis
+	ancestors := make([]*gethtypes.Header, 0, 5)
+	parentHash := gethcommon.Hash{}
+	for i := 0; i <= 4; i++ {
+		header := &gethtypes.Header{Number: big.NewInt(int64(i)), ParentHash: parentHash, Difficulty: big.NewInt(0)}
+		ancestors = append([]*gethtypes.Header{header}, ancestors...)
+		parentHash = header.Hash()
+	}
+	header := &gethtypes.Header{Number: big.NewInt(5), ParentHash: parentHash, Difficulty: big.NewInt(0)}
+	pi := &ProverInput{Blocks: []*Block{{Header: header}}, Witness: &Witness{Ancestors: ancestors}}
+
+	require.NoError(t, ValidateAncestors(pi, 5))
+	// Block 5 has only 5 ancestors, deeper requirements are capped by the block number
+	require.NoError(t, ValidateAncestors(pi, 256))
+
+	pi.Witness.Ancestors = ancestors[:2]
+	require.NoError(t, ValidateAncestors(pi, 2))
+	err := ValidateAncestors(pi, 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "insufficient ancestors depth: 2 ancestors provided, 3 required")
+
+	err = ValidateAncestors(pi, -1)
+	require.Error(t, err)
+	assert.Equal(t, "invalid required ancestors depth -1: must not be negative", err.Error())
+
+	// Ancestors must be contiguous
+	pi.Witness.Ancestors = []*gethtypes.Header{ancestors[0], ancestors[2]}
+	err = ValidateAncestors(pi, 2)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ancestor 1 is block 2 but block 4 expects parent 3")
+}
+
+func TestValidateWithdrawalsRoot(t *testing.T) {
+	withdrawals := []*gethtypes.Withdrawal{
+		{Index: 0, Validator: 1, Address: gethcommon.HexToAddress("0x1"), Amount: 100},
+		{Index: 1, Validator: 2, Address: gethcommon.HexToAddress("0x2"), Amount: 200},
+	}
+	root := gethtypes.DeriveSha(gethtypes.Withdrawals(withdrawals), gethtrie.NewStackTrie(nil))
+	block := &Block{
+		Header:      &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0), WithdrawalsHash: &root},
+		Withdrawals: withdrawals,
+	}
+	require.NoError(t, ValidateWithdrawalsRoot(block))
+
+	// Tampered withdrawal amount
+	tampered := *withdrawals[1]
+	tampered.Amount = 300
+	block.Withdrawals = []*gethtypes.Withdrawal{withdrawals[0], &tampered}
+	err := ValidateWithdrawalsRoot(block)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("withdrawals root mismatch (header %v", root.Hex()))
+
+	// Missing withdrawal
+	block.Withdrawals = withdrawals[:1]
+	require.Error(t, ValidateWithdrawalsRoot(block))
+
+	// Pre-Shanghai headers have no withdrawals root
+	block.Header.WithdrawalsHash = nil
+	err = ValidateWithdrawalsRoot(block)
+	require.Error(t, err)
+	assert.Equal(t, "missing withdrawals root", err.Error())
+}
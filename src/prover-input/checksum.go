@@ -0,0 +1,99 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Checksum computes the checksum of the prover input
+// It is the keccak256 hash of the canonical JSON serialization of the input, excluding the embedded checksum
+// When the ancestors are held in an ancestors file, the loaded ancestors are serialized along with the file reference,
+// so corruption of the ancestors file is detected too (the ancestors file must be readable, see Witness.ResolveAncestorsFile).
+func Checksum(pi *ProverInput) (gethcommon.Hash, error) {
+	canonical := *pi
+	canonical.Checksum = nil
+	if pi.Witness != nil && pi.Witness.AncestorsFile != "" {
+		ancestors, err := pi.Witness.LoadAncestors()
+		if err != nil {
+			return gethcommon.Hash{}, err
+		}
+		witness := *pi.Witness
+		witness.Ancestors = ancestors
+		canonical.Witness = &witness
+	}
+
+	b, err := json.Marshal(&canonical)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("failed to serialize prover input: %v", err)
+	}
+
+	return crypto.Keccak256Hash(b), nil
+}
+
+// WithChecksum returns a copy of the prover input with its checksum embedded
+func WithChecksum(pi *ProverInput) (*ProverInput, error) {
+	checksum, err := Checksum(pi)
+	if err != nil {
+		return nil, err
+	}
+
+	withChecksum := *pi
+	withChecksum.Checksum = &checksum
+	return &withChecksum, nil
+}
+
+// VerifyChecksum recomputes the checksum of the prover input and compares it with the embedded checksum
+func VerifyChecksum(pi *ProverInput) error {
+	if pi.Checksum == nil {
+		return fmt.Errorf("prover input has no checksum")
+	}
+
+	checksum, err := Checksum(pi)
+	if err != nil {
+		return err
+	}
+
+	if checksum != *pi.Checksum {
+		return fmt.Errorf("checksum mismatch (expected %v, computed %v)", pi.Checksum.Hex(), checksum.Hex())
+	}
+
+	return nil
+}
+
+// WitnessHash computes the hash of the prover input witness
+// It is the keccak256 hash of the canonical JSON serialization of the witness, with ancestors loaded in place of any ancestors file.
+func WitnessHash(w *Witness) (gethcommon.Hash, error) {
+	if w == nil {
+		return gethcommon.Hash{}, fmt.Errorf("prover input has no witness")
+	}
+
+	ancestors, err := w.LoadAncestors()
+	if err != nil {
+		return gethcommon.Hash{}, err
+	}
+	canonical := Witness{State: w.State, Ancestors: ancestors, Codes: w.Codes}
+
+	b, err := json.Marshal(&canonical)
+	if err != nil {
+		return gethcommon.Hash{}, fmt.Errorf("failed to serialize witness: %v", err)
+	}
+
+	return crypto.Keccak256Hash(b), nil
+}
+
+// VerifyWitnessHash recomputes the hash of the witness and compares it with an expected witness hash (e.g. recorded at generation)
+func VerifyWitnessHash(w *Witness, expected gethcommon.Hash) error {
+	hash, err := WitnessHash(w)
+	if err != nil {
+		return err
+	}
+
+	if hash != expected {
+		return fmt.Errorf("witness hash mismatch (expected %v, computed %v)", expected.Hex(), hash.Hex())
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	pi := &ProverInput{
+		Version: "v0.1.0",
+		Blocks: []*Block{
+			{
+				Header: &gethtypes.Header{
+					Number:     big.NewInt(1),
+					Difficulty: big.NewInt(0),
+					BaseFee:    big.NewInt(params.InitialBaseFee),
+				},
+			},
+		},
+		Witness: newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+			gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+		}),
+		ChainConfig: params.MainnetChainConfig,
+	}
+	pi.Witness.Ancestors[0].Number = big.NewInt(0)
+	pi.Witness.Ancestors[0].Difficulty = big.NewInt(0)
+
+	withChecksum, err := WithChecksum(pi)
+	require.NoError(t, err)
+	assert.Nil(t, pi.Checksum, "Original input should not be modified")
+
+	b, err := json.Marshal(withChecksum)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		var decoded ProverInput
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		assert.NoError(t, VerifyChecksum(&decoded))
+	})
+
+	t.Run("flipped byte", func(t *testing.T) {
+		// Flip a byte in the first witness state node
+		corrupted := bytes.Clone(b)
+		i := bytes.Index(corrupted, []byte(`"state":["0x`)) + len(`"state":["0x`)
+		if corrupted[i] == 'f' {
+			corrupted[i] = 'e'
+		} else {
+			corrupted[i] = 'f'
+		}
+
+		var decoded ProverInput
+		require.NoError(t, json.Unmarshal(corrupted, &decoded))
+		err := VerifyChecksum(&decoded)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("missing checksum", func(t *testing.T) {
+		assert.Error(t, VerifyChecksum(pi))
+	})
+}
+
+func TestChecksumAncestorsFile(t *testing.T) {
+	witness := newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+		gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+	})
+	witness.Ancestors[0].Number = big.NewInt(0)
+	witness.Ancestors[0].Difficulty = big.NewInt(0)
+
+	// Write the input in a subdirectory, referencing its ancestors file relatively
+	dir := filepath.Join(t.TempDir(), "data")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	b, err := json.Marshal(witness.Ancestors)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "anc.json"), b, 0o600))
+	witness.Ancestors, witness.AncestorsFile = nil, "anc.json"
+
+	inputPath := filepath.Join(dir, "input.json")
+	witness.ResolveAncestorsFile(inputPath)
+	withChecksum, err := WithChecksum(&ProverInput{Version: "v0.1.0", Witness: witness, ChainConfig: params.MainnetChainConfig})
+	require.NoError(t, err)
+	b, err = json.Marshal(withChecksum)
+	require.NoError(t, err)
+
+	var decoded ProverInput
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	decoded.Witness.ResolveAncestorsFile(inputPath)
+	assert.NoError(t, VerifyChecksum(&decoded))
+
+	ancestors, err := decoded.Witness.LoadAncestors()
+	require.NoError(t, err)
+	assert.Len(t, ancestors, 1)
+
+	// Corruption of the ancestors file is detected
+	ancestors[0].GasUsed++
+	b, err = json.Marshal(ancestors)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "anc.json"), b, 0o600))
+	err = VerifyChecksum(&decoded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	// A missing ancestors file fails verification
+	require.NoError(t, os.Remove(filepath.Join(dir, "anc.json")))
+	assert.Error(t, VerifyChecksum(&decoded))
+}
+
+func TestWitnessHash(t *testing.T) {
+	witness := newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+		gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+	})
+	witness.Ancestors[0].Number = big.NewInt(0)
+	witness.Ancestors[0].Difficulty = big.NewInt(0)
+	hash, err := WitnessHash(witness)
+	require.NoError(t, err)
+
+	b, err := json.Marshal(witness)
+	require.NoError(t, err)
+	var decoded Witness
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.NoError(t, VerifyWitnessHash(&decoded, hash))
+
+	decoded.State[0] = bytes.Clone(decoded.State[0])
+	decoded.State[0][len(decoded.State[0])-1] ^= 0xff
+	err = VerifyWitnessHash(&decoded, hash)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "witness hash mismatch")
+
+	_, err = WitnessHash(nil)
+	assert.Error(t, err)
+}
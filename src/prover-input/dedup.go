@@ -0,0 +1,84 @@
+package input
+
+import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DedupStats reports the witness data shared across prover inputs, which is deduplicated when merging them into a batch
+type DedupStats struct {
+	Inputs int `json:"inputs"` // Number of inputs
+
+	Nodes       DedupCount `json:"nodes"`       // Witness state nodes
+	Codes       DedupCount `json:"codes"`       // Witness codes
+	Ancestors   DedupCount `json:"ancestors"`   // Witness ancestors
+	SavedBytes  int        `json:"savedBytes"`  // Size in bytes of the duplicated state nodes and codes
+	MergedBytes int        `json:"mergedBytes"` // Size in bytes of the deduplicated state nodes and codes
+}
+
+// DedupCount counts witness items across prover inputs
+type DedupCount struct {
+	Total  int `json:"total"`  // Items across every input
+	Unique int `json:"unique"` // Distinct items, i.e. items of the merged batch
+	Shared int `json:"shared"` // Distinct items present in more than one input
+}
+
+// ComputeDedupStats returns the witness data shared across the given prover inputs, items are identified by hash
+// Ancestors are expected to be loaded (see Witness.LoadAncestors), inputs without witness are counted without items.
+func ComputeDedupStats(inputs ...*ProverInput) *DedupStats {
+	stats := &DedupStats{Inputs: len(inputs)}
+
+	nodes := make(map[gethcommon.Hash]int)
+	codes := make(map[gethcommon.Hash]int)
+	ancestors := make(map[gethcommon.Hash]int)
+	for _, pi := range inputs {
+		if pi == nil || pi.Witness == nil {
+			continue
+		}
+		countBlobs(&stats.Nodes, nodes, pi.Witness.State, stats)
+		countBlobs(&stats.Codes, codes, pi.Witness.Codes, stats)
+
+		seen := make(map[gethcommon.Hash]struct{}, len(pi.Witness.Ancestors))
+		for _, header := range pi.Witness.Ancestors {
+			hash := header.Hash()
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			stats.Ancestors.count(ancestors, hash)
+		}
+	}
+	return stats
+}
+
+// countBlobs counts the distinct blobs of an input
+func countBlobs(count *DedupCount, occurrences map[gethcommon.Hash]int, blobs []hexutil.Bytes, stats *DedupStats) {
+	seen := make(map[gethcommon.Hash]struct{}, len(blobs))
+	for _, blob := range blobs {
+		hash := crypto.Keccak256Hash(blob)
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		if count.count(occurrences, hash) {
+			stats.MergedBytes += len(blob)
+		} else {
+			stats.SavedBytes += len(blob)
+		}
+	}
+}
+
+// count counts an item occurrence and returns whether it is the first occurrence of the item
+func (c *DedupCount) count(occurrences map[gethcommon.Hash]int, hash gethcommon.Hash) bool {
+	c.Total++
+	occurrences[hash]++
+	switch occurrences[hash] {
+	case 1:
+		c.Unique++
+		return true
+	case 2:
+		c.Shared++
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package input
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDedupStats(t *testing.T) {
+	parent := &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)}
+	grandParent := &gethtypes.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0)}
+	a := &ProverInput{Witness: &Witness{
+		State:     []hexutil.Bytes{{0x01}, {0x02}, {0x03}},
+		Codes:     []hexutil.Bytes{{0x60, 0x00}},
+		Ancestors: []*gethtypes.Header{parent, grandParent},
+	}}
+	b := &ProverInput{Witness: &Witness{
+		State:     []hexutil.Bytes{{0x02}, {0x03}, {0x04}, {0x04}},
+		Codes:     []hexutil.Bytes{{0x60, 0x01}},
+		Ancestors: []*gethtypes.Header{grandParent},
+	}}
+
+	stats := ComputeDedupStats(a, b)
+	assert.Equal(t, 2, stats.Inputs)
+	// Duplicates within an input are not counted
+	assert.Equal(t, DedupCount{Total: 6, Unique: 4, Shared: 2}, stats.Nodes)
+	assert.Equal(t, DedupCount{Total: 2, Unique: 2, Shared: 0}, stats.Codes)
+	assert.Equal(t, DedupCount{Total: 3, Unique: 2, Shared: 1}, stats.Ancestors)
+	assert.Equal(t, 2, stats.SavedBytes)
+	assert.Equal(t, 8, stats.MergedBytes)
+}
@@ -0,0 +1,54 @@
+package input
+
+import "fmt"
+
+// ActiveFork returns the name of the fork governing the first block of the prover input (e.g. "cancun"), as derived from the chain configuration and the block number and timestamp
+// It errors if the chain configuration or the block is missing, or if the chain configuration is ambiguous (e.g. forks scheduled out of order)
+func ActiveFork(pi *ProverInput) (string, error) {
+	if pi.ChainConfig == nil {
+		return "", fmt.Errorf("missing chain configuration")
+	}
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil || pi.Blocks[0].Header.Number == nil {
+		return "", fmt.Errorf("no blocks provided")
+	}
+	if err := pi.ChainConfig.CheckConfigForkOrder(); err != nil {
+		return "", fmt.Errorf("ambiguous chain configuration: %v", err)
+	}
+
+	header := pi.Blocks[0].Header
+	isMerge := header.Difficulty != nil && header.Difficulty.Sign() == 0 && pi.ChainConfig.TerminalTotalDifficulty != nil
+	rules := pi.ChainConfig.Rules(header.Number, isMerge, header.Time)
+
+	switch {
+	case rules.IsVerkle:
+		return "verkle", nil
+	case rules.IsPrague:
+		return "prague", nil
+	case rules.IsCancun:
+		return "cancun", nil
+	case rules.IsShanghai:
+		return "shanghai", nil
+	case rules.IsMerge:
+		return "paris", nil
+	case rules.IsLondon:
+		return "london", nil
+	case rules.IsBerlin:
+		return "berlin", nil
+	case rules.IsIstanbul:
+		return "istanbul", nil
+	case rules.IsPetersburg:
+		return "petersburg", nil
+	case rules.IsConstantinople:
+		return "constantinople", nil
+	case rules.IsByzantium:
+		return "byzantium", nil
+	case rules.IsEIP158:
+		return "spuriousDragon", nil
+	case rules.IsEIP150:
+		return "tangerineWhistle", nil
+	case rules.IsHomestead:
+		return "homestead", nil
+	default:
+		return "frontier", nil
+	}
+}
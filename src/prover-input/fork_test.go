@@ -0,0 +1,58 @@
+package input
+
+import (
+	"math/big"
+	"testing"
+
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveFork(t *testing.T) {
+	newInput := func(cfg *params.ChainConfig, number int64, time uint64, difficulty int64) *ProverInput {
+		return &ProverInput{
+			ChainConfig: cfg,
+			Blocks: []*Block{{Header: &gethtypes.Header{
+				Number:     big.NewInt(number),
+				Time:       time,
+				Difficulty: big.NewInt(difficulty),
+			}}},
+		}
+	}
+
+	tests := []struct {
+		desc  string
+		input *ProverInput
+		fork  string
+	}{
+		{"cancun", newInput(params.MainnetChainConfig, 21465322, 1734523800, 0), "cancun"},
+		{"shanghai", newInput(params.MainnetChainConfig, 17034870, 1681338455, 0), "shanghai"},
+		{"paris", newInput(params.MainnetChainConfig, 15537394, 1663224179, 0), "paris"},
+		{"london", newInput(params.MainnetChainConfig, 12965000, 1628166822, 1), "london"},
+		{"spurious dragon", newInput(params.MainnetChainConfig, 2675000, 1479884854, 1), "spuriousDragon"},
+		{"frontier", newInput(params.MainnetChainConfig, 1, 1438269988, 1), "frontier"},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			fork, err := ActiveFork(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.fork, fork)
+		})
+	}
+
+	t.Run("missing chain configuration", func(t *testing.T) {
+		_, err := ActiveFork(newInput(nil, 1, 0, 0))
+		require.Error(t, err)
+	})
+
+	t.Run("ambiguous chain configuration", func(t *testing.T) {
+		// London scheduled before Berlin
+		cfg := *params.MainnetChainConfig
+		cfg.LondonBlock = big.NewInt(1)
+		_, err := ActiveFork(newInput(&cfg, 21465322, 1734523800, 0))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ambiguous chain configuration")
+	})
+}
@@ -1,6 +1,7 @@
 package input
 
 import (
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -13,12 +14,21 @@ type ProverInput struct {
 	Blocks      []*Block            `json:"blocks"`      // Block to execute
 	Witness     *Witness            `json:"witness"`     // Ancestors of the block that are accessed during the block execution
 	ChainConfig *params.ChainConfig `json:"chainConfig"` // Chain configuration
+
+	Checksum *gethcommon.Hash `json:"checksum,omitempty"` // Checksum of the input (optional, see Checksum)
 }
 
 type Witness struct {
 	State     []hexutil.Bytes     `json:"state"`     // Partial pre-state, consisting in a list of MPT nodes
 	Ancestors []*gethtypes.Header `json:"ancestors"` // Ancestors of the block that are accessed during the block execution
 	Codes     []hexutil.Bytes     `json:"codes"`     // Contract bytecodes used during the block execution
+
+	// AncestorsFile is the path to a JSON file holding the ancestors, loaded in place of Ancestors when set
+	// A relative path is relative to the directory of the prover input file (see Witness.ResolveAncestorsFile).
+	// It allows sharing ancestors across inputs to keep them small (it is not supported by the protobuf encoding)
+	AncestorsFile string `json:"ancestorsFile,omitempty"`
+
+	ancestorsDir string // Directory of the prover input file, a relative AncestorsFile is resolved against (not serialized)
 }
 
 type Block struct {
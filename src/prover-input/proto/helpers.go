@@ -15,6 +15,13 @@ func bytesToHashPtr(b []byte) *gethcommon.Hash {
 	return &hash
 }
 
+func hashPtrToBytes(hash *gethcommon.Hash) []byte {
+	if hash == nil {
+		return nil
+	}
+	return hash.Bytes()
+}
+
 func bytesToBigInt(b []byte) *big.Int {
 	if b == nil {
 		return nil
@@ -3,6 +3,7 @@ package proto
 import (
 	"testing"
 
+	gethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	gethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
@@ -30,6 +31,7 @@ func TestInput(t *testing.T) {
 				Blocks:      []*input.Block{},
 				Witness:     &input.Witness{},
 				ChainConfig: &params.ChainConfig{},
+				Checksum:    &gethcommon.Hash{0x1},
 			},
 		},
 	}
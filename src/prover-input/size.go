@@ -0,0 +1,49 @@
+package input
+
+import (
+	"encoding/json"
+)
+
+// SizeReport is the breakdown of the JSON serialized size in bytes of a prover input by component
+type SizeReport struct {
+	Total int `json:"total"` // Serialized size of the whole input
+
+	Blocks     int `json:"blocks"`     // Blocks (headers, transactions, uncles and withdrawals)
+	State      int `json:"state"`      // Witness state nodes
+	StateNodes int `json:"stateNodes"` // Number of witness state nodes
+	Codes      int `json:"codes"`      // Witness codes
+	CodesCount int `json:"codesCount"` // Number of witness codes
+	Ancestors  int `json:"ancestors"`  // Witness ancestors (or ancestors file reference)
+
+	Other int `json:"other"` // Remaining bytes (version, chain config, checksum and JSON framing), so components sum to the total
+}
+
+// SizeBreakdown returns the JSON serialized size of the prover input and of each of its components
+func SizeBreakdown(pi *ProverInput) SizeReport {
+	report := SizeReport{
+		Total:  jsonSize(pi),
+		Blocks: jsonSize(pi.Blocks),
+	}
+	if w := pi.Witness; w != nil {
+		report.State = jsonSize(w.State)
+		report.StateNodes = len(w.State)
+		report.Codes = jsonSize(w.Codes)
+		report.CodesCount = len(w.Codes)
+		report.Ancestors = jsonSize(w.Ancestors)
+		if w.AncestorsFile != "" {
+			report.Ancestors += jsonSize(w.AncestorsFile)
+		}
+	}
+	report.Other = report.Total - report.Blocks - report.State - report.Codes - report.Ancestors
+	return report
+}
+
+// jsonSize returns the size of the JSON serialization of v
+// Prover input components always serialize, so errors are not expected (size is then zero).
+func jsonSize(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,55 @@
+package input
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeBreakdown(t *testing.T) {
+	pi := &ProverInput{
+		Version: "v0.1.0",
+		Blocks: []*Block{
+			{
+				Header: &gethtypes.Header{
+					Number:     big.NewInt(1),
+					Difficulty: big.NewInt(0),
+					BaseFee:    big.NewInt(params.InitialBaseFee),
+				},
+			},
+		},
+		Witness: newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+			gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+			gethcommon.HexToAddress("0x2"): newTestAccount(2, 200),
+		}),
+		ChainConfig: params.MainnetChainConfig,
+	}
+	pi.Witness.Codes = []hexutil.Bytes{{0x60, 0x00}, {0x60, 0x01, 0x00}}
+
+	b, err := json.Marshal(pi)
+	require.NoError(t, err)
+
+	report := SizeBreakdown(pi)
+	assert.Equal(t, len(b), report.Total)
+	assert.Equal(t, report.Total, report.Blocks+report.State+report.Codes+report.Ancestors+report.Other)
+
+	assert.Equal(t, len(pi.Witness.State), report.StateNodes)
+	assert.Equal(t, 2, report.CodesCount)
+	assert.Equal(t, len(`["0x6000","0x600100"]`), report.Codes)
+	assert.Positive(t, report.Blocks)
+	assert.Positive(t, report.State)
+	assert.Positive(t, report.Ancestors)
+
+	// Remaining bytes are mostly the chain configuration
+	chainConfig, err := json.Marshal(pi.ChainConfig)
+	require.NoError(t, err)
+	assert.Greater(t, report.Other, len(chainConfig))
+	assert.Less(t, report.Other, len(chainConfig)+128)
+}
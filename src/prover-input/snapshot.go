@@ -0,0 +1,119 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+)
+
+// Geth database dump format (as written by `geth db export` and read by `geth db import`)
+const (
+	snapshotDumpMagic = "gethdbdump"
+	snapshotDumpKind  = "snapshot"
+
+	snapshotOpAdd = 0
+	snapshotOpDel = 1
+)
+
+type snapshotDumpHeader struct {
+	Magic    string
+	Version  uint64
+	Kind     string
+	UnixTime uint64
+}
+
+// SnapshotStats counts the entries exported to a snapshot
+type SnapshotStats struct {
+	Accounts int
+	Slots    int
+}
+
+// WriteSnapshot writes the pre-state covered by the witness as a Geth snapshot dump, importable into a node database with `geth db import`
+// The dump holds the flat snapshot entries (slim accounts and storage slots keyed by hash) in the format of `geth db export snapshot`.
+//
+// Only the covered portion of the state is exported: accounts in sub-tries absent from the witness are skipped, as well as the storage
+// of accounts whose storage trie is not covered. Slots of partially covered storage tries are exported as far as the witness covers them.
+func WriteSnapshot(w io.Writer, pi *ProverInput) (*SnapshotStats, error) {
+	root, err := PreStateRoot(pi)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rlp.Encode(w, &snapshotDumpHeader{Magic: snapshotDumpMagic, Kind: snapshotDumpKind, UnixTime: uint64(time.Now().Unix())}); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot header: %v", err)
+	}
+	// As Geth does, the snapshot root marker is deleted so the node verifies the imported snapshot against its state
+	if err := writeSnapshotEntry(w, snapshotOpDel, rawdb.SnapshotRootKey, nil); err != nil {
+		return nil, err
+	}
+
+	var (
+		stats    = new(SnapshotStats)
+		nodes    = trie.NodesByHash(pi.Witness.State...)
+		writeErr error
+	)
+	type storageTrie struct {
+		accountHash gethcommon.Hash
+		root        gethcommon.Hash
+	}
+	var storageTries []storageTrie
+
+	err = trie.Walk(root, nodes, &trie.Visitor{
+		OnLeaf: func(key, value []byte) {
+			if writeErr != nil {
+				return
+			}
+			var account gethtypes.StateAccount
+			if err := rlp.DecodeBytes(value, &account); err != nil {
+				writeErr = fmt.Errorf("failed to decode account %x: %v", key, err)
+				return
+			}
+			accountHash := gethcommon.BytesToHash(key)
+			writeErr = writeSnapshotEntry(w, snapshotOpAdd, append(append([]byte{}, rawdb.SnapshotAccountPrefix...), accountHash.Bytes()...), gethtypes.SlimAccountRLP(account))
+			stats.Accounts++
+			storageTries = append(storageTries, storageTrie{accountHash: accountHash, root: account.Root})
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk account trie: %v", err)
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	for _, st := range storageTries {
+		err := trie.Walk(st.root, nodes, &trie.Visitor{
+			OnLeaf: func(key, value []byte) {
+				if writeErr != nil {
+					return
+				}
+				storageKey := append(append(append([]byte{}, rawdb.SnapshotStoragePrefix...), st.accountHash.Bytes()...), gethcommon.BytesToHash(key).Bytes()...)
+				writeErr = writeSnapshotEntry(w, snapshotOpAdd, storageKey, value)
+				stats.Slots++
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk storage trie %v: %v", st.root.Hex(), err)
+		}
+		if writeErr != nil {
+			return nil, writeErr
+		}
+	}
+
+	return stats, nil
+}
+
+func writeSnapshotEntry(w io.Writer, op byte, key, value []byte) error {
+	for _, v := range []interface{}{op, key, value} {
+		if err := rlp.Encode(w, v); err != nil {
+			return fmt.Errorf("failed to write snapshot entry %x: %v", key, err)
+		}
+	}
+	return nil
+}
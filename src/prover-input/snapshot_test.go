@@ -0,0 +1,78 @@
+package input
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importSnapshot imports a snapshot dump into a memory database as `geth db import` does
+func importSnapshot(t *testing.T, r io.Reader) ethdb.Database {
+	stream := rlp.NewStream(r, 0)
+	var header snapshotDumpHeader
+	require.NoError(t, stream.Decode(&header))
+	assert.Equal(t, snapshotDumpMagic, header.Magic)
+	assert.Equal(t, snapshotDumpKind, header.Kind)
+
+	db := rawdb.NewMemoryDatabase()
+	for {
+		var (
+			op         byte
+			key, value []byte
+		)
+		if err := stream.Decode(&op); err == io.EOF {
+			break
+		} else {
+			require.NoError(t, err)
+		}
+		require.NoError(t, stream.Decode(&key))
+		require.NoError(t, stream.Decode(&value))
+		switch op {
+		case snapshotOpAdd:
+			require.NoError(t, db.Put(key, value))
+		case snapshotOpDel:
+			require.NoError(t, db.Delete(key))
+		}
+	}
+	return db
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	accounts := map[gethcommon.Address]*gethtypes.StateAccount{
+		gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+		gethcommon.HexToAddress("0x2"): newTestAccount(2, 200),
+		gethcommon.HexToAddress("0x3"): newTestAccount(3, 300),
+	}
+	witness := newTestWitness(t, accounts)
+
+	// Drop the leaf of one account, so it is not covered by the witness
+	uncovered := gethcommon.HexToAddress("0x3")
+	uncoveredBlob, err := rlp.EncodeToBytes(accounts[uncovered])
+	require.NoError(t, err)
+	for i, node := range witness.State {
+		if bytes.Contains(node, uncoveredBlob) {
+			witness.State = append(witness.State[:i], witness.State[i+1:]...)
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	stats, err := WriteSnapshot(&buf, &ProverInput{Witness: witness})
+	require.NoError(t, err)
+	assert.Equal(t, &SnapshotStats{Accounts: 2}, stats)
+
+	db := importSnapshot(t, &buf)
+	for _, addr := range []gethcommon.Address{gethcommon.HexToAddress("0x1"), gethcommon.HexToAddress("0x2")} {
+		assert.Equal(t, gethtypes.SlimAccountRLP(*accounts[addr]), rawdb.ReadAccountSnapshot(db, crypto.Keccak256Hash(addr.Bytes())), "account %v", addr.Hex())
+	}
+	assert.Empty(t, rawdb.ReadAccountSnapshot(db, crypto.Keccak256Hash(uncovered.Bytes())))
+}
@@ -0,0 +1,69 @@
+package input
+
+import (
+	"fmt"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+)
+
+// SystemContract is a contract called by the protocol during block processing, whose state must be covered by the witness
+type SystemContract struct {
+	Name    string
+	EIP     int
+	Address gethcommon.Address
+}
+
+// RequiredSystemContracts returns the system contracts called when processing a block with the given header, as activated by its fork
+// Deposit requests (EIP-6110) are parsed from the deposit contract logs, so the deposit contract state is not required.
+func RequiredSystemContracts(cfg *params.ChainConfig, header *gethtypes.Header) []SystemContract {
+	var contracts []SystemContract
+	if cfg.IsCancun(header.Number, header.Time) {
+		contracts = append(contracts, SystemContract{Name: "beacon roots", EIP: 4788, Address: params.BeaconRootsAddress})
+	}
+	if cfg.IsPrague(header.Number, header.Time) {
+		contracts = append(contracts,
+			SystemContract{Name: "history storage", EIP: 2935, Address: params.HistoryStorageAddress},
+			SystemContract{Name: "withdrawal queue", EIP: 7002, Address: params.WithdrawalQueueAddress},
+			SystemContract{Name: "consolidation queue", EIP: 7251, Address: params.ConsolidationQueueAddress},
+		)
+	}
+	return contracts
+}
+
+// ValidateSystemContracts verifies the witness covers the system contracts required by the fork of the first block of the prover input
+// Each contract account must be provable from the pre-state root and, if it has storage, its storage trie root node must be in the witness.
+// Verkle witnesses are not covered.
+func ValidateSystemContracts(pi *ProverInput) error {
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil {
+		return fmt.Errorf("no blocks provided")
+	}
+	if pi.ChainConfig == nil {
+		return fmt.Errorf("missing chain configuration")
+	}
+	header := pi.Blocks[0].Header
+	if pi.ChainConfig.IsVerkle(header.Number, header.Time) {
+		return nil
+	}
+
+	var nodes map[gethcommon.Hash][]byte
+	for _, contract := range RequiredSystemContracts(pi.ChainConfig, header) {
+		_, account, err := AccountProof(pi, contract.Address)
+		if err != nil {
+			return fmt.Errorf("missing witness coverage of %s contract (EIP-%d): %v", contract.Name, contract.EIP, err)
+		}
+		if account == nil || account.Root == gethtypes.EmptyRootHash {
+			continue
+		}
+
+		if nodes == nil {
+			nodes = trie.NodesByHash(pi.Witness.State...)
+		}
+		if _, ok := nodes[account.Root]; !ok {
+			return fmt.Errorf("missing witness coverage of %s contract (EIP-%d) %v: storage root %v not in witness", contract.Name, contract.EIP, contract.Address.Hex(), account.Root.Hex())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package input
+
+import (
+	"math/big"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSystemContracts(t *testing.T) {
+	newInput := func(cfg *params.ChainConfig, historyStorageRoot gethcommon.Hash) *ProverInput {
+		history := newTestAccount(1, 0)
+		history.Root = historyStorageRoot
+		return &ProverInput{
+			ChainConfig: cfg,
+			Blocks:      []*Block{{Header: &gethtypes.Header{Number: big.NewInt(1), Difficulty: big.NewInt(0)}}},
+			Witness: newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+				params.BeaconRootsAddress:        newTestAccount(1, 0),
+				params.HistoryStorageAddress:     history,
+				params.WithdrawalQueueAddress:    newTestAccount(1, 0),
+				params.ConsolidationQueueAddress: newTestAccount(1, 0),
+			}),
+		}
+	}
+	prague := params.MergedTestChainConfig
+	require.True(t, prague.IsPrague(big.NewInt(1), 0))
+
+	names := func(contracts []SystemContract) (names []string) {
+		for _, c := range contracts {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"beacon roots", "history storage", "withdrawal queue", "consolidation queue"}, names(RequiredSystemContracts(prague, &gethtypes.Header{Number: big.NewInt(1)})))
+	assert.Equal(t, []string{"beacon roots"}, names(RequiredSystemContracts(params.MainnetChainConfig, &gethtypes.Header{Number: big.NewInt(21465322), Time: 1734523800})))
+
+	t.Run("covered", func(t *testing.T) {
+		assert.NoError(t, ValidateSystemContracts(newInput(prague, gethtypes.EmptyRootHash)))
+	})
+
+	t.Run("missing history storage", func(t *testing.T) {
+		root := gethcommon.HexToHash("0x2935")
+		err := ValidateSystemContracts(newInput(prague, root))
+		require.Error(t, err)
+		assert.Equal(t, "missing witness coverage of history storage contract (EIP-2935) "+params.HistoryStorageAddress.Hex()+": storage root "+root.Hex()+" not in witness", err.Error())
+	})
+
+	t.Run("missing account", func(t *testing.T) {
+		pi := newInput(prague, gethtypes.EmptyRootHash)
+		pi.Witness.State = nil
+		err := ValidateSystemContracts(pi)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing witness coverage of beacon roots contract (EIP-4788)")
+	})
+
+	t.Run("missing chain configuration", func(t *testing.T) {
+		err := ValidateSystemContracts(newInput(nil, gethtypes.EmptyRootHash))
+		require.Error(t, err)
+		assert.Equal(t, "missing chain configuration", err.Error())
+	})
+}
@@ -0,0 +1,57 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion is the version of the prover input schema understood by this binary
+const SchemaVersion = 1
+
+// migrations upgrade prover inputs from a schema version to the next one, indexed by the version they upgrade from
+var migrations = map[int]func(*ProverInput) error{
+	// Inputs generated before versioning have the same schema as version 1
+	0: func(*ProverInput) error { return nil },
+}
+
+// ParseVersion returns the schema version of a prover input version string (e.g. "1" or "v1")
+// Inputs generated before versioning have an empty version, which is version 0.
+func ParseVersion(version string) (int, error) {
+	if version == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid version %q", version)
+	}
+	return v, nil
+}
+
+// Migrate checks the schema version of the prover input is understood by this binary and returns the input migrated to the current schema version
+// Inputs from a future version are rejected, the input is returned unchanged if it is already at the current version.
+func Migrate(pi *ProverInput) (*ProverInput, error) {
+	version, err := ParseVersion(pi.Version)
+	if err != nil {
+		return nil, err
+	}
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("unsupported future version %q: input schema version %d is newer than the supported version %d", pi.Version, version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return pi, nil
+	}
+
+	migrated := *pi
+	for ; version < SchemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("no migration from version %d", version)
+		}
+		if err := migrate(&migrated); err != nil {
+			return nil, fmt.Errorf("failed to migrate input from version %d: %v", version, err)
+		}
+	}
+	migrated.Version = strconv.Itoa(SchemaVersion)
+	return &migrated, nil
+}
@@ -0,0 +1,40 @@
+package input
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Run("legacy", func(t *testing.T) {
+		pi := &ProverInput{Blocks: []*Block{}}
+		migrated, err := Migrate(pi)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprint(SchemaVersion), migrated.Version)
+		assert.Equal(t, pi.Blocks, migrated.Blocks)
+		// The input is not modified
+		assert.Equal(t, "", pi.Version)
+	})
+
+	t.Run("current", func(t *testing.T) {
+		pi := &ProverInput{Version: fmt.Sprintf("v%d", SchemaVersion)}
+		migrated, err := Migrate(pi)
+		require.NoError(t, err)
+		assert.Same(t, pi, migrated)
+	})
+
+	t.Run("future", func(t *testing.T) {
+		_, err := Migrate(&ProverInput{Version: fmt.Sprint(SchemaVersion + 1)})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("unsupported future version \"%d\"", SchemaVersion+1))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Migrate(&ProverInput{Version: "latest"})
+		require.Error(t, err)
+		assert.Equal(t, "invalid version \"latest\"", err.Error())
+	})
+}
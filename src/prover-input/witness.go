@@ -0,0 +1,266 @@
+package input
+
+import (
+	"fmt"
+	"io"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+)
+
+// PreStateRoot returns the state root of the pre-state the witness is expected to cover (i.e. the parent block state root)
+func PreStateRoot(pi *ProverInput) (gethcommon.Hash, error) {
+	if pi.Witness == nil {
+		return gethcommon.Hash{}, fmt.Errorf("no ancestors provided")
+	}
+	ancestors, err := pi.Witness.LoadAncestors()
+	if err != nil {
+		return gethcommon.Hash{}, err
+	}
+	if len(ancestors) == 0 || ancestors[0] == nil {
+		return gethcommon.Hash{}, fmt.Errorf("no ancestors provided")
+	}
+	return ancestors[0].Root, nil
+}
+
+// WitnessAccount is an account covered by the pre-state witness
+type WitnessAccount struct {
+	Key     gethcommon.Hash     // Account trie key (i.e. keccak256(address))
+	Address *gethcommon.Address // Account address, nil if it can not be derived from the prover input
+}
+
+// WitnessAccounts returns the accounts covered by the pre-state witness, in trie order
+// It walks the witness state nodes from the parent state root without executing the block.
+//
+// Account trie keys are hashed addresses, the witness holds no address preimages. Addresses are resolved from the addresses the blocks
+// reference (see referencedAddresses), accounts accessed otherwise (e.g. by a CALL) only have their key.
+// Accounts located in sub-tries that are not covered by the witness are ignored.
+func WitnessAccounts(pi *ProverInput) ([]WitnessAccount, error) {
+	root, err := PreStateRoot(pi)
+	if err != nil {
+		return nil, err
+	}
+
+	preimages := make(map[gethcommon.Hash]gethcommon.Address)
+	for _, addr := range referencedAddresses(pi) {
+		preimages[crypto.Keccak256Hash(addr.Bytes())] = addr
+	}
+
+	var accounts []WitnessAccount
+	err = trie.Walk(root, trie.NodesByHash(pi.Witness.State...), &trie.Visitor{
+		OnLeaf: func(key, _ []byte) {
+			account := WitnessAccount{Key: gethcommon.BytesToHash(key)}
+			if addr, ok := preimages[account.Key]; ok {
+				account.Address = &addr
+			}
+			accounts = append(accounts, account)
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk witness state: %v", err)
+	}
+
+	return accounts, nil
+}
+
+// referencedAddresses returns the addresses referenced by the prover input blocks: coinbases, transaction senders, recipients,
+// created contracts and access lists, withdrawal recipients and system contracts
+// Senders and created contracts are only resolved if the chain configuration is set.
+func referencedAddresses(pi *ProverInput) []gethcommon.Address {
+	var addrs []gethcommon.Address
+	for _, block := range pi.Blocks {
+		if block.Header == nil {
+			continue
+		}
+		addrs = append(addrs, block.Header.Coinbase)
+		if pi.ChainConfig != nil {
+			for _, contract := range RequiredSystemContracts(pi.ChainConfig, block.Header) {
+				addrs = append(addrs, contract.Address)
+			}
+		}
+		for _, tx := range block.Transactions {
+			if to := tx.To(); to != nil {
+				addrs = append(addrs, *to)
+			}
+			for _, tuple := range tx.AccessList() {
+				addrs = append(addrs, tuple.Address)
+			}
+			if pi.ChainConfig == nil {
+				continue
+			}
+			from, err := gethtypes.Sender(gethtypes.MakeSigner(pi.ChainConfig, block.Header.Number, block.Header.Time), tx)
+			if err != nil {
+				continue
+			}
+			addrs = append(addrs, from)
+			if tx.To() == nil {
+				addrs = append(addrs, crypto.CreateAddress(from, tx.Nonce()))
+			}
+		}
+		for _, withdrawal := range block.Withdrawals {
+			addrs = append(addrs, withdrawal.Address)
+		}
+	}
+	return addrs
+}
+
+// WitnessDelta computes the difference between the witnesses of two prover inputs (typically of adjacent blocks)
+// added are the state nodes and codes present in b's witness but not in a's, removed are the ones present in a's witness but not in b's
+func WitnessDelta(a, b *ProverInput) (added, removed [][]byte) {
+	aItems, bItems := witnessItems(a.Witness), witnessItems(b.Witness)
+
+	for _, item := range bItems.list {
+		if _, ok := aItems.set[string(item)]; !ok {
+			added = append(added, item)
+		}
+	}
+	for _, item := range aItems.list {
+		if _, ok := bItems.set[string(item)]; !ok {
+			removed = append(removed, item)
+		}
+	}
+
+	return added, removed
+}
+
+// UnreachableNodes returns the hashes of the witness state nodes not reachable from the given state root, in witness order
+// Reachable nodes are the nodes of the account trie and of the storage tries of its accounts, unreachable ones are unneeded padding (e.g. from a buggy generator).
+func UnreachableNodes(root gethcommon.Hash, w *Witness) ([]gethcommon.Hash, error) {
+	nodes := trie.NodesByHash(w.State...)
+	reached := make(map[gethcommon.Hash]struct{}, len(nodes))
+	onNode := func(_ []byte, hash gethcommon.Hash, _ []byte) {
+		reached[hash] = struct{}{}
+	}
+
+	var storageRoots []gethcommon.Hash
+	err := trie.Walk(root, nodes, &trie.Visitor{
+		OnNode: onNode,
+		OnLeaf: func(_, value []byte) {
+			var account gethtypes.StateAccount
+			if err := rlp.DecodeBytes(value, &account); err == nil {
+				storageRoots = append(storageRoots, account.Root)
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk account trie: %v", err)
+	}
+	for _, storageRoot := range storageRoots {
+		if _, ok := reached[storageRoot]; ok {
+			continue
+		}
+		if err := trie.Walk(storageRoot, nodes, &trie.Visitor{OnNode: onNode}); err != nil {
+			return nil, fmt.Errorf("failed to walk storage trie %v: %v", storageRoot.Hex(), err)
+		}
+	}
+
+	var unreachable []gethcommon.Hash
+	seen := make(map[gethcommon.Hash]struct{}, len(w.State))
+	for _, blob := range w.State {
+		hash := crypto.Keccak256Hash(blob)
+		if _, ok := seen[hash]; ok {
+			continue
+		}
+		seen[hash] = struct{}{}
+		if _, ok := reached[hash]; !ok {
+			unreachable = append(unreachable, hash)
+		}
+	}
+
+	return unreachable, nil
+}
+
+type witnessItemSet struct {
+	list [][]byte
+	set  map[string]struct{}
+}
+
+// witnessItems returns the deduplicated state nodes and codes of a witness, in witness order
+func witnessItems(w *Witness) *witnessItemSet {
+	items := &witnessItemSet{set: make(map[string]struct{})}
+	if w == nil {
+		return items
+	}
+
+	for _, blobs := range [][]hexutil.Bytes{w.State, w.Codes} {
+		for _, blob := range blobs {
+			if _, ok := items.set[string(blob)]; ok {
+				continue
+			}
+			items.set[string(blob)] = struct{}{}
+			items.list = append(items.list, blob)
+		}
+	}
+	return items
+}
+
+// PrintWitnessState writes a human-readable representation of the witness state trie, leaves being decoded as accounts
+// Nodes absent from the witness are printed as <missing>, maxDepth limits the depth of printed nodes (zero means no limit)
+func PrintWitnessState(w io.Writer, pi *ProverInput, maxDepth int) error {
+	root, err := PreStateRoot(pi)
+	if err != nil {
+		return err
+	}
+
+	return trie.Print(w, root, trie.NodesByHash(pi.Witness.State...), &trie.PrintOptions{
+		MaxDepth:    maxDepth,
+		FormatValue: formatAccount,
+	})
+}
+
+func formatAccount(value []byte) string {
+	var account gethtypes.StateAccount
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		return fmt.Sprintf("value=%#x", value)
+	}
+	return fmt.Sprintf("account{nonce=%d balance=%v storageRoot=%v codeHash=%#x}", account.Nonce, account.Balance, account.Root.Hex(), account.CodeHash)
+}
+
+// AccountProof returns the Merkle proof path of the account in the witness pre-state trie and the decoded account
+// The account is nil if the proof shows its absence. It errors if the account is not covered by the witness.
+func AccountProof(pi *ProverInput, addr gethcommon.Address) (*trie.KeyProof, *gethtypes.StateAccount, error) {
+	root, err := PreStateRoot(pi)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proof, err := trie.ProveKey(root, trie.AccountTrieKey(addr), trie.NodesByHash(pi.Witness.State...))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to prove account %v: %v", addr.Hex(), err)
+	}
+	if proof.Missing != nil {
+		return nil, nil, fmt.Errorf("account %v is not covered by the witness (missing node %v after %d nodes)", addr.Hex(), proof.Missing.Hex(), len(proof.Nodes))
+	}
+	if proof.Value == nil {
+		return proof, nil, nil
+	}
+
+	var account gethtypes.StateAccount
+	if err := rlp.DecodeBytes(proof.Value, &account); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode account %v: %v", addr.Hex(), err)
+	}
+	return proof, &account, nil
+}
+
+// PrintAccountProof writes the Merkle proof path of the account in the witness pre-state trie (nodes from root to leaf) and the decoded account
+func PrintAccountProof(w io.Writer, pi *ProverInput, addr gethcommon.Address) error {
+	proof, account, err := AccountProof(pi, addr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "account %v (key %#x)\n", addr.Hex(), trie.AccountTrieKey(addr))
+	for i, node := range proof.Nodes {
+		fmt.Fprintf(w, "%d: path=%x hash=%v\n   %#x\n", i, []byte(node.Path), node.Hash.Hex(), []byte(node.Blob))
+	}
+	if account == nil {
+		fmt.Fprintln(w, "account does not exist (proof of absence)")
+		return nil
+	}
+	fmt.Fprintln(w, formatAccount(proof.Value))
+	return nil
+}
@@ -0,0 +1,292 @@
+package input
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	gethtrie "github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWitness returns a witness covering a pre-state containing the given accounts
+func newTestWitness(t *testing.T, accounts map[gethcommon.Address]*gethtypes.StateAccount) *Witness {
+	tr := gethtrie.NewEmpty(triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil))
+	for addr, account := range accounts {
+		blob, err := rlp.EncodeToBytes(account)
+		require.NoError(t, err)
+		require.NoError(t, tr.Update(crypto.Keccak256(addr.Bytes()), blob))
+	}
+	root, set := tr.Commit(false)
+
+	witness := &Witness{
+		Ancestors: []*gethtypes.Header{{Root: root}},
+	}
+	for _, n := range set.Nodes {
+		witness.State = append(witness.State, hexutil.Bytes(n.Blob))
+	}
+	return witness
+}
+
+func newTestAccount(nonce, balance uint64) *gethtypes.StateAccount {
+	return &gethtypes.StateAccount{
+		Nonce:    nonce,
+		Balance:  uint256.NewInt(balance),
+		Root:     gethtypes.EmptyRootHash,
+		CodeHash: gethtypes.EmptyCodeHash.Bytes(),
+	}
+}
+
+func TestWitnessAccounts(t *testing.T) {
+	addr1 := gethcommon.HexToAddress("0x1")
+	addr2 := gethcommon.HexToAddress("0x2")
+	pi := &ProverInput{
+		Witness: newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+			addr1: newTestAccount(1, 100),
+			addr2: newTestAccount(2, 200),
+		}),
+	}
+
+	accounts, err := WitnessAccounts(pi)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []WitnessAccount{
+		{Key: crypto.Keccak256Hash(addr1.Bytes())},
+		{Key: crypto.Keccak256Hash(addr2.Bytes())},
+	}, accounts, "addresses not referenced by the blocks are not derivable")
+
+	// Addresses referenced by the block are resolved
+	pi.Blocks = []*Block{{
+		Header:      &gethtypes.Header{Number: big.NewInt(1), Coinbase: addr1},
+		Withdrawals: []*gethtypes.Withdrawal{{Address: addr2}},
+	}}
+	accounts, err = WitnessAccounts(pi)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []WitnessAccount{
+		{Key: crypto.Keccak256Hash(addr1.Bytes()), Address: &addr1},
+		{Key: crypto.Keccak256Hash(addr2.Bytes()), Address: &addr2},
+	}, accounts)
+
+	_, err = WitnessAccounts(&ProverInput{Witness: &Witness{}})
+	assert.Error(t, err)
+}
+
+func TestWitnessAccountsTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	created := crypto.CreateAddress(sender, 0)
+
+	cfg := params.TestChainConfig
+	tx := gethtypes.MustSignNewTx(key, gethtypes.LatestSigner(cfg), &gethtypes.DynamicFeeTx{ChainID: cfg.ChainID, Nonce: 0, Gas: 100_000})
+	pi := &ProverInput{
+		Blocks: []*Block{{
+			Header:       &gethtypes.Header{Number: big.NewInt(1)},
+			Transactions: []*gethtypes.Transaction{tx},
+		}},
+		Witness: newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+			sender:  newTestAccount(0, 100),
+			created: newTestAccount(0, 0),
+		}),
+	}
+
+	// Senders and created contracts are not derivable without chain configuration
+	accounts, err := WitnessAccounts(pi)
+	require.NoError(t, err)
+	for _, account := range accounts {
+		assert.Nil(t, account.Address)
+	}
+
+	pi.ChainConfig = cfg
+	accounts, err = WitnessAccounts(pi)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []WitnessAccount{
+		{Key: crypto.Keccak256Hash(sender.Bytes()), Address: &sender},
+		{Key: crypto.Keccak256Hash(created.Bytes()), Address: &created},
+	}, accounts)
+}
+
+func TestWitnessAccountsAncestorsFile(t *testing.T) {
+	addr := gethcommon.HexToAddress("0x1")
+	witness := newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{addr: newTestAccount(1, 100)})
+	witness.Ancestors[0].Number, witness.Ancestors[0].Difficulty = big.NewInt(10), big.NewInt(0)
+
+	// Write the input and its ancestors file side by side, the input referencing the ancestors file relatively
+	dir := t.TempDir()
+	b, err := json.Marshal(witness.Ancestors)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ancestors.json"), b, 0o600))
+	witness.Ancestors, witness.AncestorsFile = nil, "ancestors.json"
+	b, err = json.Marshal(&ProverInput{Witness: witness})
+	require.NoError(t, err)
+	inputPath := filepath.Join(dir, "input.json")
+	require.NoError(t, os.WriteFile(inputPath, b, 0o600))
+
+	var pi ProverInput
+	b, err = os.ReadFile(inputPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &pi))
+	pi.Witness.ResolveAncestorsFile(inputPath)
+	assert.Equal(t, "ancestors.json", pi.Witness.AncestorsFile, "AncestorsFile should be left as read")
+	assert.Equal(t, filepath.Join(dir, "ancestors.json"), pi.Witness.AncestorsPath())
+
+	accounts, err := WitnessAccounts(&pi)
+	require.NoError(t, err)
+	assert.Equal(t, []WitnessAccount{{Key: crypto.Keccak256Hash(addr.Bytes())}}, accounts)
+	_, account, err := AccountProof(&pi, addr)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), account.Nonce)
+
+	// Absolute paths are left unchanged
+	pi.Witness.AncestorsFile = filepath.Join(dir, "ancestors.json")
+	pi.Witness.ResolveAncestorsFile(filepath.Join(t.TempDir(), "other.json"))
+	assert.Equal(t, filepath.Join(dir, "ancestors.json"), pi.Witness.AncestorsPath())
+}
+
+func TestUnreachableNodes(t *testing.T) {
+	witness := newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+		gethcommon.HexToAddress("0x1"): newTestAccount(1, 100),
+		gethcommon.HexToAddress("0x2"): newTestAccount(2, 200),
+	})
+	root := witness.Ancestors[0].Root
+
+	unreachable, err := UnreachableNodes(root, witness)
+	require.NoError(t, err)
+	assert.Empty(t, unreachable)
+
+	// The single node of another state trie is not reachable from the root
+	other := newTestWitness(t, map[gethcommon.Address]*gethtypes.StateAccount{
+		gethcommon.HexToAddress("0x3"): newTestAccount(3, 300),
+	})
+	require.Len(t, other.State, 1)
+	witness.State = append(witness.State, other.State[0])
+
+	unreachable, err = UnreachableNodes(root, witness)
+	require.NoError(t, err)
+	assert.Equal(t, []gethcommon.Hash{crypto.Keccak256Hash(other.State[0])}, unreachable)
+}
+
+func TestWitnessDelta(t *testing.T) {
+	accounts := make(map[gethcommon.Address]*gethtypes.StateAccount)
+	for i := 0; i < 100; i++ {
+		accounts[gethcommon.BigToAddress(big.NewInt(int64(i)))] = newTestAccount(uint64(i), 100)
+	}
+	a := &ProverInput{Witness: newTestWitness(t, accounts)}
+	a.Witness.Codes = []hexutil.Bytes{{0x01}, {0x02}}
+
+	// Next block modifies a single account and uses a different code
+	accounts[gethcommon.BigToAddress(big.NewInt(1))] = newTestAccount(2, 50)
+	b := &ProverInput{Witness: newTestWitness(t, accounts)}
+	b.Witness.Codes = []hexutil.Bytes{{0x01}, {0x03}}
+
+	added, removed := WitnessDelta(a, b)
+	assert.NotEmpty(t, added)
+	assert.NotEmpty(t, removed)
+	assert.Less(t, len(added), len(b.Witness.State)+len(b.Witness.Codes))
+	assert.Less(t, len(removed), len(a.Witness.State)+len(a.Witness.Codes))
+
+	assert.Contains(t, added, []byte{0x03})
+	assert.Contains(t, removed, []byte{0x02})
+	assert.NotContains(t, added, []byte{0x01})
+	assert.NotContains(t, removed, []byte{0x01})
+
+	// Every added item is in b's witness and not in a's
+	for _, item := range added {
+		assert.Contains(t, append(b.Witness.State, b.Witness.Codes...), hexutil.Bytes(item))
+		assert.NotContains(t, append(a.Witness.State, a.Witness.Codes...), hexutil.Bytes(item))
+	}
+}
+
+func TestPrintWitnessState(t *testing.T) {
+	accounts := make(map[gethcommon.Address]*gethtypes.StateAccount)
+	for i := 0; i < 20; i++ {
+		accounts[gethcommon.BigToAddress(big.NewInt(int64(i)))] = newTestAccount(uint64(i), 100)
+	}
+	addr := gethcommon.BigToAddress(big.NewInt(7))
+	pi := &ProverInput{Witness: newTestWitness(t, accounts)}
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintWitnessState(&buf, pi, 0))
+	assert.Contains(t, buf.String(), fmt.Sprintf("key=%#x account{nonce=7 balance=100", crypto.Keccak256(addr.Bytes())))
+	assert.NotContains(t, buf.String(), "<missing>")
+
+	t.Run("incomplete trie", func(t *testing.T) {
+		// Remove every node but the root
+		root, err := PreStateRoot(pi)
+		require.NoError(t, err)
+		for _, node := range pi.Witness.State {
+			if crypto.Keccak256Hash(node) == root {
+				pi.Witness.State = []hexutil.Bytes{node}
+				break
+			}
+		}
+
+		buf.Reset()
+		require.NoError(t, PrintWitnessState(&buf, pi, 0))
+		assert.Contains(t, buf.String(), "root: branch "+root.Hex())
+		assert.Contains(t, buf.String(), "<missing>")
+	})
+}
+
+func TestPrintAccountProof(t *testing.T) {
+	accounts := make(map[gethcommon.Address]*gethtypes.StateAccount)
+	for i := 0; i < 20; i++ {
+		accounts[gethcommon.BigToAddress(big.NewInt(int64(i)))] = newTestAccount(uint64(i), 100)
+	}
+	addr := gethcommon.BigToAddress(big.NewInt(7))
+	pi := &ProverInput{Witness: newTestWitness(t, accounts)}
+	root, err := PreStateRoot(pi)
+	require.NoError(t, err)
+
+	proof, account, err := AccountProof(pi, addr)
+	require.NoError(t, err)
+	assert.Equal(t, newTestAccount(7, 100), account)
+	require.NotEmpty(t, proof.Nodes)
+	assert.Equal(t, root, proof.Nodes[0].Hash)
+
+	// Proof verifies against the pre-state root
+	proofDB := rawdb.NewMemoryDatabase()
+	for _, node := range proof.Nodes {
+		require.NoError(t, proofDB.Put(node.Hash.Bytes(), node.Blob))
+	}
+	value, err := gethtrie.VerifyProof(root, crypto.Keccak256(addr.Bytes()), proofDB)
+	require.NoError(t, err)
+	assert.Equal(t, proof.Value, value)
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintAccountProof(&buf, pi, addr))
+	assert.Contains(t, buf.String(), fmt.Sprintf("account %v (key %#x)", addr.Hex(), crypto.Keccak256(addr.Bytes())))
+	assert.Contains(t, buf.String(), "0: path= hash="+root.Hex())
+	assert.Contains(t, buf.String(), "account{nonce=7 balance=100")
+
+	t.Run("absent account", func(t *testing.T) {
+		buf.Reset()
+		require.NoError(t, PrintAccountProof(&buf, pi, gethcommon.HexToAddress("0xdead")))
+		assert.Contains(t, buf.String(), "account does not exist (proof of absence)")
+	})
+
+	t.Run("account not covered", func(t *testing.T) {
+		for _, node := range pi.Witness.State {
+			if crypto.Keccak256Hash(node) == root {
+				pi.Witness.State = []hexutil.Bytes{node}
+				break
+			}
+		}
+		err := PrintAccountProof(&buf, pi, addr)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("account %v is not covered by the witness", addr.Hex()))
+	})
+}
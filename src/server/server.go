@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/kkrt-labs/go-utils/log"
+	kkrthttp "github.com/kkrt-labs/go-utils/net/http"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/state"
+	"github.com/kkrt-labs/zk-pig/src/generator"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"go.uber.org/zap"
+)
+
+// Config is the configuration of the execution server
+type Config struct {
+	Addr           string        // Address the server listens on
+	MaxRequestSize int64         // Maximum size in bytes of a request body
+	Timeout        time.Duration // Maximum duration of a request
+	MaxConcurrency int           // Maximum number of concurrent executions, further requests wait for an execution to complete
+}
+
+// SetDefault sets the default values of the configuration
+func (cfg *Config) SetDefault() *Config {
+	if cfg.Addr == "" {
+		cfg.Addr = ":8080"
+	}
+	if cfg.MaxRequestSize == 0 {
+		cfg.MaxRequestSize = 128 << 20
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Minute
+	}
+	if cfg.MaxConcurrency == 0 {
+		cfg.MaxConcurrency = runtime.NumCPU()
+	}
+	return cfg
+}
+
+// ExecuteResponse is the response of the execute endpoint
+type ExecuteResponse struct {
+	BlockNumber   uint64          `json:"blockNumber"`
+	BlockHash     gethcommon.Hash `json:"blockHash"`
+	GasUsed       uint64          `json:"gasUsed"`
+	GasRefunded   uint64          `json:"gasRefunded"`
+	InvalidOpcode bool            `json:"invalidOpcode"`
+	OutOfGas      bool            `json:"outOfGas"`
+
+	// Optional artifacts, requested with the artifacts query parameter (e.g. ?artifacts=receipts,touchedState)
+	Receipts     []*gethtypes.Receipt `json:"receipts,omitempty"`
+	TouchedState *state.Dump          `json:"touchedState,omitempty"`
+}
+
+// NewHandler creates an HTTP handler exposing the executor
+//
+// Endpoints:
+//   - POST /execute executes the JSON prover input in the request body and returns an ExecuteResponse
+//   - GET /healthz returns 200 when the server is alive
+//
+// Request bodies larger than the maximum request size are rejected and requests lasting longer than the timeout are aborted
+// (their execution is canceled). At most the maximum concurrency executions run at once, other requests wait for a slot within their timeout.
+// Inputs referencing an ancestors file are rejected, as the server would read it from its own filesystem.
+func NewHandler(executor generator.Executor, cfg *Config) http.Handler {
+	cfg = cfg.SetDefault()
+	h := &handler{executor: executor, cfg: cfg, slots: make(chan struct{}, cfg.MaxConcurrency)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /execute", h.execute)
+	mux.HandleFunc("GET /healthz", h.healthz)
+
+	return http.TimeoutHandler(mux, cfg.Timeout, "request timed out")
+}
+
+type handler struct {
+	executor generator.Executor
+	cfg      *Config
+	slots    chan struct{}
+}
+
+func (h *handler) execute(rw http.ResponseWriter, req *http.Request) {
+	req.Body = http.MaxBytesReader(rw, req.Body, h.cfg.MaxRequestSize)
+
+	var pi input.ProverInput
+	if err := kkrthttp.DecodeJSON(req, &pi); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			kkrthttp.WriteError(rw, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", maxBytesErr.Limit))
+			return
+		}
+		kkrthttp.WriteError(rw, http.StatusBadRequest, fmt.Errorf("invalid prover input: %v", err))
+		return
+	}
+	if len(pi.Blocks) == 0 || pi.Blocks[0].Header == nil || pi.ChainConfig == nil || pi.Witness == nil {
+		kkrthttp.WriteError(rw, http.StatusBadRequest, fmt.Errorf("invalid prover input: missing block, chain config or witness"))
+		return
+	}
+	if pi.Witness.AncestorsFile != "" {
+		kkrthttp.WriteError(rw, http.StatusBadRequest, fmt.Errorf("invalid prover input: ancestors file is not supported, ancestors must be inlined"))
+		return
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	case <-req.Context().Done():
+		// The timeout handler responds to the request
+		return
+	}
+
+	res, err := h.executor.Execute(req.Context(), &pi)
+	if err != nil {
+		kkrthttp.WriteError(rw, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	header := pi.Blocks[0].Header
+	resp := &ExecuteResponse{
+		BlockNumber:   header.Number.Uint64(),
+		BlockHash:     header.Hash(),
+		GasUsed:       res.ProcessResult.GasUsed,
+		GasRefunded:   res.GasRefunded,
+		InvalidOpcode: res.InvalidOpcode,
+		OutOfGas:      res.OutOfGas,
+	}
+
+	for _, artifact := range strings.Split(req.URL.Query().Get("artifacts"), ",") {
+		switch artifact {
+		case "receipts":
+			resp.Receipts = res.ProcessResult.Receipts
+			for _, receipt := range resp.Receipts {
+				// Receipts without logs must encode an empty list to be decodable
+				if receipt.Logs == nil {
+					receipt.Logs = []*gethtypes.Log{}
+				}
+			}
+		case "touchedState":
+			resp.TouchedState = res.TouchedState
+		}
+	}
+
+	if err := kkrthttp.WriteJSON(rw, http.StatusOK, resp); err != nil {
+		log.LoggerFromContext(req.Context()).Error("Failed to write execute response", zap.Error(err))
+	}
+}
+
+func (h *handler) healthz(rw http.ResponseWriter, _ *http.Request) {
+	_ = kkrthttp.WriteJSON(rw, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Server is an HTTP server exposing the executor
+type Server struct {
+	cfg    *Config
+	server *http.Server
+	errors chan error
+}
+
+// New creates a new execution server
+func New(executor generator.Executor, cfg *Config) *Server {
+	cfg = cfg.SetDefault()
+	return &Server{
+		cfg: cfg,
+		server: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           NewHandler(executor, cfg),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		errors: make(chan error, 1),
+	}
+}
+
+// Start starts listening and serving requests in the background
+func (s *Server) Start(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %v: %v", s.cfg.Addr, err)
+	}
+
+	// Requests are not canceled when ctx is done, so in-flight executions complete on graceful stop
+	s.server.BaseContext = func(net.Listener) context.Context { return context.WithoutCancel(ctx) }
+	go func() {
+		if err := s.server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.errors <- err
+		}
+		close(s.errors)
+	}()
+
+	log.LoggerFromContext(ctx).Info("Execution server started", zap.String("addr", l.Addr().String()))
+	return nil
+}
+
+// Stop gracefully stops the server, waiting for in-flight requests to complete
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Errors returns the error channel of the server
+func (s *Server) Errors() <-chan error {
+	return s.errors
+}
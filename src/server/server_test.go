@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kkrt-labs/zk-pig/src/generator"
+	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadTestProverInput loads the JSON prover input of the generator test data
+func loadTestProverInput(t *testing.T) []byte {
+	f, err := os.Open("../generator/testdata/Ethereum_Mainnet_21465322.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	var data struct {
+		ProverInput json.RawMessage `json:"proverInput"`
+	}
+	require.NoError(t, json.NewDecoder(f).Decode(&data))
+	return data.ProverInput
+}
+
+type mockExecutor struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *mockExecutor) Execute(_ context.Context, _ *input.ProverInput) (*generator.Result, error) {
+	time.Sleep(e.delay)
+	return nil, e.err
+}
+
+// blockingExecutor blocks executions until their context is done, recording the maximum number of concurrent executions
+type blockingExecutor struct {
+	mu            sync.Mutex
+	running, peak int
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, _ *input.ProverInput) (*generator.Result, error) {
+	e.mu.Lock()
+	e.running++
+	e.peak = max(e.peak, e.running)
+	e.mu.Unlock()
+
+	<-ctx.Done()
+
+	e.mu.Lock()
+	e.running--
+	e.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func TestHandlerExecute(t *testing.T) {
+	body := loadTestProverInput(t)
+	var pi input.ProverInput
+	require.NoError(t, json.Unmarshal(body, &pi))
+
+	h := NewHandler(generator.NewExecutor(), &Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/execute?artifacts=receipts", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp ExecuteResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint64(21465322), resp.BlockNumber)
+	assert.Equal(t, pi.Blocks[0].Header.Hash(), resp.BlockHash)
+	assert.Equal(t, pi.Blocks[0].Header.GasUsed, resp.GasUsed)
+	assert.Len(t, resp.Receipts, len(pi.Blocks[0].Transactions))
+	assert.Nil(t, resp.TouchedState)
+}
+
+func TestHandlerErrors(t *testing.T) {
+	body := loadTestProverInput(t)
+
+	tests := []struct {
+		desc     string
+		executor generator.Executor
+		cfg      *Config
+		body     []byte
+		code     int
+	}{
+		{
+			desc:     "invalid JSON",
+			executor: &mockExecutor{},
+			cfg:      &Config{},
+			body:     []byte("{"),
+			code:     http.StatusBadRequest,
+		},
+		{
+			desc:     "missing block",
+			executor: &mockExecutor{},
+			cfg:      &Config{},
+			body:     []byte("{}"),
+			code:     http.StatusBadRequest,
+		},
+		{
+			desc:     "ancestors file",
+			executor: &mockExecutor{},
+			cfg:      &Config{},
+			body:     []byte(`{"blocks":[{"header":{}}],"chainConfig":{},"witness":{"ancestorsFile":"/etc/passwd"}}`),
+			code:     http.StatusBadRequest,
+		},
+		{
+			desc:     "request too large",
+			executor: &mockExecutor{},
+			cfg:      &Config{MaxRequestSize: 1024},
+			body:     body,
+			code:     http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:     "execution failure",
+			executor: &mockExecutor{err: fmt.Errorf("execution failed")},
+			cfg:      &Config{},
+			body:     body,
+			code:     http.StatusUnprocessableEntity,
+		},
+		{
+			desc:     "timeout",
+			executor: &mockExecutor{delay: 100 * time.Millisecond},
+			cfg:      &Config{Timeout: 10 * time.Millisecond},
+			body:     body,
+			code:     http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewHandler(test.executor, test.cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/execute", bytes.NewReader(test.body)))
+			assert.Equal(t, test.code, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestHandlerCancelAndConcurrency(t *testing.T) {
+	body := loadTestProverInput(t)
+	executor := new(blockingExecutor)
+	h := NewHandler(executor, &Config{Timeout: 50 * time.Millisecond, MaxConcurrency: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/execute", bytes.NewReader(body)))
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		}()
+	}
+	wg.Wait()
+
+	// Timed out executions are canceled and concurrency is capped
+	assert.Eventually(t, func() bool {
+		executor.mu.Lock()
+		defer executor.mu.Unlock()
+		return executor.running == 0
+	}, time.Second, 10*time.Millisecond)
+	executor.mu.Lock()
+	defer executor.mu.Unlock()
+	assert.LessOrEqual(t, executor.peak, 2)
+}
+
+func TestHandlerHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(&mockExecutor{}, &Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
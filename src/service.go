@@ -65,7 +65,11 @@ func New(cfg *Config) (*Service, error) {
 		ContentEncoding:  cfg.ProverInputStore.ContentEncoding,
 	})
 
-	ProverInputStore := inputstore.NewFromStore(compressStore, cfg.ProverInputStore.ContentType)
+	var storeOpts []inputstore.ProverInputStoreOption
+	if cfg.ProverInputStore.StoreConfig.FileConfig != nil {
+		storeOpts = append(storeOpts, inputstore.WithDataDir(cfg.ProverInputStore.StoreConfig.FileConfig.DataDir))
+	}
+	ProverInputStore := inputstore.NewFromStore(compressStore, cfg.ProverInputStore.ContentType, storeOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prover inputs store: %v", err)
 	}
@@ -198,6 +202,32 @@ func (s *Service) execute(ctx context.Context, blockNumber *big.Int) error {
 	return err
 }
 
+// ExecuteFromRPC generates the prover inputs for the given block number and immediately executes the block on them.
+// Preflight data and prover inputs are kept in memory and are not written to the stores.
+// It requires the remote RPC to be configured and started
+func (s *Service) ExecuteFromRPC(ctx context.Context, blockNumber *big.Int) error {
+	if s.ethrpc == nil {
+		return fmt.Errorf("no chain RPC configured")
+	}
+
+	data, err := generator.NewPreflight(s.ethrpc).Preflight(ctx, blockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to execute preflight: %v", err)
+	}
+
+	inputs, err := generator.NewPreparer().Prepare(ctx, data)
+	if err != nil {
+		return fmt.Errorf("failed to prepare provable inputs: %v", err)
+	}
+
+	_, err = generator.NewExecutor().Execute(ctx, inputs)
+	if err != nil {
+		return fmt.Errorf("failed to execute block on provable inputs: %v", err)
+	}
+
+	return nil
+}
+
 // Errors returns the error channel for possible internal errors of the service.
 func (s *Service) Errors() <-chan error {
 	if errorable, ok := s.remote.(svc.ErrorReporter); ok {
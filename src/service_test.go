@@ -0,0 +1,155 @@
+package src
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+
+	gethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	rpcmock "github.com/kkrt-labs/go-utils/ethereum/rpc/mock"
+	"github.com/kkrt-labs/zk-pig/src/ethereum/trie"
+	"github.com/kkrt-labs/zk-pig/src/generator"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func loadTestPreflightData(t *testing.T, path string) *generator.PreflightData {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var data struct {
+		PreflightData generator.PreflightData `json:"preflightData"`
+	}
+	err = json.NewDecoder(f).Decode(&data)
+	require.NoError(t, err)
+
+	return &data.PreflightData
+}
+
+func accountResultFromProof(proof *trie.AccountProof) *gethclient.AccountResult {
+	res := &gethclient.AccountResult{
+		Address:      proof.Address,
+		AccountProof: proof.Proof,
+		Balance:      proof.Balance.ToInt(),
+		CodeHash:     proof.CodeHash,
+		Nonce:        proof.Nonce,
+		StorageHash:  proof.StorageHash,
+	}
+	for _, slot := range proof.Storage {
+		res.StorageProof = append(res.StorageProof, gethclient.StorageResult{
+			Key:   slot.Key,
+			Value: slot.Value.ToInt(),
+			Proof: slot.Proof,
+		})
+	}
+	return res
+}
+
+// newMockRPCFromPreflightData returns a mock RPC client serving the chain data contained in the preflight data
+func newMockRPCFromPreflightData(ctrl *gomock.Controller, data *generator.PreflightData) *rpcmock.MockClient {
+	var (
+		block        = data.Block.Block()
+		parentNumber = new(big.Int).Sub(block.Number(), big.NewInt(1))
+		headers      = make(map[gethcommon.Hash]*gethtypes.Header)
+		codes        = make(map[gethcommon.Hash][]byte)
+		preProofs    = make(map[gethcommon.Address]*trie.AccountProof)
+		postProofs   = make(map[gethcommon.Address]*trie.AccountProof)
+	)
+
+	genesis := core.DefaultGenesisBlock().ToBlock().Header()
+	headers[genesis.Hash()] = genesis
+	for _, header := range data.Ancestors {
+		headers[header.Hash()] = header
+	}
+	for _, code := range data.Codes {
+		codes[crypto.Keccak256Hash(code)] = code
+	}
+	for _, proof := range data.PreStateProofs {
+		preProofs[proof.Address] = proof
+	}
+	for _, proof := range data.PostStateProofs {
+		postProofs[proof.Address] = proof
+	}
+
+	getProof := func(account gethcommon.Address, blockNumber *big.Int) (*trie.AccountProof, error) {
+		proofs := preProofs
+		if blockNumber.Cmp(parentNumber) != 0 {
+			proofs = postProofs
+		}
+		proof, ok := proofs[account]
+		if !ok {
+			return nil, fmt.Errorf("unknown account %v at block %v", account.Hex(), blockNumber)
+		}
+		return proof, nil
+	}
+
+	remote := rpcmock.NewMockClient(ctrl)
+	remote.EXPECT().ChainID(gomock.Any()).Return(data.ChainConfig.ChainID, nil).AnyTimes()
+	remote.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(block, nil).AnyTimes()
+	remote.EXPECT().HeaderByHash(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, hash gethcommon.Hash) (*gethtypes.Header, error) {
+			header, ok := headers[hash]
+			if !ok {
+				return nil, fmt.Errorf("unknown header %v", hash.Hex())
+			}
+			return header, nil
+		},
+	).AnyTimes()
+	remote.EXPECT().GetProof(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, account gethcommon.Address, _ []string, blockNumber *big.Int) (*gethclient.AccountResult, error) {
+			proof, err := getProof(account, blockNumber)
+			if err != nil {
+				return nil, err
+			}
+			return accountResultFromProof(proof), nil
+		},
+	).AnyTimes()
+	remote.EXPECT().StorageAt(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, account gethcommon.Address, key gethcommon.Hash, blockNumber *big.Int) ([]byte, error) {
+			proof, err := getProof(account, blockNumber)
+			if err != nil {
+				return nil, err
+			}
+			for _, slot := range proof.Storage {
+				if gethcommon.HexToHash(slot.Key) == key {
+					return gethcommon.BigToHash(slot.Value.ToInt()).Bytes(), nil
+				}
+			}
+			return nil, fmt.Errorf("unknown storage slot %v for account %v", key.Hex(), account.Hex())
+		},
+	).AnyTimes()
+	remote.EXPECT().CodeAt(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, account gethcommon.Address, blockNumber *big.Int) ([]byte, error) {
+			proof, err := getProof(account, blockNumber)
+			if err != nil {
+				return nil, err
+			}
+			return codes[proof.CodeHash], nil
+		},
+	).AnyTimes()
+
+	return remote
+}
+
+func TestServiceExecuteFromRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	data := loadTestPreflightData(t, "generator/testdata/Ethereum_Mainnet_21465322.json")
+
+	s := &Service{
+		cfg:    new(Config).SetDefault(),
+		ethrpc: newMockRPCFromPreflightData(ctrl, data),
+	}
+
+	err := s.ExecuteFromRPC(context.Background(), data.Block.Number.ToInt())
+	require.NoError(t, err)
+}
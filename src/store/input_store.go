@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 
 	store "github.com/kkrt-labs/go-utils/store"
 	multistore "github.com/kkrt-labs/go-utils/store/multi"
@@ -32,6 +34,18 @@ type ProverInputStoreConfig struct {
 type proverInputStore struct {
 	store       store.Store
 	contentType store.ContentType
+	dataDir     string
+}
+
+// ProverInputStoreOption is an option for the prover input store
+type ProverInputStoreOption func(*proverInputStore)
+
+// WithDataDir sets the file store data directory, against which relative ancestors files of loaded inputs are resolved
+// Without it, loading an input referencing a relative ancestors file fails.
+func WithDataDir(dataDir string) ProverInputStoreOption {
+	return func(s *proverInputStore) {
+		s.dataDir = dataDir
+	}
 }
 
 func New(cfg *ProverInputStoreConfig) (ProverInputStore, error) {
@@ -39,25 +53,48 @@ func New(cfg *ProverInputStoreConfig) (ProverInputStore, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewFromStore(inputstore, cfg.ContentType), nil
+	var opts []ProverInputStoreOption
+	if cfg.StoreConfig.FileConfig != nil {
+		opts = append(opts, WithDataDir(cfg.StoreConfig.FileConfig.DataDir))
+	}
+	return NewFromStore(inputstore, cfg.ContentType, opts...), nil
 }
 
-func NewFromStore(inputstore store.Store, contentType store.ContentType) ProverInputStore {
-	return &proverInputStore{store: inputstore, contentType: contentType}
+func NewFromStore(inputstore store.Store, contentType store.ContentType, opts ...ProverInputStoreOption) ProverInputStore {
+	s := &proverInputStore{store: inputstore, contentType: contentType}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *proverInputStore) StoreProverInput(ctx context.Context, data *input.ProverInput) error {
 	var buf bytes.Buffer
 	switch s.contentType {
 	case store.ContentTypeProtobuf:
-		protoMsg := protoinput.ToProto(data)
+		// The protobuf encoding has no ancestors file, so such inputs would silently lose their ancestors
+		if data.Witness != nil && data.Witness.AncestorsFile != "" {
+			return fmt.Errorf("protobuf encoding does not support ancestors file (ancestors must be inlined)")
+		}
+		// Embed a checksum to detect corruption on load, computed on the input as decoded from protobuf
+		// as the protobuf encoding does not hold every field of the input
+		withChecksum, err := input.WithChecksum(protoinput.FromProto(protoinput.ToProto(data)))
+		if err != nil {
+			return fmt.Errorf("failed to compute checksum: %w", err)
+		}
+		protoMsg := protoinput.ToProto(withChecksum)
 		protoBytes, err := proto.Marshal(protoMsg)
 		if err != nil {
 			return fmt.Errorf("failed to marshal protobuf: %w", err)
 		}
 		buf.Write(protoBytes)
 	case store.ContentTypeJSON:
-		if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		// Embed a checksum to detect corruption on load
+		withChecksum, err := input.WithChecksum(data)
+		if err != nil {
+			return fmt.Errorf("failed to compute checksum: %w", err)
+		}
+		if err := json.NewEncoder(&buf).Encode(withChecksum); err != nil {
 			return fmt.Errorf("failed to encode JSON: %w", err)
 		}
 	default:
@@ -94,6 +131,16 @@ func (s *proverInputStore) LoadProverInput(ctx context.Context, chainID, blockNu
 		if err := json.NewDecoder(reader).Decode(data); err != nil {
 			return nil, fmt.Errorf("failed to decode JSON: %w", err)
 		}
+		// The ancestors file is resolved first, as the checksum covers its content
+		if err := s.resolveAncestorsFile(data, chainID, path); err != nil {
+			return nil, err
+		}
+		// Inputs stored without checksum are accepted
+		if data.Checksum != nil {
+			if err := input.VerifyChecksum(data); err != nil {
+				return nil, fmt.Errorf("invalid prover input: %w", err)
+			}
+		}
 	case store.ContentTypeProtobuf:
 		protoBytes, err := io.ReadAll(reader)
 		if err != nil {
@@ -104,6 +151,12 @@ func (s *proverInputStore) LoadProverInput(ctx context.Context, chainID, blockNu
 			return nil, fmt.Errorf("failed to unmarshal protobuf: %w", err)
 		}
 		data = protoinput.FromProto(protoMsg)
+		// Inputs stored without checksum are accepted
+		if data.Checksum != nil {
+			if err := input.VerifyChecksum(data); err != nil {
+				return nil, fmt.Errorf("invalid prover input: %w", err)
+			}
+		}
 	default:
 		contentType, err := s.contentType.String()
 		if err != nil {
@@ -115,6 +168,20 @@ func (s *proverInputStore) LoadProverInput(ctx context.Context, chainID, blockNu
 	return data, nil
 }
 
+// resolveAncestorsFile resolves a relative ancestors file of a loaded input against the location of the input in the file store
+func (s *proverInputStore) resolveAncestorsFile(data *input.ProverInput, chainID uint64, path string) error {
+	if data.Witness == nil || data.Witness.AncestorsFile == "" || filepath.IsAbs(data.Witness.AncestorsFile) {
+		return nil
+	}
+	if s.dataDir == "" {
+		return fmt.Errorf("can not resolve relative ancestors file %q: no file store data directory", data.Witness.AncestorsFile)
+	}
+	// Mirrors the file store layout, which replaces "default" in the data directory with the chain ID
+	dataDir := strings.Replace(s.dataDir, "default", fmt.Sprintf("%d", chainID), 1)
+	data.Witness.ResolveAncestorsFile(filepath.Join(dataDir, path))
+	return nil
+}
+
 func (s *proverInputStore) proverPath(blockNumber uint64) string {
 	return fmt.Sprintf("%d", blockNumber)
 }
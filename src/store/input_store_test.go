@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 
 	gethcommon "github.com/ethereum/go-ethereum/common"
@@ -14,7 +17,10 @@ import (
 	multistore "github.com/kkrt-labs/go-utils/store/multi"
 	s3store "github.com/kkrt-labs/go-utils/store/s3"
 	input "github.com/kkrt-labs/zk-pig/src/prover-input"
+	protoinput "github.com/kkrt-labs/zk-pig/src/prover-input/proto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 // Common test structures and helpers
@@ -82,7 +88,7 @@ func setupProverInputTestStore(t *testing.T, tc testCase) (store ProverInputStor
 		MultiStoreConfig: cfg.StoreConfig,
 		ContentEncoding:  tc.contentEncoding,
 	})
-	store = NewFromStore(compressStore, tc.contentType)
+	store = NewFromStore(compressStore, tc.contentType, WithDataDir(baseDir))
 
 	assert.NoError(t, err)
 	return store, baseDir
@@ -124,3 +130,105 @@ func TestProverInputStore(t *testing.T) {
 		})
 	}
 }
+
+func TestProverInputStoreAncestorsFile(t *testing.T) {
+	newInput := func() *input.ProverInput {
+		return &input.ProverInput{
+			ChainConfig: &params.ChainConfig{ChainID: big.NewInt(2)},
+			Blocks: []*input.Block{
+				{Header: &gethtypes.Header{Number: big.NewInt(15), Difficulty: big.NewInt(15)}},
+			},
+			Witness: &input.Witness{AncestorsFile: "ancestors.json"},
+		}
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		store, baseDir := setupProverInputTestStore(t, testCases[0])
+		ancestors := []*gethtypes.Header{{Number: big.NewInt(14), Difficulty: big.NewInt(14)}}
+		b, err := json.Marshal(ancestors)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(baseDir, "ancestors.json"), b, 0o600))
+
+		// The ancestors file is read to compute the input checksum
+		pi := newInput()
+		pi.Witness.ResolveAncestorsFile(filepath.Join(baseDir, "15"))
+		require.NoError(t, store.StoreProverInput(context.Background(), pi))
+		loaded, err := store.LoadProverInput(context.Background(), 2, 15)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(baseDir, "ancestors.json"), loaded.Witness.AncestorsPath())
+		loadedAncestors, err := loaded.Witness.LoadAncestors()
+		require.NoError(t, err)
+		require.Len(t, loadedAncestors, 1)
+		assert.Equal(t, uint64(14), loadedAncestors[0].Number.Uint64())
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		store, _ := setupProverInputTestStore(t, testCases[1])
+		err := store.StoreProverInput(context.Background(), newInput())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "ancestors file")
+	})
+}
+
+func TestProverInputStoreChecksum(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		tc     testCase
+		tamper func(t *testing.T, b []byte) []byte
+	}{
+		{
+			name: "JSON",
+			tc:   testCases[0],
+			tamper: func(t *testing.T, b []byte) []byte {
+				var pi input.ProverInput
+				require.NoError(t, json.Unmarshal(b, &pi))
+				pi.Version = "tampered"
+				b, err := json.Marshal(&pi)
+				require.NoError(t, err)
+				return b
+			},
+		},
+		{
+			name: "Protobuf",
+			tc:   testCases[1],
+			tamper: func(t *testing.T, b []byte) []byte {
+				var msg protoinput.ProverInput
+				require.NoError(t, proto.Unmarshal(b, &msg))
+				msg.Version = "tampered"
+				b, err := proto.Marshal(&msg)
+				require.NoError(t, err)
+				return b
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, baseDir := setupProverInputTestStore(t, tc.tc)
+			pi := &input.ProverInput{
+				Version:     "1",
+				ChainConfig: &params.ChainConfig{ChainID: big.NewInt(2)},
+				Blocks: []*input.Block{
+					{Header: &gethtypes.Header{Number: big.NewInt(15), Difficulty: big.NewInt(15)}},
+				},
+			}
+			require.NoError(t, store.StoreProverInput(context.Background(), pi))
+
+			// The checksum is embedded on store and verified on load
+			loaded, err := store.LoadProverInput(context.Background(), 2, 15)
+			require.NoError(t, err)
+			require.NotNil(t, loaded.Checksum)
+			require.NoError(t, input.VerifyChecksum(loaded))
+
+			// A stored input modified without updating its checksum is rejected
+			paths, err := filepath.Glob(filepath.Join(baseDir, "15*"))
+			require.NoError(t, err)
+			require.Len(t, paths, 1)
+			b, err := os.ReadFile(paths[0])
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(paths[0], tc.tamper(t, b), 0o600))
+
+			_, err = store.LoadProverInput(context.Background(), 2, 15)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "checksum mismatch")
+		})
+	}
+}